@@ -0,0 +1,26 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// checkAdminToken makes sure the request includes the admin token (server.admintoken) as a bearer token.
+// Admin endpoints are turned off if there isn't an admin token
+func checkAdminToken(req *http.Request) error {
+	token := viper.GetString("server.admintoken")
+	if token == "" {
+		return newForbiddenError(fmt.Errorf("admin endpoints are turned off -- set server.admintoken to use them"))
+	}
+
+	provided := strings.TrimSpace(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+	if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		return newUnauthorizedError(fmt.Errorf("a valid admin token is required"))
+	}
+
+	return nil
+}
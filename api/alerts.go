@@ -1,19 +1,25 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/danesparza/daydash-service/internal/httpclient"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"golang.org/x/net/context/ctxhttp"
 )
 
 type AlertsRequest struct {
-	Latitude  string `json:"lat"`
-	Longitude string `json:"long"`
+	Latitude     string   `json:"lat"`
+	Longitude    string   `json:"long"`
+	MinSeverity  string   `json:"min_severity"`  // Only include alerts at least this severe (Minor, Moderate, Severe or Extreme)
+	MinUrgency   string   `json:"min_urgency"`   // Only include alerts at least this urgent (Past, Future, Expected or Immediate)
+	MinCertainty string   `json:"min_certainty"` // Only include alerts at least this certain (Unlikely, Possible, Likely or Observed)
+	Statuses     []string `json:"statuses"`      // Alert statuses to include (Actual, Exercise, System, Test or Draft).  Defaults to Actual
 }
 
 // NWSPointsResponse defines the expected response format from the NWS points service
@@ -67,13 +73,15 @@ type NWSPointsResponse struct {
 type NWSAlertsResponse struct {
 	Type     string `json:"type"`
 	Features []struct {
-		ID         string `json:"id"`
-		Type       string `json:"type"`
+		ID         string          `json:"id"`
+		Type       string          `json:"type"`
+		Geometry   json.RawMessage `json:"geometry"`
 		Properties struct {
-			ID       string `json:"@id"`
-			Type     string `json:"@type"`
-			AreaDesc string `json:"areaDesc"`
-			Geocode  struct {
+			ID         string `json:"@id"`
+			Type       string `json:"@type"`
+			Identifier string `json:"id"`
+			AreaDesc   string `json:"areaDesc"`
+			Geocode    struct {
 				SAME []string `json:"SAME"`
 				UGC  []string `json:"UGC"`
 			} `json:"geocode"`
@@ -130,37 +138,59 @@ type AlertReport struct {
 
 // AlertItem defines an individual alert item in a report
 type AlertItem struct {
-	Event           string    `json:"event"`            // Short event summary
-	Headline        string    `json:"headline"`         // Full headline description
-	Description     string    `json:"description"`      // Long description of the event
-	Severity        string    `json:"severity"`         // Severity of the event
-	Urgency         string    `json:"urgency"`          // Urgency of the event
-	AreaDescription string    `json:"area_description"` // Affected Area description
-	Sender          string    `json:"sender"`           // Sender (email) of the event
-	SenderName      string    `json:"sendername"`       // Sender name of the event
-	Start           time.Time `json:"start"`            // Event start time
-	End             time.Time `json:"end"`              // Event end time
+	ID              string          `json:"id"`                                      // Unique alert identifier
+	Event           string          `json:"event"`                                   // Short event summary
+	Headline        string          `json:"headline"`                                // Full headline description
+	Description     string          `json:"description"`                             // Long description of the event
+	Severity        string          `json:"severity"`                                // Severity of the event
+	Urgency         string          `json:"urgency"`                                 // Urgency of the event
+	Certainty       string          `json:"certainty"`                               // Certainty of the event
+	Status          string          `json:"status"`                                  // Alert status (Actual, Exercise, System, Test or Draft)
+	MessageType     string          `json:"messagetype"`                             // Message type (Alert, Update or Cancel)
+	AreaDescription string          `json:"area_description"`                        // Affected Area description
+	Sender          string          `json:"sender"`                                  // Sender (email) of the event
+	SenderName      string          `json:"sendername"`                              // Sender name of the event
+	Sent            time.Time       `json:"sent"`                                    // When the alert message was sent
+	Start           time.Time       `json:"start"`                                   // When the alert takes effect
+	Onset           time.Time       `json:"onset"`                                   // When the event is expected to start
+	End             time.Time       `json:"end"`                                     // Event end time
+	NWSHeadline     string          `json:"nwsheadline"`                             // The NWS headline (like 'TORNADO WARNING IN EFFECT UNTIL 6 PM EDT')
+	EventEndingTime time.Time       `json:"eventendingtime"`                         // When the event is expected to end (if the NWS includes it)
+	VTEC            []AlertVTEC     `json:"vtec"`                                    // Parsed VTEC details (tells if the alert is new, continued, cancelled, etc)
+	Geometry        json.RawMessage `json:"geometry,omitempty" swaggertype:"object"` // The area the alert covers as a GeoJSON geometry (if it has one)
 }
 
 // GetWeatherAlerts godoc
 // @Summary Gets the weather alerts for the area specified
-// @Description Gets the weather alerts for the area specified
+// @Description Gets the weather alerts for the area specified.  As application/cap+xml, only the most recently sent alert is returned (a CAP document is a single alert) -- use application/atom+xml to get every alert as CAP
 // @Tags dashboard
 // @Accept  json
 // @Produce  json
+// @Produce  application/cap+xml
+// @Produce  application/atom+xml
 // @Param config body api.AlertsRequest true "The location to get alerts for"
 // @Success 200 {object} api.AlertReport
+// @Success 204 "No alerts (application/cap+xml only)"
+// @Header 200 {string} X-Cache "hit if the response came from the cache, otherwise miss"
+// @Header 200 {integer} Age "How old the cached response is (in seconds)"
 // @Failure 400 {object} api.ErrorResponse
+// @Failure 406 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
+// @Failure 502 {object} api.ErrorResponse
+// @Failure 503 {object} api.ErrorResponse
+// @Failure 504 {object} api.ErrorResponse
 // @Router /alerts [post]
 func (s Service) GetWeatherAlerts(rw http.ResponseWriter, req *http.Request) {
 
 	txn := newrelic.FromContext(req.Context())
 	defer txn.StartSegment("Alerts GetWeatherAlerts").End()
 
-	//	Our return value
-	retval := AlertReport{}
-	retval.Alerts = []AlertItem{} // Initialize the array
+	//	Find out how the client wants the response
+	contentType := negotiateContentType(req, MediaTypeJSON, MediaTypeCAP, MediaTypeAtom)
+	if contentType == "" {
+		sendErrorResponse(rw, newNotAcceptableError(fmt.Errorf("alerts are available as %s, %s or %s", MediaTypeJSON, MediaTypeCAP, MediaTypeAtom)))
+		return
+	}
 
 	//	Parse the request
 	request := AlertsRequest{}
@@ -170,59 +200,81 @@ func (s Service) GetWeatherAlerts(rw http.ResponseWriter, req *http.Request) {
 			"Problem decoding request",
 			"error", err,
 		)
-		sendErrorResponse(rw, err, http.StatusBadRequest)
+		sendErrorResponse(rw, newBadRequestError(err))
 		return
 	}
 
 	//	Make sure we have minimum args:
 	if request.Latitude == "" || request.Longitude == "" {
-		sendErrorResponse(rw, fmt.Errorf("you must include a valid lat and long param"), http.StatusBadRequest)
+		sendErrorResponse(rw, newBadRequestError(fmt.Errorf("you must include a valid lat and long param")))
+		return
+	}
+
+	//	Make sure the filters make sense
+	request, err = normalizeAlertFilters(request)
+	if err != nil {
+		sendErrorResponse(rw, newBadRequestError(err))
 		return
 	}
 
 	//	Add the request
 	txn.AddAttribute("request", request)
 
-	//	First, call the points service for the lat/long specified
-	pointsUrl := fmt.Sprintf("https://api.weather.gov/points/%s,%s", request.Latitude, request.Longitude)
-	clientRequest, err := http.NewRequest("GET", pointsUrl, nil)
+	//	Get the report (from the cache, if we have it)
+	cacheKey := fmt.Sprintf("alerts:%s", coordinateKey(request.Latitude, request.Longitude))
+	cached, cacheResult, err := responseCache.GetOrLoad(cacheKey, cacheTTL("alerts"), func() (interface{}, error) {
+		ctx, cancel := loaderContext(req.Context())
+		defer cancel()
+		return getAlertReport(ctx, request)
+	})
 	if err != nil {
-		zlog.Errorw(
-			"problem creating request to the NWS points service",
-			"error", err,
-		)
 		txn.NoticeError(err)
+		sendErrorResponse(rw, err)
 		return
 	}
 
-	//	Set our headers
-	clientRequest.Header.Set("Content-Type", "application/geo+json; charset=UTF-8")
-	clientRequest = newrelic.RequestWithTransactionContext(clientRequest, txn)
+	//	Only include the alerts that were asked for (the cached report is shared, so it isn't changed)
+	retval := cached.(AlertReport)
+	retval.Alerts = filterAlerts(retval.Alerts, request)
 
-	//	Execute the request
-	client := &http.Client{}
-	client.Transport = newrelic.NewRoundTripper(client.Transport)
-	pointClientResponse, err := ctxhttp.Do(req.Context(), client, clientRequest)
-	if err != nil {
-		zlog.Errorw(
-			"error when sending request to the NWS points service",
-			"error", err,
-		)
-		txn.NoticeError(err)
-		return
+	//	Add the report to the request metadata
+	txn.AddAttribute("response", retval)
+
+	//	Serialize in the format that was asked for & return the response:
+	setCacheHeaders(rw, cacheResult)
+	rw.Header().Add("Vary", "Accept")
+	switch contentType {
+	case MediaTypeCAP:
+		//	A CAP document is a single alert, so it's the newest one (if there are any)
+		alert, found := newestCAPAlert(retval)
+		if !found {
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeXMLResponse(rw, MediaTypeCAP, alert)
+	case MediaTypeAtom:
+		writeXMLResponse(rw, MediaTypeAtom, newAlertsAtomFeed(retval))
+	default:
+		rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(rw).Encode(retval)
 	}
-	defer pointClientResponse.Body.Close()
+}
 
-	//	Decode the response:
-	pointsResponse := NWSPointsResponse{}
-	err = json.NewDecoder(pointClientResponse.Body).Decode(&pointsResponse)
+// getAlertReport gets the alert report for the requested location
+func getAlertReport(ctx context.Context, request AlertsRequest) (AlertReport, error) {
+
+	txn := newrelic.FromContext(ctx)
+	segment := txn.StartSegment("Alerts getAlertReport")
+	defer segment.End()
+
+	//	Our return value
+	retval := AlertReport{}
+	retval.Alerts = []AlertItem{} // Initialize the array
+
+	//	First, call the points service for the lat/long specified
+	pointsResponse, err := getNWSPoints(ctx, request.Latitude, request.Longitude)
 	if err != nil {
-		zlog.Errorw(
-			"problem decoding the response from the NWS points service",
-			"error", err,
-		)
-		txn.NoticeError(err)
-		return
+		return retval, err
 	}
 
 	//	Parse the zone information and add information to the returned report
@@ -236,15 +288,37 @@ func (s Service) GetWeatherAlerts(rw http.ResponseWriter, req *http.Request) {
 	retval.City = pointsResponse.Properties.RelativeLocation.Properties.City
 
 	//	Call the alerts service for the lat/long specified
-	alertsServiceUrl := fmt.Sprintf("https://api.weather.gov/alerts?point=%s,%s", request.Latitude, request.Longitude)
+	alertsResponse, err := fetchNWSAlerts(ctx, fmt.Sprintf("https://api.weather.gov/alerts?point=%s,%s", request.Latitude, request.Longitude))
+	if err != nil {
+		return retval, err
+	}
+
+	//	Compile our report
+	retval.Alerts = buildAlertItems(alertsResponse)
+
+	//	Keep a record of what we've seen
+	recordAlertHistory(coordinateKey(request.Latitude, request.Longitude), retval)
+
+	return retval, nil
+}
+
+// fetchNWSAlerts calls the NWS alerts service with the given url
+func fetchNWSAlerts(ctx context.Context, alertsServiceUrl string) (NWSAlertsResponse, error) {
+
+	txn := newrelic.FromContext(ctx)
+	segment := txn.StartSegment("Alerts fetchNWSAlerts")
+	defer segment.End()
+
+	//	Our return value
+	retval := NWSAlertsResponse{}
+
 	alertClientRequest, err := http.NewRequest("GET", alertsServiceUrl, nil)
 	if err != nil {
 		zlog.Errorw(
 			"problem creating request to the NWS alerts service",
 			"error", err,
 		)
-		txn.NoticeError(err)
-		return
+		return retval, newInternalError(fmt.Errorf("problem creating request to the NWS alerts service: %v", err))
 	}
 
 	//	Set our headers
@@ -252,54 +326,97 @@ func (s Service) GetWeatherAlerts(rw http.ResponseWriter, req *http.Request) {
 	alertClientRequest = newrelic.RequestWithTransactionContext(alertClientRequest, txn)
 
 	//	Execute the request
-	alertClient := &http.Client{}
-	alertClient.Transport = newrelic.NewRoundTripper(alertClient.Transport)
-	alertClientResponse, err := ctxhttp.Do(req.Context(), alertClient, alertClientRequest)
+	alertClientResponse, err := ctxhttp.Do(ctx, httpclient.Default(), alertClientRequest)
 	if err != nil {
 		zlog.Errorw(
 			"error when sending request to the NWS alerts service",
 			"error", err,
 		)
-		txn.NoticeError(err)
-		return
+		return retval, newUpstreamError(fmt.Errorf("error when sending request to the NWS alerts service: %w", err))
 	}
 	defer alertClientResponse.Body.Close()
 
+	//	If the HTTP status code indicates an error, report it and get out
+	if alertClientResponse.StatusCode >= 400 {
+		return retval, newUpstreamStatusError(fmt.Errorf("error getting information from the NWS alerts service: %s", alertClientResponse.Status), alertClientResponse.StatusCode)
+	}
+
 	//	Decode the response:
-	alertsResponse := NWSAlertsResponse{}
-	err = json.NewDecoder(alertClientResponse.Body).Decode(&alertsResponse)
+	err = json.NewDecoder(alertClientResponse.Body).Decode(&retval)
 	if err != nil {
 		zlog.Errorw(
 			"problem decoding the response from the NWS alerts service",
 			"error", err,
 		)
-		txn.NoticeError(err)
-		return
+		return retval, newUpstreamBadDataError(fmt.Errorf("problem decoding the response from the NWS alerts service: %v", err))
 	}
 
-	//	Compile our report
+	return retval, nil
+}
+
+// buildAlertItems gets the alert items from an NWS alerts response.  Anything that has been replaced
+// by a later update is skipped, and the most important alerts come first
+func buildAlertItems(alertsResponse NWSAlertsResponse) []AlertItem {
+	retval := []AlertItem{}
+
+	superseded := supersededAlertIDs(alertsResponse)
 	for _, item := range alertsResponse.Features {
 
+		if superseded[item.Properties.Identifier] {
+			continue
+		}
+
 		alertItem := AlertItem{
+			ID:              item.Properties.Identifier,
 			Event:           item.Properties.Event,
 			Headline:        item.Properties.Headline,
 			Description:     item.Properties.Description,
 			Severity:        item.Properties.Severity,
 			Urgency:         item.Properties.Urgency,
+			Certainty:       item.Properties.Certainty,
+			Status:          item.Properties.Status,
+			MessageType:     item.Properties.MessageType,
 			AreaDescription: item.Properties.AreaDesc,
 			Sender:          item.Properties.Sender,
 			SenderName:      item.Properties.SenderName,
+			Sent:            item.Properties.Sent,
 			Start:           item.Properties.Effective,
+			Onset:           item.Properties.Onset,
 			End:             item.Properties.Ends,
+			VTEC:            []AlertVTEC{},
+		}
+
+		//	Include the NWS details
+		if len(item.Properties.Parameters.NWSheadline) > 0 {
+			alertItem.NWSHeadline = item.Properties.Parameters.NWSheadline[0]
+		}
+
+		if len(item.Properties.Parameters.EventEndingTime) > 0 {
+			alertItem.EventEndingTime = item.Properties.Parameters.EventEndingTime[0]
+		}
+
+		for _, raw := range item.Properties.Parameters.VTEC {
+			vtec, err := parseVTEC(raw)
+			if err != nil {
+				zlog.Warnw(
+					"problem parsing VTEC for alert",
+					"alert", item.Properties.Identifier,
+					"error", err,
+				)
+				continue
+			}
+			alertItem.VTEC = append(alertItem.VTEC, vtec)
 		}
 
-		retval.Alerts = append(retval.Alerts, alertItem)
+		if len(item.Geometry) > 0 && string(item.Geometry) != "null" {
+			alertItem.Geometry = item.Geometry
+		}
+
+		retval = append(retval, alertItem)
 	}
 
-	//	Add the report to the request metadata
-	txn.AddAttribute("response", retval)
+	//	Put the most important alerts first
+	sortAlerts(retval)
 
-	//	Serialize to JSON & return the response:
-	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(rw).Encode(retval)
+	return retval
 }
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CAP severity, urgency and certainty values -- ranked from least to most important.
+// 'Unknown' (or anything we don't recognize) ranks below all of them
+var (
+	alertSeverityRanks  = map[string]int{"minor": 1, "moderate": 2, "severe": 3, "extreme": 4}
+	alertUrgencyRanks   = map[string]int{"past": 1, "future": 2, "expected": 3, "immediate": 4}
+	alertCertaintyRanks = map[string]int{"unlikely": 1, "possible": 2, "likely": 3, "observed": 4}
+	alertStatuses       = map[string]string{"actual": "Actual", "exercise": "Exercise", "system": "System", "test": "Test", "draft": "Draft"}
+)
+
+// normalizeAlertFilters checks the filter options in an alerts request and puts them in a standard form.
+// If no statuses are requested, only 'Actual' alerts are included
+func normalizeAlertFilters(request AlertsRequest) (AlertsRequest, error) {
+
+	checks := []struct {
+		name  string
+		value *string
+		ranks map[string]int
+		valid string
+	}{
+		{"min_severity", &request.MinSeverity, alertSeverityRanks, "Minor, Moderate, Severe or Extreme"},
+		{"min_urgency", &request.MinUrgency, alertUrgencyRanks, "Past, Future, Expected or Immediate"},
+		{"min_certainty", &request.MinCertainty, alertCertaintyRanks, "Unlikely, Possible, Likely or Observed"},
+	}
+
+	for _, check := range checks {
+		*check.value = strings.ToLower(strings.TrimSpace(*check.value))
+		if _, found := check.ranks[*check.value]; *check.value != "" && !found {
+			return request, fmt.Errorf("%s must be one of %s", check.name, check.valid)
+		}
+	}
+
+	statuses := []string{}
+	for _, status := range request.Statuses {
+		normalized, found := alertStatuses[strings.ToLower(strings.TrimSpace(status))]
+		if !found {
+			return request, fmt.Errorf("statuses must be Actual, Exercise, System, Test or Draft")
+		}
+		statuses = append(statuses, normalized)
+	}
+
+	if len(statuses) == 0 {
+		statuses = []string{"Actual"}
+	}
+	request.Statuses = statuses
+
+	return request, nil
+}
+
+// filterAlerts gets the alerts that match the (normalized) request filters.  The alerts passed
+// in aren't changed, and the order is kept
+func filterAlerts(alerts []AlertItem, request AlertsRequest) []AlertItem {
+	retval := []AlertItem{}
+
+	for _, alert := range alerts {
+		if !alertHasStatus(alert, request.Statuses) {
+			continue
+		}
+
+		if !meetsAlertMinimum(alert.Severity, request.MinSeverity, alertSeverityRanks) ||
+			!meetsAlertMinimum(alert.Urgency, request.MinUrgency, alertUrgencyRanks) ||
+			!meetsAlertMinimum(alert.Certainty, request.MinCertainty, alertCertaintyRanks) {
+			continue
+		}
+
+		retval = append(retval, alert)
+	}
+
+	return retval
+}
+
+// alertHasStatus returns true if the alert has one of the given statuses
+func alertHasStatus(alert AlertItem, statuses []string) bool {
+	for _, status := range statuses {
+		if strings.EqualFold(alert.Status, status) {
+			return true
+		}
+	}
+	return false
+}
+
+// meetsAlertMinimum returns true if the value ranks at least as high as the minimum (or there is no minimum)
+func meetsAlertMinimum(value, minimum string, ranks map[string]int) bool {
+	if minimum == "" {
+		return true
+	}
+	return ranks[strings.ToLower(value)] >= ranks[minimum]
+}
+
+// sortAlerts sorts alerts so the most severe comes first.  Alerts with the same severity
+// are sorted by when they start (soonest first)
+func sortAlerts(alerts []AlertItem) {
+	sort.SliceStable(alerts, func(i, j int) bool {
+		iSeverity := alertSeverityRanks[strings.ToLower(alerts[i].Severity)]
+		jSeverity := alertSeverityRanks[strings.ToLower(alerts[j].Severity)]
+		if iSeverity != jSeverity {
+			return iSeverity > jSeverity
+		}
+
+		return alertOnset(alerts[i]).Before(alertOnset(alerts[j]))
+	})
+}
+
+// supersededAlertIDs gets the IDs of alerts that have been replaced by a later update.  An alert is
+// replaced if another alert references it, or lists it in its expired references
+// (formatted as 'sender,identifier,sent')
+func supersededAlertIDs(response NWSAlertsResponse) map[string]bool {
+	retval := map[string]bool{}
+
+	for _, feature := range response.Features {
+		for _, reference := range feature.Properties.References {
+			retval[reference.Identifier] = true
+		}
+
+		for _, expired := range feature.Properties.Parameters.ExpiredReferences {
+			for _, reference := range strings.Split(expired, " ") {
+				parts := strings.Split(reference, ",")
+				if len(parts) >= 2 {
+					retval[parts[1]] = true
+				}
+			}
+		}
+	}
+
+	return retval
+}
+
+// alertOnset gets when the alert's event starts (or when the alert took effect, if there's no onset)
+func alertOnset(alert AlertItem) time.Time {
+	if !alert.Onset.IsZero() {
+		return alert.Onset
+	}
+	return alert.Start
+}
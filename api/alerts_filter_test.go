@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNormalizeAlertFilters_Defaults_OnlyActual(t *testing.T) {
+	//	Act
+	request, err := normalizeAlertFilters(AlertsRequest{MinSeverity: " SEVERE "})
+
+	//	Assert
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+
+	if len(request.Statuses) != 1 || request.Statuses[0] != "Actual" {
+		t.Errorf("Expected only Actual statuses but got %v instead", request.Statuses)
+	}
+
+	if request.MinSeverity != "severe" {
+		t.Errorf("Expected severity 'severe' but got '%v' instead", request.MinSeverity)
+	}
+}
+
+func TestNormalizeAlertFilters_UnknownValues_ReturnsError(t *testing.T) {
+	//	Arrange
+	tests := []AlertsRequest{
+		{MinSeverity: "catastrophic"},
+		{MinUrgency: "whenever"},
+		{MinCertainty: "maybe"},
+		{Statuses: []string{"Actual", "Rumor"}},
+	}
+
+	for _, test := range tests {
+		//	Act
+		_, err := normalizeAlertFilters(test)
+
+		//	Assert
+		if err == nil {
+			t.Errorf("Didn't return an error for %+v and we expected one", test)
+		}
+	}
+}
+
+func TestFilterAlerts_AppliesMinimumsAndStatuses(t *testing.T) {
+	//	Arrange
+	alerts := []AlertItem{
+		{ID: "extreme", Severity: "Extreme", Urgency: "Immediate", Certainty: "Observed", Status: "Actual"},
+		{ID: "minor", Severity: "Minor", Urgency: "Immediate", Certainty: "Observed", Status: "Actual"},
+		{ID: "future", Severity: "Severe", Urgency: "Future", Certainty: "Likely", Status: "Actual"},
+		{ID: "test", Severity: "Extreme", Urgency: "Immediate", Certainty: "Observed", Status: "Test"},
+		{ID: "unknown", Severity: "Unknown", Urgency: "Unknown", Certainty: "Unknown", Status: "Actual"},
+	}
+	request, _ := normalizeAlertFilters(AlertsRequest{MinSeverity: "moderate", MinUrgency: "expected"})
+
+	//	Act
+	filtered := filterAlerts(alerts, request)
+
+	//	Assert
+	if len(filtered) != 1 || filtered[0].ID != "extreme" {
+		t.Errorf("Expected only the 'extreme' alert but got %+v instead", filtered)
+	}
+
+	if len(alerts) != 5 {
+		t.Errorf("Expected the original alerts to be left alone but now there are %v", len(alerts))
+	}
+}
+
+func TestSortAlerts_SeverityThenOnset(t *testing.T) {
+	//	Arrange
+	now := time.Now()
+	alerts := []AlertItem{
+		{ID: "moderate", Severity: "Moderate", Onset: now},
+		{ID: "severe-later", Severity: "Severe", Onset: now.Add(2 * time.Hour)},
+		{ID: "severe-sooner", Severity: "Severe", Start: now.Add(time.Hour)},
+		{ID: "extreme", Severity: "Extreme", Onset: now.Add(5 * time.Hour)},
+	}
+
+	//	Act
+	sortAlerts(alerts)
+
+	//	Assert
+	expected := []string{"extreme", "severe-sooner", "severe-later", "moderate"}
+	for i, id := range expected {
+		if alerts[i].ID != id {
+			t.Errorf("Expected %v at position %v but got %v instead", id, i, alerts[i].ID)
+		}
+	}
+}
+
+func TestSupersededAlertIDs_FindsReferencedAlerts(t *testing.T) {
+	//	Arrange
+	response := NWSAlertsResponse{}
+	err := json.Unmarshal([]byte(`{"features": [
+		{"properties": {"id": "urn:oid:new", "references": [{"identifier": "urn:oid:old"}]}},
+		{"properties": {"id": "urn:oid:old"}},
+		{"properties": {"id": "urn:oid:other", "parameters": {"expiredReferences": ["w-nws.webmaster@noaa.gov,urn:oid:expired,2022-06-01T12:00:00-04:00"]}}}
+	]}`), &response)
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	//	Act
+	superseded := supersededAlertIDs(response)
+
+	//	Assert
+	for _, id := range []string{"urn:oid:old", "urn:oid:expired"} {
+		if !superseded[id] {
+			t.Errorf("Expected %v to be superseded", id)
+		}
+	}
+
+	if superseded["urn:oid:new"] {
+		t.Errorf("Didn't expect urn:oid:new to be superseded")
+	}
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/alerthistory"
+	"github.com/spf13/viper"
+)
+
+var (
+	alertHistoryStore     alerthistory.Store
+	alertHistoryStoreOnce sync.Once
+)
+
+// AlertHistoryReport defines the alerts the service has seen
+type AlertHistoryReport struct {
+	Alerts []alerthistory.Record `json:"alerts"` // Alerts seen (most recently seen first)
+}
+
+// OpenAlertHistoryStore opens the alert history store.  It's called when the service starts, so a
+// slow (or broken) MongoDB connection is found then -- not while somebody is waiting for their alerts
+func OpenAlertHistoryStore() {
+	if !viper.GetBool("alerts.history.enabled") {
+		return
+	}
+
+	switch getAlertHistoryStore().(type) {
+	case *alerthistory.MongoStore:
+		zlog.Infow("keeping alert history in MongoDB")
+	default:
+		zlog.Infow(
+			"keeping alert history in memory",
+			"maxRecords", viper.GetInt("alerts.history.memory.maxrecords"),
+			"retention", viper.GetDuration("alerts.history.memory.retention"),
+		)
+	}
+}
+
+// getAlertHistoryStore gets the alert history store (creating it the first time it's needed).  History
+// is kept in MongoDB (using the news database connection) when it's configured, and in memory otherwise
+func getAlertHistoryStore() alerthistory.Store {
+	alertHistoryStoreOnce.Do(func() {
+		alertHistoryStore = alerthistory.NewMemoryStore(viper.GetInt("alerts.history.memory.maxrecords"), viper.GetDuration("alerts.history.memory.retention"))
+
+		uri := viper.GetString("news.mongodb")
+		if uri == "" {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		store, err := alerthistory.NewMongoStore(ctx, uri)
+		if err != nil {
+			zlog.Errorw(
+				"problem opening the alert history store in MongoDB -- keeping alert history in memory",
+				"error", err,
+			)
+			return
+		}
+
+		alertHistoryStore = store
+	})
+
+	return alertHistoryStore
+}
+
+// recordAlertHistory saves the alerts in a report to the alert history.  It's saved in the background
+// (with its own timeout) and problems are logged -- the history is nice to have, it shouldn't slow
+// anybody down or stop them from getting their alerts
+func recordAlertHistory(location string, report AlertReport) {
+	if !viper.GetBool("alerts.history.enabled") || len(report.Alerts) == 0 {
+		return
+	}
+
+	records := []alerthistory.Record{}
+	for _, alert := range report.Alerts {
+		records = append(records, alerthistory.Record{
+			Location:  location,
+			City:      report.City,
+			State:     report.State,
+			County:    report.NWSCounty,
+			AlertID:   alert.ID,
+			Event:     alert.Event,
+			Headline:  alert.Headline,
+			Severity:  alert.Severity,
+			Urgency:   alert.Urgency,
+			Certainty: alert.Certainty,
+			Status:    alert.Status,
+			Start:     alert.Start,
+			End:       alert.End,
+		})
+	}
+
+	seen := time.Now().UTC()
+	go func() {
+		timeout := viper.GetDuration("alerts.history.timeout")
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if err := getAlertHistoryStore().Record(ctx, records, seen); err != nil {
+			zlog.Errorw(
+				"problem recording alert history",
+				"location", location,
+				"error", err,
+			)
+		}
+	}()
+}
+
+// GetAlertHistory godoc
+// @Summary Gets the weather alerts the service has seen
+// @Description Gets the weather alerts the service has seen (including expired alerts), most recently seen first.  Alerts are included if they were seen at any point in the time range.  Requires the admin token (server.admintoken) as a bearer token
+// @Tags admin
+// @Produce  json
+// @Param lat query string false "Only alerts for this latitude (requires long)"
+// @Param long query string false "Only alerts for this longitude (requires lat)"
+// @Param event query string false "Only alerts for this event type (like 'Tornado Warning')"
+// @Param from query string false "Only alerts seen at or after this time (RFC 3339)"
+// @Param to query string false "Only alerts seen at or before this time (RFC 3339)"
+// @Param limit query int false "The most alerts to return (defaults to 100, at most 1000)"
+// @Success 200 {object} api.AlertHistoryReport
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 401 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /alerts/history [get]
+func (s Service) GetAlertHistory(rw http.ResponseWriter, req *http.Request) {
+
+	//	The history includes every location the service has been asked about
+	if err := checkAdminToken(req); err != nil {
+		sendErrorResponse(rw, err)
+		return
+	}
+
+	//	Parse the request
+	query, err := parseAlertHistoryQuery(req)
+	if err != nil {
+		sendErrorResponse(rw, newBadRequestError(err))
+		return
+	}
+
+	records, err := getAlertHistoryStore().Find(req.Context(), query)
+	if err != nil {
+		zlog.Errorw(
+			"problem finding alert history",
+			"error", err,
+		)
+		sendErrorResponse(rw, newInternalError(err))
+		return
+	}
+
+	retval := AlertHistoryReport{Alerts: records}
+
+	//	Serialize to JSON & return the response:
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(rw).Encode(retval)
+}
+
+// parseAlertHistoryQuery gets the alert history query from the request querystring
+func parseAlertHistoryQuery(req *http.Request) (alerthistory.Query, error) {
+	values := req.URL.Query()
+	retval := alerthistory.Query{
+		Event: values.Get("event"),
+		Limit: 100,
+	}
+
+	lat, long := values.Get("lat"), values.Get("long")
+	if (lat == "") != (long == "") {
+		return retval, fmt.Errorf("lat and long must be used together")
+	}
+	if lat != "" {
+		retval.Location = coordinateKey(lat, long)
+	}
+
+	times := []struct {
+		name  string
+		value *time.Time
+	}{
+		{"from", &retval.From},
+		{"to", &retval.To},
+	}
+	for _, t := range times {
+		if raw := values.Get(t.name); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return retval, fmt.Errorf("%s must be an RFC 3339 time (like 2022-06-01T17:00:00Z)", t.name)
+			}
+			*t.value = parsed
+		}
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > 1000 {
+			return retval, fmt.Errorf("limit must be a number from 1 to 1000")
+		}
+		retval.Limit = limit
+	}
+
+	return retval, nil
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestParseAlertHistoryQuery_ValidQuery_ReturnsQuery(t *testing.T) {
+	//	Arrange
+	req := httptest.NewRequest("GET", "/v2/alerts/history?lat=33.8712&long=-84.0012&event=Tornado+Warning&from=2022-06-01T00:00:00Z&limit=5", nil)
+
+	//	Act
+	query, err := parseAlertHistoryQuery(req)
+
+	//	Assert
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+
+	if query.Location != "33.87,-84.00" {
+		t.Errorf("Expected the rounded location but got %v instead", query.Location)
+	}
+
+	if query.Event != "Tornado Warning" || query.Limit != 5 {
+		t.Errorf("Expected the event and limit but got %v and %v instead", query.Event, query.Limit)
+	}
+
+	if !query.From.Equal(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)) || !query.To.IsZero() {
+		t.Errorf("Expected only a from time but got %v and %v instead", query.From, query.To)
+	}
+}
+
+func TestParseAlertHistoryQuery_BadValues_ReturnsError(t *testing.T) {
+	tests := []string{
+		"/v2/alerts/history?lat=33.87",
+		"/v2/alerts/history?from=yesterday",
+		"/v2/alerts/history?limit=0",
+		"/v2/alerts/history?limit=5000",
+	}
+
+	for _, url := range tests {
+		//	Act
+		_, err := parseAlertHistoryQuery(httptest.NewRequest("GET", url, nil))
+
+		//	Assert
+		if err == nil {
+			t.Errorf("Expected an error for %v but didn't get one", url)
+		}
+	}
+}
+
+func TestGetAlertHistory_RequiresAdminToken(t *testing.T) {
+	//	Arrange
+	viper.Set("server.admintoken", "secret")
+	defer viper.Set("server.admintoken", nil)
+
+	anonymous := httptest.NewRecorder()
+	authorized := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/v2/alerts/history", nil)
+
+	//	Act
+	Service{}.GetAlertHistory(anonymous, req)
+	req.Header.Set("Authorization", "Bearer secret")
+	Service{}.GetAlertHistory(authorized, req)
+
+	//	Assert
+	if anonymous.Code != http.StatusUnauthorized || authorized.Code != http.StatusOK {
+		t.Errorf("Expected a 401 without the token and a 200 with it but got %v and %v instead", anonymous.Code, authorized.Code)
+	}
+}
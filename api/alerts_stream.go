@@ -0,0 +1,430 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/spf13/viper"
+)
+
+// Alert stream event types
+const (
+	AlertEventNew     = "alert.new"     // An alert we haven't seen before
+	AlertEventUpdated = "alert.updated" // A new version of an alert we've already seen
+	AlertEventExpired = "alert.expired" // An alert that's no longer active
+)
+
+// AlertStreamEvent is a single event on the alert stream
+type AlertStreamEvent struct {
+	Type  string    `json:"type"`  // alert.new, alert.updated or alert.expired
+	Zone  string    `json:"zone"`  // The NWS zones polled for the alert (the forecast zone and county, like GAZ045,GAC135)
+	Alert AlertItem `json:"alert"` // The alert (for expired alerts, the last version we saw)
+}
+
+// alertStreams is the hub that shares NWS polling between everybody streaming alerts for the same zones
+var alertStreams = &alertStreamHub{pollers: map[string]*alertZonePoller{}}
+
+// alertStreamHub keeps track of a poller for each set of zones that has subscribers
+type alertStreamHub struct {
+	mu      sync.Mutex
+	pollers map[string]*alertZonePoller
+}
+
+// alertZonePoller polls the active alerts for a set of zones and sends the changes to its subscribers.
+// All of its fields are protected by the hub's lock
+type alertZonePoller struct {
+	zone        string // Comma separated NWS zones, like GAZ045,GAC135
+	cancel      context.CancelFunc
+	subscribers map[chan AlertStreamEvent]*alertSubscriber
+	current     map[string]AlertItem // The active alerts (keyed by alertEventKey)
+	ready       bool                 // We've successfully polled at least once
+}
+
+// alertSubscriber is somebody listening to a poller.  Each subscriber has its own filters, so
+// we keep track of the alerts it's been sent to know when an alert enters or leaves its filters
+type alertSubscriber struct {
+	ch       chan AlertStreamEvent
+	filters  AlertsRequest
+	sent     map[string]bool // The alerts this subscriber has been sent (keyed by alertEventKey)
+	location string          // The subscriber's location (see coordinateKey) for the alert history
+	report   AlertReport     // The city, state and county for the alert history
+}
+
+// subscriberEvent gets the event to send to the subscriber for an event from the poller (if any).
+// An alert that starts matching the filters is sent as a new alert, and one that stops matching
+// is sent as expired -- so the subscriber always sees new, then updated, then expired
+func (s *alertSubscriber) subscriberEvent(event AlertStreamEvent) (AlertStreamEvent, bool) {
+	key := alertEventKey(event.Alert)
+	matches := event.Type != AlertEventExpired && len(filterAlerts([]AlertItem{event.Alert}, s.filters)) > 0
+
+	switch {
+	case matches && !s.sent[key]:
+		s.sent[key] = true
+		event.Type = AlertEventNew
+	case matches:
+		event.Type = AlertEventUpdated
+	case s.sent[key]:
+		delete(s.sent, key)
+		event.Type = AlertEventExpired
+	default:
+		return event, false
+	}
+
+	return event, true
+}
+
+// subscribe starts listening for alert events in the given zones (starting a poller if there isn't one).
+// Only the alerts that match the subscriber's filters are sent.  If the zones have already been polled,
+// the currently active alerts are sent as new alerts.  The channel is closed if the subscriber can't
+// keep up.  Call the returned function to stop listening
+func (h *alertStreamHub) subscribe(zone string, subscriber *alertSubscriber) (<-chan AlertStreamEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	poller, found := h.pollers[zone]
+	if !found {
+		ctx, cancel := context.WithCancel(context.Background())
+		poller = &alertZonePoller{
+			zone:        zone,
+			cancel:      cancel,
+			subscribers: map[chan AlertStreamEvent]*alertSubscriber{},
+			current:     map[string]AlertItem{},
+		}
+		h.pollers[zone] = poller
+		go h.poll(ctx, poller, viper.GetDuration("alerts.stream.interval"))
+	}
+
+	ch := make(chan AlertStreamEvent, len(poller.current)+32)
+	subscriber.ch = ch
+	subscriber.sent = map[string]bool{}
+	poller.subscribers[ch] = subscriber
+
+	if poller.ready {
+		for _, alert := range sortedAlertMap(poller.current) {
+			if event, send := subscriber.subscriberEvent(AlertStreamEvent{Type: AlertEventNew, Zone: zone, Alert: alert}); send {
+				ch <- event
+			}
+		}
+	}
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if poller.subscribers[ch] != nil {
+			delete(poller.subscribers, ch)
+			close(ch)
+		}
+
+		//	If nobody is listening anymore, stop polling
+		if len(poller.subscribers) == 0 && h.pollers[zone] == poller {
+			poller.cancel()
+			delete(h.pollers, zone)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// poll checks the zone for alerts every interval until the context is cancelled
+func (h *alertStreamHub) poll(ctx context.Context, poller *alertZonePoller, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.pollOnce(ctx, poller)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollOnce gets the active alerts for the zones and sends any changes to the subscribers
+func (h *alertStreamHub) pollOnce(ctx context.Context, poller *alertZonePoller) {
+	response, err := fetchNWSAlerts(ctx, fmt.Sprintf("https://api.weather.gov/alerts/active?zone=%s", poller.zone))
+	if err != nil {
+		if ctx.Err() == nil {
+			zlog.Errorw(
+				"problem polling alerts for the alert stream",
+				"zone", poller.zone,
+				"error", err,
+			)
+		}
+		return
+	}
+	alerts := buildAlertItems(response)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	events, current := diffAlerts(poller.current, alerts)
+	poller.current = current
+	poller.ready = true
+
+	//	Keep a record of what we've seen (once for each location listening)
+	recorded := map[string]bool{}
+	for _, subscriber := range poller.subscribers {
+		if subscriber.location == "" || recorded[subscriber.location] {
+			continue
+		}
+		recorded[subscriber.location] = true
+
+		report := subscriber.report
+		report.Alerts = alerts
+		recordAlertHistory(subscriber.location, report)
+	}
+
+	for ch, subscriber := range poller.subscribers {
+		for _, event := range events {
+			event.Zone = poller.zone
+
+			subscriberEvent, send := subscriber.subscriberEvent(event)
+			if !send {
+				continue
+			}
+
+			select {
+			case ch <- subscriberEvent:
+			default:
+				//	The subscriber can't keep up -- drop it (it can reconnect and start fresh)
+				zlog.Warnw(
+					"alert stream subscriber is too slow -- disconnecting it",
+					"zone", poller.zone,
+				)
+				delete(poller.subscribers, ch)
+				close(ch)
+			}
+
+			if poller.subscribers[ch] == nil {
+				break
+			}
+		}
+	}
+}
+
+// diffAlerts compares the previously active alerts with the current ones and gets the events
+// that describe the changes (along with the new set of active alerts)
+func diffAlerts(previous map[string]AlertItem, alerts []AlertItem) ([]AlertStreamEvent, map[string]AlertItem) {
+	events := []AlertStreamEvent{}
+	current := map[string]AlertItem{}
+
+	for _, alert := range alerts {
+		key := alertEventKey(alert)
+		if _, seen := current[key]; seen {
+			continue
+		}
+		current[key] = alert
+
+		last, found := previous[key]
+		switch {
+		case !found:
+			events = append(events, AlertStreamEvent{Type: AlertEventNew, Alert: alert})
+		case last.ID != alert.ID || !last.End.Equal(alert.End):
+			events = append(events, AlertStreamEvent{Type: AlertEventUpdated, Alert: alert})
+		}
+	}
+
+	for _, alert := range sortedAlertMap(previous) {
+		if _, found := current[alertEventKey(alert)]; !found {
+			events = append(events, AlertStreamEvent{Type: AlertEventExpired, Alert: alert})
+		}
+	}
+
+	return events, current
+}
+
+// alertEventKey gets the key that identifies the event an alert is about.  Updates to an alert
+// get a new ID, but keep the same VTEC event (office, phenomena, significance and tracking number)
+func alertEventKey(alert AlertItem) string {
+	if len(alert.VTEC) > 0 {
+		vtec := alert.VTEC[0]
+		return fmt.Sprintf("%s.%s.%s.%04d", vtec.Office, vtec.Phenomena, vtec.Significance, vtec.EventTrackingNumber)
+	}
+	return alert.ID
+}
+
+// sortedAlertMap gets the alerts in a map in a predictable order
+func sortedAlertMap(alerts map[string]AlertItem) []AlertItem {
+	retval := []AlertItem{}
+	for _, alert := range alerts {
+		retval = append(retval, alert)
+	}
+
+	//	Map order is random, so start with the IDs in order (so alerts that sort the same stay in order)
+	sort.Slice(retval, func(i, j int) bool {
+		return retval[i].ID < retval[j].ID
+	})
+	sortAlerts(retval)
+	return retval
+}
+
+// alertStreamZones gets the NWS zones to poll for a location.  Most alerts are issued for forecast
+// zones, but storm-based warnings (tornado and severe thunderstorm warnings, for example) are issued
+// for counties -- so we need both
+func alertStreamZones(pointsResponse NWSPointsResponse) (string, error) {
+	zones := []string{}
+	for _, zoneURL := range []string{pointsResponse.Properties.ForecastZone, pointsResponse.Properties.County} {
+		zone := path.Base(zoneURL)
+		if zone == "" || zone == "." || zone == "/" {
+			continue
+		}
+		zones = append(zones, zone)
+	}
+
+	if len(zones) == 0 {
+		return "", fmt.Errorf("the NWS points service didn't include a forecast zone or county")
+	}
+
+	return strings.Join(zones, ","), nil
+}
+
+// writeAlertStreamEvent writes an event in the server-sent events format
+func writeAlertStreamEvent(w io.Writer, event AlertStreamEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\nid: %s\ndata: %s\n\n", event.Type, event.Alert.ID, data)
+	return err
+}
+
+// alertStreamRequest gets the alerts request from the query string of an alert stream request.
+// The filters use the same names as the /v2/alerts request
+func alertStreamRequest(query url.Values) AlertsRequest {
+	retval := AlertsRequest{
+		Latitude:     query.Get("lat"),
+		Longitude:    query.Get("long"),
+		MinSeverity:  query.Get("min_severity"),
+		MinUrgency:   query.Get("min_urgency"),
+		MinCertainty: query.Get("min_certainty"),
+	}
+
+	if statuses := query.Get("statuses"); statuses != "" {
+		retval.Statuses = strings.Split(statuses, ",")
+	}
+
+	return retval
+}
+
+// StreamWeatherAlerts godoc
+// @Summary Streams weather alerts for the area specified
+// @Description Streams server-sent events as weather alerts for the area specified are issued (alert.new), updated (alert.updated) and expire (alert.expired).  The currently active alerts are sent as alert.new events when the stream starts
+// @Tags dashboard
+// @Produce  text/event-stream
+// @Param lat query string true "Latitude"
+// @Param long query string true "Longitude"
+// @Param min_severity query string false "Only include alerts at least this severe (Minor, Moderate, Severe or Extreme)"
+// @Param min_urgency query string false "Only include alerts at least this urgent (Past, Future, Expected or Immediate)"
+// @Param min_certainty query string false "Only include alerts at least this certain (Unlikely, Possible, Likely or Observed)"
+// @Param statuses query string false "Comma separated alert statuses to include (defaults to Actual)"
+// @Success 200 {object} api.AlertStreamEvent
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Failure 502 {object} api.ErrorResponse
+// @Failure 503 {object} api.ErrorResponse
+// @Router /alerts/stream [get]
+func (s Service) StreamWeatherAlerts(rw http.ResponseWriter, req *http.Request) {
+
+	//	Streams stay open for a long time -- don't report them as slow transactions
+	txn := newrelic.FromContext(req.Context())
+	txn.Ignore()
+
+	//	Parse the request
+	request := alertStreamRequest(req.URL.Query())
+
+	//	Make sure we have minimum args:
+	if request.Latitude == "" || request.Longitude == "" {
+		sendErrorResponse(rw, newBadRequestError(fmt.Errorf("you must include a valid lat and long param")))
+		return
+	}
+
+	request, err := normalizeAlertFilters(request)
+	if err != nil {
+		sendErrorResponse(rw, newBadRequestError(err))
+		return
+	}
+
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		sendErrorResponse(rw, newInternalError(fmt.Errorf("streaming isn't supported")))
+		return
+	}
+
+	//	Find the zone for the location
+	pointsResponse, err := getNWSPoints(req.Context(), request.Latitude, request.Longitude)
+	if err != nil {
+		sendErrorResponse(rw, err)
+		return
+	}
+
+	zone, err := alertStreamZones(pointsResponse)
+	if err != nil {
+		sendErrorResponse(rw, newUpstreamBadDataError(err))
+		return
+	}
+
+	//	Start listening
+	subscriber := &alertSubscriber{
+		filters:  request,
+		location: coordinateKey(request.Latitude, request.Longitude),
+		report: AlertReport{
+			City:      pointsResponse.Properties.RelativeLocation.Properties.City,
+			State:     pointsResponse.Properties.RelativeLocation.Properties.State,
+			NWSCounty: pointsResponse.Properties.County,
+		},
+	}
+	events, unsubscribe := alertStreams.subscribe(zone, subscriber)
+	defer unsubscribe()
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+	fmt.Fprintf(rw, "retry: 10000\n: streaming alerts for zones %s\n\n", zone)
+	flusher.Flush()
+
+	//	Keep the connection alive when there's nothing to say
+	keepalive := time.NewTicker(30 * time.Second)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(rw, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case event, open := <-events:
+			if !open {
+				return
+			}
+
+			if err := writeAlertStreamEvent(rw, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffAlerts_FindsNewUpdatedAndExpired(t *testing.T) {
+	//	Arrange
+	warning := AlertItem{ID: "urn:1", Severity: "Extreme", VTEC: []AlertVTEC{{Office: "KFFC", Phenomena: "TO", Significance: "W", EventTrackingNumber: 42}}}
+	advisory := AlertItem{ID: "urn:2", Severity: "Minor"}
+	previous := map[string]AlertItem{
+		alertEventKey(warning):  warning,
+		alertEventKey(advisory): advisory,
+	}
+
+	updatedWarning := warning
+	updatedWarning.ID = "urn:3"
+	updatedWarning.End = time.Now().Add(time.Hour)
+	watch := AlertItem{ID: "urn:4", Severity: "Severe"}
+
+	//	Act
+	events, current := diffAlerts(previous, []AlertItem{updatedWarning, watch})
+
+	//	Assert
+	expected := map[string]string{
+		"urn:3": AlertEventUpdated,
+		"urn:4": AlertEventNew,
+		"urn:2": AlertEventExpired,
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %v events but got %+v instead", len(expected), events)
+	}
+
+	for _, event := range events {
+		if expected[event.Alert.ID] != event.Type {
+			t.Errorf("Expected %v for %v but got %v instead", expected[event.Alert.ID], event.Alert.ID, event.Type)
+		}
+	}
+
+	if len(current) != 2 {
+		t.Errorf("Expected 2 current alerts but got %v instead", len(current))
+	}
+}
+
+func TestDiffAlerts_NothingChanged_NoEvents(t *testing.T) {
+	//	Arrange
+	alert := AlertItem{ID: "urn:1", Severity: "Moderate"}
+	previous := map[string]AlertItem{alertEventKey(alert): alert}
+
+	//	Act
+	events, _ := diffAlerts(previous, []AlertItem{alert})
+
+	//	Assert
+	if len(events) != 0 {
+		t.Errorf("Expected no events but got %+v instead", events)
+	}
+}
+
+func TestWriteAlertStreamEvent_UsesSSEFormat(t *testing.T) {
+	//	Arrange
+	buffer := new(bytes.Buffer)
+
+	//	Act
+	err := writeAlertStreamEvent(buffer, AlertStreamEvent{Type: AlertEventNew, Zone: "GAZ045", Alert: AlertItem{ID: "urn:1"}})
+
+	//	Assert
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+
+	output := buffer.String()
+	if !strings.HasPrefix(output, "event: alert.new\nid: urn:1\ndata: {") || !strings.HasSuffix(output, "}\n\n") {
+		t.Errorf("Expected a server-sent event but got %q instead", output)
+	}
+}
+
+func TestAlertStreamRequest_ParsesEveryFilter(t *testing.T) {
+	//	Arrange
+	query, _ := url.ParseQuery("lat=33.98&long=-83.96&min_severity=severe&min_urgency=Expected&min_certainty=likely&statuses=actual,test")
+
+	//	Act
+	request, err := normalizeAlertFilters(alertStreamRequest(query))
+
+	//	Assert
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	if request.MinSeverity != "severe" || request.MinUrgency != "expected" || request.MinCertainty != "likely" {
+		t.Errorf("Expected the severity, urgency and certainty filters but got %+v instead", request)
+	}
+
+	if len(request.Statuses) != 2 || request.Statuses[1] != "Test" {
+		t.Errorf("Expected the Actual and Test statuses but got %v instead", request.Statuses)
+	}
+}
+
+func TestStreamWeatherAlerts_BadUrgency_IsBadRequest(t *testing.T) {
+	//	Arrange
+	req := httptest.NewRequest("GET", "/v2/alerts/stream?lat=33.98&long=-83.96&min_urgency=soonish", nil)
+	rw := httptest.NewRecorder()
+
+	//	Act
+	Service{}.StreamWeatherAlerts(rw, req)
+
+	//	Assert
+	if rw.Code != http.StatusBadRequest || !strings.Contains(rw.Body.String(), "min_urgency") {
+		t.Errorf("Expected a 400 about min_urgency but got %v instead: %s", rw.Code, rw.Body.String())
+	}
+}
+
+func TestSubscriberEvent_AlertEntersAndLeavesFilter_IsNewThenExpired(t *testing.T) {
+	//	Arrange
+	filters, err := normalizeAlertFilters(AlertsRequest{MinSeverity: "severe"})
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	subscriber := &alertSubscriber{filters: filters, sent: map[string]bool{}}
+
+	vtec := []AlertVTEC{{Office: "KFFC", Phenomena: "SV", Significance: "A", EventTrackingNumber: 7}}
+	advisory := AlertItem{ID: "urn:1", Status: "Actual", Severity: "Moderate", VTEC: vtec}
+	upgraded := AlertItem{ID: "urn:2", Status: "Actual", Severity: "Severe", VTEC: vtec}
+	downgraded := AlertItem{ID: "urn:3", Status: "Actual", Severity: "Minor", VTEC: vtec}
+
+	//	Act
+	_, sentAdvisory := subscriber.subscriberEvent(AlertStreamEvent{Type: AlertEventNew, Alert: advisory})
+	upgradedEvent, sentUpgraded := subscriber.subscriberEvent(AlertStreamEvent{Type: AlertEventUpdated, Alert: upgraded})
+	downgradedEvent, sentDowngraded := subscriber.subscriberEvent(AlertStreamEvent{Type: AlertEventUpdated, Alert: downgraded})
+	_, sentExpired := subscriber.subscriberEvent(AlertStreamEvent{Type: AlertEventExpired, Alert: downgraded})
+
+	//	Assert
+	if sentAdvisory {
+		t.Errorf("Expected the advisory to be filtered out but it was sent")
+	}
+
+	if !sentUpgraded || upgradedEvent.Type != AlertEventNew {
+		t.Errorf("Expected the upgraded alert to be sent as %v but got %v (sent: %v) instead", AlertEventNew, upgradedEvent.Type, sentUpgraded)
+	}
+
+	if !sentDowngraded || downgradedEvent.Type != AlertEventExpired {
+		t.Errorf("Expected the downgraded alert to be sent as %v but got %v (sent: %v) instead", AlertEventExpired, downgradedEvent.Type, sentDowngraded)
+	}
+
+	if sentExpired {
+		t.Errorf("Expected the expired alert not to be sent again but it was")
+	}
+}
+
+func TestSubscriberEvent_SentAlert_IsUpdatedThenExpired(t *testing.T) {
+	//	Arrange
+	filters, err := normalizeAlertFilters(AlertsRequest{})
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	subscriber := &alertSubscriber{filters: filters, sent: map[string]bool{}}
+	warning := AlertItem{ID: "urn:1", Status: "Actual", Severity: "Extreme", VTEC: []AlertVTEC{{Office: "KFFC", Phenomena: "TO", Significance: "W", EventTrackingNumber: 42}}}
+	updated := warning
+	updated.ID = "urn:2"
+
+	//	Act
+	newEvent, _ := subscriber.subscriberEvent(AlertStreamEvent{Type: AlertEventNew, Alert: warning})
+	updatedEvent, _ := subscriber.subscriberEvent(AlertStreamEvent{Type: AlertEventUpdated, Alert: updated})
+	expiredEvent, sentExpired := subscriber.subscriberEvent(AlertStreamEvent{Type: AlertEventExpired, Alert: updated})
+
+	//	Assert
+	if newEvent.Type != AlertEventNew || updatedEvent.Type != AlertEventUpdated || expiredEvent.Type != AlertEventExpired || !sentExpired {
+		t.Errorf("Expected new, updated and expired events but got %v, %v and %v (sent: %v) instead", newEvent.Type, updatedEvent.Type, expiredEvent.Type, sentExpired)
+	}
+}
+
+func TestAlertStreamZones_IncludesForecastZoneAndCounty(t *testing.T) {
+	//	Arrange
+	pointsResponse := NWSPointsResponse{}
+	pointsResponse.Properties.ForecastZone = "https://api.weather.gov/zones/forecast/GAZ045"
+	pointsResponse.Properties.County = "https://api.weather.gov/zones/county/GAC135"
+
+	//	Act
+	zones, err := alertStreamZones(pointsResponse)
+
+	//	Assert
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+
+	if zones != "GAZ045,GAC135" {
+		t.Errorf("Expected GAZ045,GAC135 but got %v instead", zones)
+	}
+}
+
+func TestAlertStreamZones_NoZones_ReturnsError(t *testing.T) {
+	//	Act
+	_, err := alertStreamZones(NWSPointsResponse{})
+
+	//	Assert
+	if err == nil {
+		t.Errorf("Expected an error but didn't get one")
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// AlertVTEC defines the parsed details of a P-VTEC (Primary Valid Time Event Code) string.
+// See https://www.weather.gov/vtec/ for more information
+type AlertVTEC struct {
+	ProductClass        string     `json:"productclass"`        // O (operational), T (test), E (experimental) or X (experimental VTEC)
+	Action              string     `json:"action"`              // Action code (like NEW, CON, EXT, CAN or EXP)
+	ActionDescription   string     `json:"actiondescription"`   // Human readable action (like 'New' or 'Cancelled')
+	Office              string     `json:"office"`              // Issuing office (like KFFC)
+	Phenomena           string     `json:"phenomena"`           // Phenomena code (like TO for tornado or SV for severe thunderstorm)
+	Significance        string     `json:"significance"`        // Significance code (W for warning, A for watch, Y for advisory, S for statement)
+	EventTrackingNumber int        `json:"eventtrackingnumber"` // Event tracking number (unique for the office, phenomena and significance in a year)
+	Begin               *time.Time `json:"begin,omitempty"`     // When the event begins (left out if it's already in progress)
+	End                 *time.Time `json:"end,omitempty"`       // When the event ends (left out if it's not known)
+	Raw                 string     `json:"raw"`                 // The original VTEC string
+}
+
+// vtecPattern matches a P-VTEC string like /O.NEW.KFFC.TO.W.0042.220601T1700Z-220601T1745Z/
+var vtecPattern = regexp.MustCompile(`^/?([OTEX])\.([A-Z]{3})\.([A-Z]{4})\.([A-Z]{2})\.([A-Z])\.(\d{4})\.(\d{6}T\d{4}Z)-(\d{6}T\d{4}Z)/?$`)
+
+// vtecActions are the descriptions of each VTEC action code
+var vtecActions = map[string]string{
+	"NEW": "New",
+	"CON": "Continued",
+	"EXT": "Extended",
+	"EXA": "Extended area",
+	"EXB": "Extended time and area",
+	"UPG": "Upgraded",
+	"CAN": "Cancelled",
+	"EXP": "Expired",
+	"COR": "Correction",
+	"ROU": "Routine",
+}
+
+// parseVTEC parses a P-VTEC string
+func parseVTEC(raw string) (AlertVTEC, error) {
+	retval := AlertVTEC{Raw: raw}
+
+	matches := vtecPattern.FindStringSubmatch(raw)
+	if matches == nil {
+		return retval, fmt.Errorf("'%s' isn't a valid VTEC string", raw)
+	}
+
+	retval.ProductClass = matches[1]
+	retval.Action = matches[2]
+	retval.ActionDescription = vtecActions[matches[2]]
+	retval.Office = matches[3]
+	retval.Phenomena = matches[4]
+	retval.Significance = matches[5]
+	retval.EventTrackingNumber, _ = strconv.Atoi(matches[6])
+	retval.Begin = parseVTECTime(matches[7])
+	retval.End = parseVTECTime(matches[8])
+
+	return retval, nil
+}
+
+// parseVTECTime parses a VTEC time (like 220601T1700Z).  All zeros means the time isn't specified
+func parseVTECTime(value string) *time.Time {
+	if value == "000000T0000Z" {
+		return nil
+	}
+
+	parsed, err := time.Parse("060102T1504Z", value)
+	if err != nil {
+		return nil
+	}
+
+	return &parsed
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseVTEC_ValidString_ReturnsDetails(t *testing.T) {
+	//	Act
+	vtec, err := parseVTEC("/O.NEW.KFFC.TO.W.0042.220601T1700Z-220601T1745Z/")
+
+	//	Assert
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	if vtec.ProductClass != "O" || vtec.Action != "NEW" || vtec.ActionDescription != "New" {
+		t.Errorf("Expected an operational new event but got %+v instead", vtec)
+	}
+
+	if vtec.Office != "KFFC" || vtec.Phenomena != "TO" || vtec.Significance != "W" || vtec.EventTrackingNumber != 42 {
+		t.Errorf("Expected KFFC TO.W 42 but got %v %v.%v %v instead", vtec.Office, vtec.Phenomena, vtec.Significance, vtec.EventTrackingNumber)
+	}
+
+	expectedBegin := time.Date(2022, 6, 1, 17, 0, 0, 0, time.UTC)
+	if vtec.Begin == nil || !vtec.Begin.Equal(expectedBegin) {
+		t.Errorf("Expected begin time %v but got %v instead", expectedBegin, vtec.Begin)
+	}
+
+	expectedEnd := time.Date(2022, 6, 1, 17, 45, 0, 0, time.UTC)
+	if vtec.End == nil || !vtec.End.Equal(expectedEnd) {
+		t.Errorf("Expected end time %v but got %v instead", expectedEnd, vtec.End)
+	}
+}
+
+func TestParseVTEC_EventInProgress_NoBeginTime(t *testing.T) {
+	//	Act
+	vtec, err := parseVTEC("/O.CAN.KFFC.SV.W.0101.000000T0000Z-220601T1800Z/")
+
+	//	Assert
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	if vtec.Begin != nil {
+		t.Errorf("Expected no begin time but got %v instead", vtec.Begin)
+	}
+
+	if vtec.ActionDescription != "Cancelled" {
+		t.Errorf("Expected 'Cancelled' but got '%v' instead", vtec.ActionDescription)
+	}
+}
+
+func TestParseVTEC_InvalidString_ReturnsError(t *testing.T) {
+	//	Arrange
+	tests := []string{
+		"",
+		"/O.NEW.KFFC.TO.W/",
+		"/Q.NEW.KFFC.TO.W.0042.220601T1700Z-220601T1745Z/",
+	}
+
+	for _, test := range tests {
+		//	Act
+		_, err := parseVTEC(test)
+
+		//	Assert
+		if err == nil {
+			t.Errorf("Didn't return an error for '%v' and we expected one", test)
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/httpclient"
+	"github.com/danesparza/daydash-service/internal/telemetry"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// WebhookSignatureHeader is the header with the HMAC-SHA256 signature of the webhook body
+const WebhookSignatureHeader = "X-Daydash-Signature"
+
+// WebhookLocation is a named location watched for alerts
+type WebhookLocation struct {
+	Name      string `json:"name" mapstructure:"name"`
+	Latitude  string `json:"lat" mapstructure:"lat"`
+	Longitude string `json:"long" mapstructure:"long"`
+}
+
+// WebhookTarget is a url that alert notifications are sent to
+type WebhookTarget struct {
+	URL    string `json:"url" mapstructure:"url"`
+	Secret string `json:"-" mapstructure:"secret"` // Used to sign the body (never reported)
+}
+
+// AlertWebhookPayload is the body sent to a webhook when a new alert appears
+type AlertWebhookPayload struct {
+	Event      string               `json:"event"`      // Always alert.new
+	DeliveryID string               `json:"deliveryid"` // Unique ID for the delivery (the same for each retry)
+	SentAt     time.Time            `json:"sentat"`     // When the payload was built (the same for each retry)
+	Location   AlertWebhookLocation `json:"location"`   // The watched location
+	Alert      AlertItem            `json:"alert"`      // The alert
+}
+
+// AlertWebhookLocation describes the watched location in a webhook payload
+type AlertWebhookLocation struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	City      string  `json:"city"`
+	State     string  `json:"state"`
+	NWSCounty string  `json:"county"`
+}
+
+// WebhookDelivery describes an attempt to deliver an alert to a webhook
+type WebhookDelivery struct {
+	ID         string    `json:"id"`
+	Time       time.Time `json:"time"`
+	Location   string    `json:"location"`
+	AlertID    string    `json:"alertid"`
+	Event      string    `json:"event"` // The alert event (like 'Tornado Warning')
+	URL        string    `json:"url"`   // Just the scheme and host (the rest of a webhook url is often a secret)
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"status"` // The last HTTP status code (0 if we never got a response)
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// WebhookDeliveryLog is the list of recent webhook deliveries
+type WebhookDeliveryLog struct {
+	Deliveries []WebhookDelivery `json:"deliveries"` // Most recent first
+}
+
+// webhookDeliveries keeps the most recent webhook deliveries
+var webhookDeliveries = newDeliveryLog(100)
+
+// deliveryLog is a fixed size log of webhook deliveries (the oldest are dropped first)
+type deliveryLog struct {
+	mu      sync.Mutex
+	entries []WebhookDelivery
+	size    int
+}
+
+func newDeliveryLog(size int) *deliveryLog {
+	return &deliveryLog{size: size}
+}
+
+// add adds a delivery to the log
+func (l *deliveryLog) add(delivery WebhookDelivery) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, delivery)
+	if len(l.entries) > l.size {
+		l.entries = l.entries[len(l.entries)-l.size:]
+	}
+}
+
+// recent gets the deliveries in the log (most recent first)
+func (l *deliveryLog) recent() []WebhookDelivery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	retval := []WebhookDelivery{}
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		retval = append(retval, l.entries[i])
+	}
+	return retval
+}
+
+// AlertWebhookTask watches the configured locations for new alerts (at or above the configured
+// severity) and sends them to the configured webhooks
+func AlertWebhookTask(ctx context.Context) {
+
+	locations := []WebhookLocation{}
+	targets := []WebhookTarget{}
+	viper.UnmarshalKey("alerts.webhooks.locations", &locations)
+	viper.UnmarshalKey("alerts.webhooks.targets", &targets)
+
+	if len(locations) == 0 || len(targets) == 0 {
+		zlog.Infow("AlertWebhookTask not starting -- no webhook locations or targets are configured")
+		return
+	}
+
+	//	Only notify for alerts that are important enough
+	filters, err := normalizeAlertFilters(AlertsRequest{MinSeverity: viper.GetString("alerts.webhooks.minseverity")})
+	if err != nil {
+		zlog.Errorw(
+			"AlertWebhookTask not starting -- the webhook settings aren't valid",
+			"error", err,
+		)
+		return
+	}
+
+	interval := viper.GetDuration("alerts.webhooks.interval")
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	zlog.Infow(
+		"AlertWebhookTask starting... ",
+		"locations", len(locations),
+		"targets", len(targets),
+		"interval", interval,
+	)
+
+	//	The alerts we've already sent to each target for each location (by location index, since names
+	//	don't have to be unique)
+	notified := make([]map[string]bool, len(locations))
+
+	for {
+		//	Start a background transaction
+		txn := telemetry.NRApp.StartTransaction("AlertWebhookTask")
+		cx := newrelic.NewContext(ctx, txn)
+
+		for i, location := range locations {
+			notified[i] = checkWebhookLocation(cx, location, filters, targets, notified[i])
+		}
+
+		txn.End()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// checkWebhookLocation gets the alerts for a location and sends any we haven't sent to each target before.
+// It returns the alerts that have been sent to each target for the location (keyed by webhookSentKey).
+// An alert only counts as sent once it's been delivered, so failed deliveries are tried again next time.
+// Anything that's no longer active is forgotten
+func checkWebhookLocation(ctx context.Context, location WebhookLocation, filters AlertsRequest, targets []WebhookTarget, sent map[string]bool) map[string]bool {
+
+	//	Use the same (cached) report the alerts endpoint uses
+	cacheKey := fmt.Sprintf("alerts:%s", coordinateKey(location.Latitude, location.Longitude))
+	cached, _, err := responseCache.GetOrLoad(cacheKey, cacheTTL("alerts"), func() (interface{}, error) {
+		return getAlertReport(ctx, AlertsRequest{Latitude: location.Latitude, Longitude: location.Longitude})
+	})
+	if err != nil {
+		zlog.Errorw(
+			"problem getting alerts for webhook location",
+			"location", location.Name,
+			"error", err,
+		)
+		return sent
+	}
+	report := cached.(AlertReport)
+
+	active := map[string]bool{}
+	for _, alert := range filterAlerts(report.Alerts, filters) {
+
+		//	Find the targets that still need the alert
+		pending := []int{}
+		for i := range targets {
+			key := webhookSentKey(i, alert)
+			if sent[key] {
+				active[key] = true
+				continue
+			}
+			pending = append(pending, i)
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		payload := AlertWebhookPayload{
+			Event:      AlertEventNew,
+			DeliveryID: newDeliveryID(),
+			SentAt:     time.Now().UTC(),
+			Location: AlertWebhookLocation{
+				Name:      location.Name,
+				Latitude:  report.Latitude,
+				Longitude: report.Longitude,
+				City:      report.City,
+				State:     report.State,
+				NWSCounty: report.NWSCounty,
+			},
+			Alert: alert,
+		}
+
+		for _, i := range pending {
+			delivery := deliverWebhook(ctx, targets[i], payload)
+			webhookDeliveries.add(delivery)
+			if delivery.Success {
+				active[webhookSentKey(i, alert)] = true
+			}
+		}
+	}
+
+	return active
+}
+
+// webhookSentKey gets the key used to remember that an alert was sent to the target (by target index).
+// It uses alertEventKey, so updates to an alert aren't sent again
+func webhookSentKey(target int, alert AlertItem) string {
+	return fmt.Sprintf("%d|%s", target, alertEventKey(alert))
+}
+
+// redactWebhookURL gets just the scheme and host of a webhook url.  The path and query of a webhook
+// url often include a token, so they're never reported or logged
+func redactWebhookURL(webhookUrl string) string {
+	parsed, err := url.Parse(webhookUrl)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
+}
+
+// deliverWebhook sends the payload to the webhook target, retrying (with backoff) if the target is
+// unavailable.  The returned delivery describes what happened
+func deliverWebhook(ctx context.Context, target WebhookTarget, payload AlertWebhookPayload) WebhookDelivery {
+
+	delivery := WebhookDelivery{
+		ID:       payload.DeliveryID,
+		Time:     time.Now().UTC(),
+		Location: payload.Location.Name,
+		AlertID:  payload.Alert.ID,
+		Event:    payload.Alert.Event,
+		URL:      redactWebhookURL(target.URL),
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		delivery.Error = fmt.Sprintf("problem encoding the payload: %v", err)
+		return delivery
+	}
+
+	retries := viper.GetInt("alerts.webhooks.retries")
+	delay := viper.GetDuration("alerts.webhooks.retrydelay")
+
+	for delivery.Attempts = 1; ; delivery.Attempts++ {
+		retry := false
+		delivery.StatusCode, retry, err = postWebhook(ctx, target, payload.DeliveryID, body)
+		if err == nil {
+			delivery.Success = true
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = err.Error()
+
+		if !retry || delivery.Attempts > retries {
+			break
+		}
+
+		//	Wait a little longer each time
+		select {
+		case <-ctx.Done():
+			delivery.Error = ctx.Err().Error()
+			return delivery
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	if delivery.Success {
+		zlog.Infow(
+			"delivered alert webhook",
+			"location", delivery.Location,
+			"alert", delivery.AlertID,
+			"url", delivery.URL,
+			"attempts", delivery.Attempts,
+		)
+	} else {
+		zlog.Errorw(
+			"problem delivering alert webhook",
+			"location", delivery.Location,
+			"alert", delivery.AlertID,
+			"url", delivery.URL,
+			"attempts", delivery.Attempts,
+			"error", delivery.Error,
+		)
+	}
+
+	return delivery
+}
+
+// postWebhook makes a single attempt to post the body to the webhook target.  It returns the HTTP
+// status code and whether it's worth trying again if it failed
+func postWebhook(ctx context.Context, target WebhookTarget, deliveryID string, body []byte) (int, bool, error) {
+
+	req, err := http.NewRequest(http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("problem creating the webhook request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Daydash-Event", AlertEventNew)
+	req.Header.Set("X-Daydash-Delivery", deliveryID)
+	if target.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, signWebhookBody(target.Secret, body))
+	}
+	req = newrelic.RequestWithTransactionContext(req, newrelic.FromContext(ctx))
+
+	resp, err := ctxhttp.Do(ctx, httpclient.Default(), req)
+	if err != nil {
+		return 0, true, fmt.Errorf("problem sending the webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 300 {
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return resp.StatusCode, retry, fmt.Errorf("the webhook returned %s", resp.Status)
+	}
+
+	return resp.StatusCode, false, nil
+}
+
+// signWebhookBody gets the signature for a webhook body (sha256= followed by the hex encoded HMAC-SHA256)
+func signWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newDeliveryID gets a random ID for a webhook delivery
+func newDeliveryID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// GetWebhookDeliveries godoc
+// @Summary Gets the recent alert webhook deliveries
+// @Description Gets the most recent alert webhook deliveries (newest first), including failed deliveries.  Requires the admin token (server.admintoken) as a bearer token
+// @Tags admin
+// @Produce  json
+// @Success 200 {object} api.WebhookDeliveryLog
+// @Failure 401 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Router /alerts/webhooks/deliveries [get]
+func (s Service) GetWebhookDeliveries(rw http.ResponseWriter, req *http.Request) {
+
+	if err := checkAdminToken(req); err != nil {
+		sendErrorResponse(rw, err)
+		return
+	}
+
+	retval := WebhookDeliveryLog{Deliveries: webhookDeliveries.recent()}
+
+	//	Serialize to JSON & return the response:
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(rw).Encode(retval)
+}
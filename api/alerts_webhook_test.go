@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestSignWebhookBody_MatchesHMAC(t *testing.T) {
+	//	Arrange
+	body := []byte(`{"event":"alert.new"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	//	Act
+	signature := signWebhookBody("secret", body)
+
+	//	Assert
+	if signature != expected {
+		t.Errorf("Expected %v but got %v instead", expected, signature)
+	}
+
+	if signWebhookBody("other", body) == signature {
+		t.Errorf("Expected a different secret to give a different signature")
+	}
+}
+
+func TestDeliverWebhook_RetriesUntilSuccess(t *testing.T) {
+	//	Arrange
+	viper.Set("alerts.webhooks.retries", 3)
+	viper.Set("alerts.webhooks.retrydelay", time.Millisecond)
+	defer viper.Set("alerts.webhooks.retries", nil)
+	defer viper.Set("alerts.webhooks.retrydelay", nil)
+
+	attempts := 0
+	signatures := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(req.Body)
+		if req.Header.Get(WebhookSignatureHeader) == signWebhookBody("secret", body) {
+			signatures = append(signatures, req.Header.Get(WebhookSignatureHeader))
+		}
+
+		if attempts < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	payload := AlertWebhookPayload{
+		Event:      AlertEventNew,
+		DeliveryID: newDeliveryID(),
+		Location:   AlertWebhookLocation{Name: "home"},
+		Alert:      AlertItem{ID: "urn:1", Event: "Tornado Warning"},
+	}
+
+	//	Act
+	delivery := deliverWebhook(context.Background(), WebhookTarget{URL: server.URL, Secret: "secret"}, payload)
+
+	//	Assert
+	if !delivery.Success {
+		t.Errorf("Expected the delivery to succeed but got %v instead", delivery.Error)
+	}
+
+	if delivery.Attempts != 3 || delivery.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected 3 attempts ending with a 204 but got %v attempts and a %v instead", delivery.Attempts, delivery.StatusCode)
+	}
+
+	if len(signatures) != 3 {
+		t.Errorf("Expected every attempt to be signed but only %v were", len(signatures))
+	}
+}
+
+func TestDeliverWebhook_ClientError_DoesNotRetry(t *testing.T) {
+	//	Arrange
+	viper.Set("alerts.webhooks.retries", 3)
+	viper.Set("alerts.webhooks.retrydelay", time.Millisecond)
+	defer viper.Set("alerts.webhooks.retries", nil)
+	defer viper.Set("alerts.webhooks.retrydelay", nil)
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		attempts++
+		rw.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	//	Act
+	delivery := deliverWebhook(context.Background(), WebhookTarget{URL: server.URL}, AlertWebhookPayload{Alert: AlertItem{ID: "urn:1"}})
+
+	//	Assert
+	if delivery.Success || delivery.Error == "" {
+		t.Errorf("Expected the delivery to fail with an error but got %+v instead", delivery)
+	}
+
+	if attempts != 1 {
+		t.Errorf("Expected a single attempt but got %v instead", attempts)
+	}
+}
+
+func TestDeliveryLog_KeepsMostRecent(t *testing.T) {
+	//	Arrange
+	log := newDeliveryLog(2)
+
+	//	Act
+	log.add(WebhookDelivery{ID: "1"})
+	log.add(WebhookDelivery{ID: "2"})
+	log.add(WebhookDelivery{ID: "3"})
+	deliveries := log.recent()
+
+	//	Assert
+	if len(deliveries) != 2 {
+		t.Fatalf("Expected 2 deliveries but got %v instead", len(deliveries))
+	}
+
+	if deliveries[0].ID != "3" || deliveries[1].ID != "2" {
+		t.Errorf("Expected the newest deliveries first but got %v and %v instead", deliveries[0].ID, deliveries[1].ID)
+	}
+}
+
+func TestCheckWebhookLocation_FailedTarget_IsTriedAgain(t *testing.T) {
+	//	Arrange
+	viper.Set("alerts.webhooks.retries", 0)
+	defer viper.Set("alerts.webhooks.retries", nil)
+
+	working, broken := 0, 0
+	workingServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		working++
+	}))
+	defer workingServer.Close()
+	brokenServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		broken++
+		rw.WriteHeader(http.StatusBadRequest)
+	}))
+	defer brokenServer.Close()
+
+	location := WebhookLocation{Name: "home", Latitude: "10.01", Longitude: "-20.02"}
+	cacheKey := fmt.Sprintf("alerts:%s", coordinateKey(location.Latitude, location.Longitude))
+	responseCache.Set(cacheKey, AlertReport{Alerts: []AlertItem{{ID: "urn:1", Status: "Actual"}}}, 0)
+	defer responseCache.Delete(cacheKey)
+
+	filters, _ := normalizeAlertFilters(AlertsRequest{})
+	targets := []WebhookTarget{{URL: workingServer.URL + "/hook/token"}, {URL: brokenServer.URL}}
+
+	//	Act
+	sent := checkWebhookLocation(context.Background(), location, filters, targets, nil)
+	sent = checkWebhookLocation(context.Background(), location, filters, targets, sent)
+
+	//	Assert
+	if working != 1 || broken != 2 {
+		t.Errorf("Expected 1 delivery to the working target and 2 to the broken one but got %v and %v instead", working, broken)
+	}
+
+	if len(sent) != 1 || !sent[webhookSentKey(0, AlertItem{ID: "urn:1"})] {
+		t.Errorf("Expected the alert to be sent to the working target only but got %v instead", sent)
+	}
+
+	for _, delivery := range webhookDeliveries.recent()[:3] {
+		if strings.Contains(delivery.URL, "token") || !strings.HasPrefix(delivery.URL, "http://127.0.0.1") {
+			t.Errorf("Expected just the scheme and host of the webhook but got '%v' instead", delivery.URL)
+		}
+	}
+}
+
+func TestRedactWebhookURL_KeepsSchemeAndHost(t *testing.T) {
+	//	Arrange
+	tests := []struct {
+		webhookUrl string
+		expected   string
+	}{
+		{"https://hooks.slack.com/services/T000/B000/XXXX", "https://hooks.slack.com"},
+		{"http://localhost:8080/hook?token=secret", "http://localhost:8080"},
+		{"not a url", ""},
+	}
+
+	for _, test := range tests {
+		//	Act
+		redacted := redactWebhookURL(test.webhookUrl)
+
+		//	Assert
+		if redacted != test.expected {
+			t.Errorf("Expected '%v' for %v but got '%v' instead", test.expected, test.webhookUrl, redacted)
+		}
+	}
+}
+
+func TestGetWebhookDeliveries_RequiresAdminToken(t *testing.T) {
+	//	Arrange
+	viper.Set("server.admintoken", "secret")
+	defer viper.Set("server.admintoken", nil)
+
+	anonymous := httptest.NewRecorder()
+	authorized := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/v2/alerts/webhooks/deliveries", nil)
+
+	//	Act
+	Service{}.GetWebhookDeliveries(anonymous, req)
+	req.Header.Set("Authorization", "Bearer secret")
+	Service{}.GetWebhookDeliveries(authorized, req)
+
+	//	Assert
+	if anonymous.Code != http.StatusUnauthorized || authorized.Code != http.StatusOK {
+		t.Errorf("Expected a 401 without the token and a 200 with it but got %v and %v instead", anonymous.Code, authorized.Code)
+	}
+}
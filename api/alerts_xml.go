@@ -0,0 +1,317 @@
+package api
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CAP 1.2 and Atom namespaces
+const (
+	capNamespace  = "urn:oasis:names:tc:emergency:cap:1.2"
+	atomNamespace = "http://www.w3.org/2005/Atom"
+)
+
+// CAPAlert is a CAP 1.2 alert message.  See http://docs.oasis-open.org/emergency/cap/v1.2/CAP-v1.2.html
+// A CAP document only describes a single alert (the elements are in the order the schema requires)
+type CAPAlert struct {
+	XMLName    xml.Name `xml:"urn:oasis:names:tc:emergency:cap:1.2 alert"`
+	Identifier string   `xml:"identifier"`
+	Sender     string   `xml:"sender"`
+	Sent       string   `xml:"sent"`
+	Status     string   `xml:"status"`
+	MsgType    string   `xml:"msgType"`
+	Scope      string   `xml:"scope"`
+	Info       CAPInfo  `xml:"info"`
+}
+
+// CAPInfo describes the event in a CAP alert
+type CAPInfo struct {
+	Language    string         `xml:"language"`
+	Category    string         `xml:"category"`
+	Event       string         `xml:"event"`
+	Urgency     string         `xml:"urgency"`
+	Severity    string         `xml:"severity"`
+	Certainty   string         `xml:"certainty"`
+	Effective   string         `xml:"effective,omitempty"`
+	Onset       string         `xml:"onset,omitempty"`
+	Expires     string         `xml:"expires,omitempty"`
+	SenderName  string         `xml:"senderName,omitempty"`
+	Headline    string         `xml:"headline,omitempty"`
+	Description string         `xml:"description,omitempty"`
+	Parameters  []CAPParameter `xml:"parameter"`
+	Area        CAPArea        `xml:"area"`
+}
+
+// CAPParameter is a system-specific value in a CAP alert (like the VTEC string)
+type CAPParameter struct {
+	ValueName string `xml:"valueName"`
+	Value     string `xml:"value"`
+}
+
+// CAPArea describes the area a CAP alert covers
+type CAPArea struct {
+	AreaDesc string   `xml:"areaDesc"`
+	Polygons []string `xml:"polygon"`
+}
+
+// AtomFeed is an Atom feed of alerts.  Each entry includes the CAP details (in the CAP namespace)
+// the way the NWS alert feeds do, and the full CAP alert as its content
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	CAPNS   string      `xml:"xmlns:cap,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  AtomAuthor  `xml:"author"`
+	Links   []AtomLink  `xml:"link"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+// AtomAuthor is the author of an Atom feed or entry
+type AtomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// AtomLink is a link in an Atom feed or entry
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+// AtomEntry is a single alert in an Atom feed
+type AtomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published"`
+	Author    AtomAuthor  `xml:"author"`
+	Summary   string      `xml:"summary"`
+	Event     string      `xml:"cap:event"`
+	Effective string      `xml:"cap:effective,omitempty"`
+	Onset     string      `xml:"cap:onset,omitempty"`
+	Expires   string      `xml:"cap:expires,omitempty"`
+	Status    string      `xml:"cap:status"`
+	MsgType   string      `xml:"cap:msgType"`
+	Urgency   string      `xml:"cap:urgency"`
+	Severity  string      `xml:"cap:severity"`
+	Certainty string      `xml:"cap:certainty"`
+	AreaDesc  string      `xml:"cap:areaDesc"`
+	Polygons  []string    `xml:"cap:polygon"`
+	Content   AtomContent `xml:"content"`
+}
+
+// AtomContent is the content of an Atom entry -- the full CAP alert
+type AtomContent struct {
+	Type  string   `xml:"type,attr"`
+	Alert CAPAlert `xml:"alert"`
+}
+
+// newCAPAlert renders an alert as a CAP 1.2 alert
+func newCAPAlert(alert AlertItem) CAPAlert {
+	parameters := []CAPParameter{}
+	if alert.NWSHeadline != "" {
+		parameters = append(parameters, CAPParameter{ValueName: "NWSheadline", Value: alert.NWSHeadline})
+	}
+	for _, vtec := range alert.VTEC {
+		parameters = append(parameters, CAPParameter{ValueName: "VTEC", Value: vtec.Raw})
+	}
+	if !alert.EventEndingTime.IsZero() {
+		parameters = append(parameters, CAPParameter{ValueName: "eventEndingTime", Value: capTime(alert.EventEndingTime)})
+	}
+
+	return CAPAlert{
+		Identifier: alert.ID,
+		Sender:     alert.Sender,
+		Sent:       capTime(alertSentOrNow(alert)),
+		Status:     alert.Status,
+		MsgType:    alert.MessageType,
+		Scope:      "Public",
+		Info: CAPInfo{
+			Language:    "en-US",
+			Category:    "Met",
+			Event:       alert.Event,
+			Urgency:     alert.Urgency,
+			Severity:    alert.Severity,
+			Certainty:   alert.Certainty,
+			Effective:   capTime(alert.Start),
+			Onset:       capTime(alert.Onset),
+			Expires:     capTime(alertExpires(alert)),
+			SenderName:  alert.SenderName,
+			Headline:    alert.Headline,
+			Description: alert.Description,
+			Parameters:  parameters,
+			Area: CAPArea{
+				AreaDesc: alert.AreaDescription,
+				Polygons: capPolygons(alert.Geometry),
+			},
+		},
+	}
+}
+
+// newestCAPAlert renders the most recently sent alert in a report as a CAP 1.2 alert.  The bool
+// is false if there aren't any alerts (the Atom feed has all of them)
+func newestCAPAlert(report AlertReport) (CAPAlert, bool) {
+	if len(report.Alerts) == 0 {
+		return CAPAlert{}, false
+	}
+
+	newest := report.Alerts[0]
+	for _, alert := range report.Alerts[1:] {
+		if alertSent(alert).After(alertSent(newest)) {
+			newest = alert
+		}
+	}
+
+	return newCAPAlert(newest), true
+}
+
+// newAlertsAtomFeed renders the alerts in a report as an Atom feed
+func newAlertsAtomFeed(report AlertReport) AtomFeed {
+	retval := AtomFeed{
+		CAPNS:   capNamespace,
+		ID:      fmt.Sprintf("urn:daydash:alerts:%.4f,%.4f", report.Latitude, report.Longitude),
+		Title:   fmt.Sprintf("Weather alerts for %s, %s", report.City, report.State),
+		Author:  AtomAuthor{Name: "daydash-service"},
+		Entries: []AtomEntry{},
+	}
+
+	if report.ActiveAlertsForCountyURL != "" {
+		retval.Links = append(retval.Links, AtomLink{Href: report.ActiveAlertsForCountyURL, Rel: "alternate"})
+	}
+
+	//	The feed was last updated when the newest alert was sent
+	updated := time.Time{}
+	for _, alert := range report.Alerts {
+		if alertSent(alert).After(updated) {
+			updated = alertSent(alert)
+		}
+
+		title := alert.Headline
+		if title == "" {
+			title = alert.Event
+		}
+
+		retval.Entries = append(retval.Entries, AtomEntry{
+			ID:        alert.ID,
+			Title:     title,
+			Updated:   atomTime(alertSentOrNow(alert)),
+			Published: atomTime(alertSentOrNow(alert)),
+			Author:    AtomAuthor{Name: alert.SenderName},
+			Summary:   alert.Description,
+			Event:     alert.Event,
+			Effective: capTime(alert.Start),
+			Onset:     capTime(alert.Onset),
+			Expires:   capTime(alertExpires(alert)),
+			Status:    alert.Status,
+			MsgType:   alert.MessageType,
+			Urgency:   alert.Urgency,
+			Severity:  alert.Severity,
+			Certainty: alert.Certainty,
+			AreaDesc:  alert.AreaDescription,
+			Polygons:  capPolygons(alert.Geometry),
+			Content:   AtomContent{Type: MediaTypeCAP, Alert: newCAPAlert(alert)},
+		})
+	}
+
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	retval.Updated = atomTime(updated)
+
+	return retval
+}
+
+// alertSent gets when an alert message was sent (providers that don't say are treated as sending
+// it when it took effect)
+func alertSent(alert AlertItem) time.Time {
+	if !alert.Sent.IsZero() {
+		return alert.Sent
+	}
+	return alert.Start
+}
+
+// alertSentOrNow gets when an alert message was sent.  CAP and Atom both require the time, so if
+// the provider didn't say when it was sent (or took effect) it's treated as sent when we got it
+func alertSentOrNow(alert AlertItem) time.Time {
+	if sent := alertSent(alert); !sent.IsZero() {
+		return sent
+	}
+	return time.Now()
+}
+
+// alertExpires gets when an alert expires (when the event ends, if we know that)
+func alertExpires(alert AlertItem) time.Time {
+	if !alert.End.IsZero() {
+		return alert.End
+	}
+	return alert.EventEndingTime
+}
+
+// capTime formats a time the way CAP requires (no 'Z' -- UTC is +00:00).  Zero times are left empty
+func capTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02T15:04:05-07:00")
+}
+
+// atomTime formats a time for an Atom feed
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// capPolygons converts a GeoJSON Polygon or MultiPolygon to CAP polygons ('lat,lon' pairs separated
+// by spaces).  Only the outer ring of each polygon is used.  Anything else gives no polygons
+func capPolygons(geometry json.RawMessage) []string {
+	retval := []string{}
+	if len(geometry) == 0 {
+		return retval
+	}
+
+	parsed := struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}{}
+	if err := json.Unmarshal(geometry, &parsed); err != nil {
+		return retval
+	}
+
+	polygons := [][][][]float64{}
+	switch parsed.Type {
+	case "Polygon":
+		polygon := [][][]float64{}
+		if err := json.Unmarshal(parsed.Coordinates, &polygon); err != nil {
+			return retval
+		}
+		polygons = append(polygons, polygon)
+	case "MultiPolygon":
+		if err := json.Unmarshal(parsed.Coordinates, &polygons); err != nil {
+			return retval
+		}
+	}
+
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			continue
+		}
+
+		points := []string{}
+		for _, point := range polygon[0] {
+			if len(point) < 2 {
+				continue
+			}
+
+			//	GeoJSON is longitude first -- CAP is latitude first
+			points = append(points, fmt.Sprintf("%v,%v", point[1], point[0]))
+		}
+
+		if len(points) > 0 {
+			retval = append(retval, strings.Join(points, " "))
+		}
+	}
+
+	return retval
+}
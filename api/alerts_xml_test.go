@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testAlertReport() AlertReport {
+	return AlertReport{
+		Latitude:  33.87,
+		Longitude: -84.0,
+		City:      "Lilburn",
+		State:     "GA",
+		Alerts: []AlertItem{
+			{
+				ID:              "urn:oid:2.49.0.1.840.0.1",
+				Event:           "Tornado Warning",
+				Headline:        "Tornado Warning issued June 1 at 1:00PM EDT",
+				Severity:        "Extreme",
+				Urgency:         "Immediate",
+				Certainty:       "Observed",
+				Status:          "Actual",
+				MessageType:     "Alert",
+				AreaDescription: "Gwinnett, GA",
+				Sent:            time.Date(2022, 6, 1, 16, 58, 0, 0, time.UTC),
+				Start:           time.Date(2022, 6, 1, 17, 0, 0, 0, time.UTC),
+				VTEC:            []AlertVTEC{{Raw: "/O.NEW.KFFC.TO.W.0042.220601T1700Z-220601T1745Z/"}},
+				Geometry:        json.RawMessage(`{"type":"Polygon","coordinates":[[[-84.1,33.8],[-84.0,33.9],[-84.1,33.8]]]}`),
+			},
+		},
+	}
+}
+
+func TestNewestCAPAlert_RendersCAP12(t *testing.T) {
+	//	Arrange
+	report := testAlertReport()
+	older := report.Alerts[0]
+	older.ID = "urn:oid:2.49.0.1.840.0.0"
+	older.Sent = older.Sent.Add(-time.Hour)
+	report.Alerts = append(report.Alerts, older)
+
+	//	Act
+	alert, found := newestCAPAlert(report)
+	data, err := xml.Marshal(alert)
+
+	//	Assert
+	if err != nil || !found {
+		t.Fatalf("Returned error and we didn't expect that: %v (found: %v)", err, found)
+	}
+
+	expected := []string{
+		`<alert xmlns="urn:oasis:names:tc:emergency:cap:1.2"><identifier>urn:oid:2.49.0.1.840.0.1</identifier>`,
+		`<sent>2022-06-01T16:58:00+00:00</sent>`,
+		`<effective>2022-06-01T17:00:00+00:00</effective>`,
+		`<msgType>Alert</msgType>`,
+		`<severity>Extreme</severity>`,
+		`<valueName>VTEC</valueName><value>/O.NEW.KFFC.TO.W.0042.220601T1700Z-220601T1745Z/</value>`,
+		`<polygon>33.8,-84.1 33.9,-84 33.8,-84.1</polygon>`,
+	}
+
+	for _, fragment := range expected {
+		if !strings.Contains(string(data), fragment) {
+			t.Errorf("Expected the CAP document to contain %v but got %s instead", fragment, data)
+		}
+	}
+
+	if strings.Count(string(data), "<alert ") != 1 {
+		t.Errorf("Expected a single CAP alert but got %s instead", data)
+	}
+}
+
+func TestNewestCAPAlert_NoAlerts_NotFound(t *testing.T) {
+	//	Act
+	_, found := newestCAPAlert(AlertReport{Alerts: []AlertItem{}})
+
+	//	Assert
+	if found {
+		t.Errorf("Expected no CAP alert when there are no alerts")
+	}
+}
+
+func TestNewAlertsAtomFeed_RendersEntries(t *testing.T) {
+	//	Arrange
+	report := testAlertReport()
+
+	//	Act
+	data, err := xml.Marshal(newAlertsAtomFeed(report))
+
+	//	Assert
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	expected := []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom" xmlns:cap="urn:oasis:names:tc:emergency:cap:1.2">`,
+		`<title>Weather alerts for Lilburn, GA</title>`,
+		`<updated>2022-06-01T16:58:00Z</updated>`,
+		`<title>Tornado Warning issued June 1 at 1:00PM EDT</title>`,
+		`<cap:event>Tornado Warning</cap:event>`,
+		`<content type="application/cap+xml"><alert xmlns="urn:oasis:names:tc:emergency:cap:1.2"><identifier>urn:oid:2.49.0.1.840.0.1</identifier>`,
+	}
+
+	for _, fragment := range expected {
+		if !strings.Contains(string(data), fragment) {
+			t.Errorf("Expected the Atom feed to contain %v but got %s instead", fragment, data)
+		}
+	}
+}
+
+func TestCAPPolygons_UnsupportedGeometry_ReturnsNone(t *testing.T) {
+	//	Act
+	polygons := capPolygons(json.RawMessage(`{"type":"Point","coordinates":[-84.1,33.8]}`))
+
+	//	Assert
+	if len(polygons) != 0 {
+		t.Errorf("Expected no polygons but got %v instead", polygons)
+	}
+}
+
+func TestBuildAlertItems_KeepsSentTime(t *testing.T) {
+	//	Arrange
+	response := NWSAlertsResponse{}
+	err := json.Unmarshal([]byte(`{"features":[{"properties":{"identifier":"urn:1","sent":"2022-06-01T12:58:00-04:00","effective":"2022-06-01T13:00:00-04:00"}}]}`), &response)
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	//	Act
+	alerts := buildAlertItems(response)
+
+	//	Assert
+	if len(alerts) != 1 || !alerts[0].Sent.Equal(time.Date(2022, 6, 1, 16, 58, 0, 0, time.UTC)) {
+		t.Errorf("Expected the alert to be sent at 16:58 UTC but got %+v instead", alerts)
+	}
+}
+
+func TestNewCAPAlert_NoSentOrEffectiveTime_UsesNow(t *testing.T) {
+	//	Arrange
+	alert := AlertItem{ID: "urn:1", Event: "Special Weather Statement"}
+	before := time.Now().Add(-time.Second)
+
+	//	Act
+	capAlert := newCAPAlert(alert)
+
+	//	Assert
+	sent, err := time.Parse("2006-01-02T15:04:05-07:00", capAlert.Sent)
+	if err != nil {
+		t.Fatalf("Expected a sent time but got %q instead: %v", capAlert.Sent, err)
+	}
+
+	if sent.Before(before) || sent.After(time.Now()) {
+		t.Errorf("Expected the sent time to be now but got %v instead", sent)
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/astronomy"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+type AstronomyRequest struct {
+	Latitude  string `json:"lat"`
+	Longitude string `json:"long"`
+	Date      string `json:"date"`     // The date to get data for (YYYY-MM-DD).  Defaults to today
+	Timezone  string `json:"timezone"` // The timezone to use (like America/New_York).  Defaults to UTC
+}
+
+// AstronomyReport defines an astronomy report
+type AstronomyReport struct {
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	Date      string     `json:"date"`     // The date of the report (YYYY-MM-DD)
+	TimeZone  string     `json:"timezone"` // The timezone used
+	Sun       SunReport  `json:"sun"`
+	Moon      MoonReport `json:"moon"`
+}
+
+// SunReport defines the sun events for a day.  Events that don't happen on the day
+// (like sunrise during a polar night) are left out
+type SunReport struct {
+	Sunrise          *time.Time `json:"sunrise,omitempty"`
+	Sunset           *time.Time `json:"sunset,omitempty"`
+	SolarNoon        *time.Time `json:"solarNoon,omitempty"`
+	CivilDawn        *time.Time `json:"civilDawn,omitempty"`
+	CivilDusk        *time.Time `json:"civilDusk,omitempty"`
+	NauticalDawn     *time.Time `json:"nauticalDawn,omitempty"`
+	NauticalDusk     *time.Time `json:"nauticalDusk,omitempty"`
+	DaylightDuration int64      `json:"daylightDuration"` // Seconds between sunrise and sunset
+	AlwaysUp         bool       `json:"alwaysUp"`         // The sun never sets on this day
+	AlwaysDown       bool       `json:"alwaysDown"`       // The sun never rises on this day
+}
+
+// MoonReport defines the moon details for a day
+type MoonReport struct {
+	Phase        float64    `json:"phase"`        // 0 and 1 are 'new moon', 0.25 is 'first quarter', 0.5 is 'full moon', 0.75 is 'last quarter'
+	PhaseName    string     `json:"phaseName"`    // Human readable moon phase (like 'Waxing Gibbous')
+	Illumination float64    `json:"illumination"` // Fraction of the moon's disk that is lit (0 - 1)
+	Moonrise     *time.Time `json:"moonrise,omitempty"`
+	Moonset      *time.Time `json:"moonset,omitempty"`
+}
+
+// GetAstronomyReport godoc
+// @Summary Gets sun and moon data for the given location and date
+// @Description Gets sunrise, sunset, twilight, solar noon, moon phase and moonrise/moonset for the given location and date.  Calculated locally (no outside services are used)
+// @Tags dashboard
+// @Accept  json
+// @Produce  json
+// @Param config body api.AstronomyRequest true "The location and date to get data for"
+// @Success 200 {object} api.AstronomyReport
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /astronomy [post]
+func (s Service) GetAstronomyReport(rw http.ResponseWriter, req *http.Request) {
+
+	txn := newrelic.FromContext(req.Context())
+	defer txn.StartSegment("Astronomy GetAstronomyReport").End()
+
+	//	Parse the request
+	request := AstronomyRequest{}
+	err := json.NewDecoder(req.Body).Decode(&request)
+	if err != nil {
+		zlog.Errorw(
+			"Problem decoding request",
+			"error", err,
+		)
+		txn.NoticeError(err)
+		sendErrorResponse(rw, newBadRequestError(err))
+		return
+	}
+
+	//	Make sure we have minimum args:
+	lat, latErr := strconv.ParseFloat(request.Latitude, 64)
+	long, longErr := strconv.ParseFloat(request.Longitude, 64)
+	if latErr != nil || longErr != nil || lat < -90 || lat > 90 || long < -180 || long > 180 {
+		sendErrorResponse(rw, newBadRequestError(fmt.Errorf("you must include a valid lat and long param")))
+		return
+	}
+
+	//	Get the timezone (UTC if one wasn't passed)
+	timezone := request.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		sendErrorResponse(rw, newBadRequestError(fmt.Errorf("you must include a valid timezone param: %v", err)))
+		return
+	}
+
+	//	Get the date (today if one wasn't passed)
+	date := time.Now().In(location)
+	if request.Date != "" {
+		date, err = time.ParseInLocation("2006-01-02", request.Date, location)
+		if err != nil {
+			sendErrorResponse(rw, newBadRequestError(fmt.Errorf("the date param must be in the format YYYY-MM-DD")))
+			return
+		}
+	}
+
+	//	Calculate what we need
+	sun := astronomy.GetSunTimes(date, lat, long)
+	moon := astronomy.GetMoonInfo(date, lat, long)
+
+	retval := AstronomyReport{
+		Latitude:  lat,
+		Longitude: long,
+		Date:      date.Format("2006-01-02"),
+		TimeZone:  timezone,
+		Sun: SunReport{
+			Sunrise:          optionalTime(sun.Sunrise),
+			Sunset:           optionalTime(sun.Sunset),
+			SolarNoon:        optionalTime(sun.SolarNoon),
+			CivilDawn:        optionalTime(sun.CivilDawn),
+			CivilDusk:        optionalTime(sun.CivilDusk),
+			NauticalDawn:     optionalTime(sun.NauticalDawn),
+			NauticalDusk:     optionalTime(sun.NauticalDusk),
+			DaylightDuration: int64(sun.DaylightDuration.Seconds()),
+			AlwaysUp:         sun.AlwaysUp,
+			AlwaysDown:       sun.AlwaysDown,
+		},
+		Moon: MoonReport{
+			Phase:        moon.Phase,
+			PhaseName:    moon.PhaseName,
+			Illumination: moon.Illumination,
+			Moonrise:     optionalTime(moon.Moonrise),
+			Moonset:      optionalTime(moon.Moonset),
+		},
+	}
+
+	//	Serialize to JSON & return the response:
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(rw).Encode(retval)
+}
+
+// optionalTime returns a pointer to the given time (or nil if it's a zero time)
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/cache"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/spf13/viper"
+)
+
+// responseCache holds upstream data so dashboards polling for the same location don't
+// hit the upstream services every time
+var responseCache = cache.New()
+
+// ConfigureResponseCache applies the configured limit (cache.maxentries) to the response cache.
+// Cache keys come from the locations (and options) clients ask for, so without a limit any
+// client could keep adding to it
+func ConfigureResponseCache() {
+	responseCache.SetMaxEntries(viper.GetInt("cache.maxentries"))
+}
+
+// cacheTTL gets the configured time-to-live for the named kind of data (like 'weather').
+// 0 keeps the data forever and a negative value turns off caching
+func cacheTTL(name string) time.Duration {
+	return viper.GetDuration(fmt.Sprintf("cache.ttl.%s", name))
+}
+
+// loaderContext gets the context for loading a cached item.  The load is shared by every caller waiting
+// on the same key, so it can't be cancelled when the first caller goes away.  It keeps the caller's
+// New Relic transaction, but it has its own timeout (cache.loadtimeout) instead
+func loaderContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := newrelic.NewContext(context.Background(), newrelic.FromContext(ctx))
+
+	timeout := viper.GetDuration("cache.loadtimeout")
+	if timeout <= 0 {
+		return context.WithCancel(detached)
+	}
+
+	return context.WithTimeout(detached, timeout)
+}
+
+// coordinateKey gets the cache key for a lat/long.  Coordinates are rounded to 2 decimal
+// places (about 1km) so nearby requests share the same cached data
+func coordinateKey(lat, long string) string {
+	latitude, latErr := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	longitude, longErr := strconv.ParseFloat(strings.TrimSpace(long), 64)
+	if latErr != nil || longErr != nil {
+		return fmt.Sprintf("%s,%s", strings.TrimSpace(lat), strings.TrimSpace(long))
+	}
+
+	return fmt.Sprintf("%.2f,%.2f", roundCoordinate(latitude), roundCoordinate(longitude))
+}
+
+// roundCoordinate rounds a coordinate to 2 decimal places (and avoids reporting -0.00)
+func roundCoordinate(value float64) float64 {
+	rounded, _ := strconv.ParseFloat(fmt.Sprintf("%.2f", value), 64)
+	if rounded == 0 {
+		return 0
+	}
+	return rounded
+}
+
+// setCacheHeaders lets the client know if the response came from the cache (and how old it is)
+func setCacheHeaders(rw http.ResponseWriter, result cache.Result) {
+	if result.Hit {
+		rw.Header().Set("X-Cache", "hit")
+	} else {
+		rw.Header().Set("X-Cache", "miss")
+	}
+
+	rw.Header().Set("Age", strconv.FormatInt(int64(result.Age/time.Second), 10))
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/cache"
+	"github.com/spf13/viper"
+)
+
+func TestCoordinateKey_RoundsNearbyCoordinates(t *testing.T) {
+	//	Arrange
+	tests := []struct {
+		lat  string
+		long string
+		want string
+	}{
+		{"33.8654", "-84.0038", "33.87,-84.00"},
+		{" 33.8712 ", "-84.0011", "33.87,-84.00"},
+		{"-0.001", "0.001", "0.00,0.00"},
+		{"not-a-number", "-84", "not-a-number,-84"},
+	}
+
+	for _, test := range tests {
+		//	Act
+		got := coordinateKey(test.lat, test.long)
+
+		//	Assert
+		if got != test.want {
+			t.Errorf("Expected %v for %v,%v but got %v instead", test.want, test.lat, test.long, got)
+		}
+	}
+}
+
+func TestSetCacheHeaders_Hit_SetsAge(t *testing.T) {
+	//	Arrange
+	rw := httptest.NewRecorder()
+
+	//	Act
+	setCacheHeaders(rw, cache.Result{Hit: true, Age: 90 * time.Second})
+
+	//	Assert
+	if got := rw.Header().Get("X-Cache"); got != "hit" {
+		t.Errorf("Expected X-Cache hit but got %v instead", got)
+	}
+
+	if got := rw.Header().Get("Age"); got != "90" {
+		t.Errorf("Expected Age 90 but got %v instead", got)
+	}
+}
+
+func TestLoaderContext_CallerCancelled_KeepsLoading(t *testing.T) {
+	//	Arrange
+	viper.Set("cache.loadtimeout", "50ms")
+	defer viper.Set("cache.loadtimeout", nil)
+
+	callerCtx, callerCancel := context.WithCancel(context.Background())
+
+	//	Act
+	ctx, cancel := loaderContext(callerCtx)
+	defer cancel()
+	callerCancel()
+
+	//	Assert
+	if ctx.Err() != nil {
+		t.Errorf("Expected the load to keep going after the caller went away but got '%v' instead", ctx.Err())
+	}
+
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("Expected the load to time out on its own but got '%v' instead", ctx.Err())
+	}
+}
@@ -1,27 +1,54 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/apognu/gocal"
+	"github.com/danesparza/daydash-service/internal/holidays"
 	"github.com/newrelic/go-agent/v3/newrelic"
-	"golang.org/x/net/context/ctxhttp"
 )
 
 type CalendarRequest struct {
-	CalendarURL string `json:"url"`
-	Timezone    string `json:"timezone"`
+	CalendarURL string           `json:"url"`       // Optional single calendar url (use calendars for more than one)
+	Calendars   []CalendarSource `json:"calendars"` // Optional list of calendars to merge
+	Timezone    string           `json:"timezone"`
+	Start       string           `json:"start"` // Optional first day (YYYY-MM-DD in the timezone given).  Defaults to today
+	End         string           `json:"end"`   // Optional last day (YYYY-MM-DD, inclusive).  Defaults to the start day
+	Days        int              `json:"days"`  // Optional number of days to include (instead of end)
+
+	IncludeCancelled bool              `json:"include_cancelled"` // Include cancelled events (they're left out by default)
+	Holidays         *CalendarHolidays `json:"holidays"`          // Optional public holidays to include as all-day events
+
+	Analyze      bool   `json:"analyze"`       // Include free/busy blocks and conflicts for each day, and the next event
+	WorkdayStart string `json:"workday_start"` // Optional start of working hours for free/busy blocks (HH:MM).  Defaults to the configured start
+	WorkdayEnd   string `json:"workday_end"`   // Optional end of working hours for free/busy blocks (HH:MM).  Defaults to the configured end
+}
+
+// CalendarSource is a single calendar to include in a calendar request
+type CalendarSource struct {
+	URL    string `json:"url"`    // The iCal url
+	CalDAV string `json:"caldav"` // Optional name of a CalDAV calendar configured on the server (used instead of url)
+	Name   string `json:"name"`   // Optional display name (defaults to the url host or CalDAV calendar name)
+	Color  string `json:"color"`  // Optional display color (like '#3366cc')
 }
 
 type CalendarResponse struct {
-	TimeZone         string          `json:"timezone"`         // The timezone used
-	CurrentLocalTime time.Time       `json:"currentlocaltime"` // Sanity check:  Current local time in the timezone given
-	Events           []CalendarEvent `json:"events"`           // The calendar events found
+	TimeZone         string             `json:"timezone"`            // The timezone used
+	CurrentLocalTime time.Time          `json:"currentlocaltime"`    // Sanity check:  Current local time in the timezone given
+	Start            time.Time          `json:"start"`               // The start of the first day included
+	End              time.Time          `json:"end"`                 // The end of the last day included
+	Events           []CalendarEvent    `json:"events"`              // The calendar events found (sorted by start time)
+	Days             []CalendarDay      `json:"days"`                // The calendar events found, grouped by local date
+	Errors           []ProviderError    `json:"errors,omitempty"`    // Calendars that couldn't be included (by calendar name)
+	NextEvent        *CalendarNextEvent `json:"nextevent,omitempty"` // The next event to start after the current local time (when analyze is set)
 }
 
 type CalendarEvent struct {
@@ -30,11 +57,19 @@ type CalendarEvent struct {
 	Description string    `json:"description"` // Event long description
 	StartTime   time.Time `json:"starttime"`   // Event start time
 	EndTime     time.Time `json:"endtime"`     // Event end time
+	AllDay      bool      `json:"allday"`      // True if the event lasts all day (start and end are local midnights)
+	Location    string    `json:"location"`    // Where the event is
+	Status      string    `json:"status"`      // confirmed, tentative or cancelled (blank if the calendar doesn't say)
+	Organizer   string    `json:"organizer"`   // Who organized the event (their name, or email address if there's no name)
+	URL         string    `json:"url"`         // Link to more information about the event
+	Recurring   bool      `json:"recurring"`   // True if the event is an instance of a repeating event
+	Calendar    string    `json:"calendar"`    // The name of the calendar the event came from
+	Color       string    `json:"color"`       // The display color of the calendar the event came from
 }
 
 // GetCalendar godoc
-// @Summary Gets today's calendar data for the given iCal url and timezone
-// @Description Gets today's calendar data for the given iCal url and timezone
+// @Summary Gets calendar data for the given iCal urls and timezone
+// @Description Gets calendar data for the given iCal url (or list of calendars) and timezone.  Calendars can also be CalDAV calendars configured on the server (by name), and public holidays can be included as all-day events.  With analyze set, each day includes free/busy blocks within working hours and groups of conflicting events, and the next event to start is included.  Events from all calendars are merged and sorted by start time.  If some calendars can't be fetched, their errors are included and the rest of the events are returned.  By default only today's events are included -- use start, end or days to get a range of days (up to 31)
 // @Tags dashboard
 // @Accept  json
 // @Produce  json
@@ -42,6 +77,9 @@ type CalendarEvent struct {
 // @Success 200 {object} api.CalendarResponse
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
+// @Failure 502 {object} api.ErrorResponse
+// @Failure 503 {object} api.ErrorResponse
+// @Failure 504 {object} api.ErrorResponse
 // @Router /calendar [post]
 func (s Service) GetCalendar(rw http.ResponseWriter, req *http.Request) {
 
@@ -61,19 +99,21 @@ func (s Service) GetCalendar(rw http.ResponseWriter, req *http.Request) {
 			"error", err,
 		)
 		txn.NoticeError(err)
-		sendErrorResponse(rw, err, http.StatusBadRequest)
+		sendErrorResponse(rw, newBadRequestError(err))
 		return
 	}
 
+	//	Gather the calendars to include
+	sources := calendarSources(request)
+
 	//	Make sure we have minimum args:
-	if request.CalendarURL == "" || request.Timezone == "" {
-		sendErrorResponse(rw, fmt.Errorf("you must include a valid url and timezone param"), http.StatusBadRequest)
+	if (len(sources) == 0 && request.Holidays == nil) || request.Timezone == "" {
+		sendErrorResponse(rw, newBadRequestError(fmt.Errorf("you must include a valid url (or calendars or holidays) and timezone param")))
 		return
 	}
 
 	//	Set the timezone in the response
 	timezone := request.Timezone
-	url := request.CalendarURL
 	retval.TimeZone = timezone
 
 	//	Set our start / end times
@@ -85,18 +125,43 @@ func (s Service) GetCalendar(rw http.ResponseWriter, req *http.Request) {
 			"error", err,
 		)
 		txn.NoticeError(err)
-		sendErrorResponse(rw, err, http.StatusInternalServerError)
+		sendErrorResponse(rw, newBadRequestError(fmt.Errorf("you must include a valid timezone param: %v", err)))
 		return
 	}
 
 	//	Current time in the location
 	t := time.Now().In(location)
 
-	//	Find the beginning and end of the day in the given timezone
-	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
-	end := time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, location)
+	//	Find the beginning of the first day and end of the last day in the given timezone
+	start, end, err := calendarRange(request, t, location)
+	if err != nil {
+		sendErrorResponse(rw, newBadRequestError(err))
+		return
+	}
+
+	//	Find the holiday rules (if holidays were asked for)
+	var holidayCalendars []holidays.Calendar
+	if request.Holidays != nil {
+		holidayCalendars, _, err = findHolidayCalendars(request.Holidays.Country, request.Holidays.Region)
+		if err != nil {
+			sendErrorResponse(rw, err)
+			return
+		}
+	}
+
+	//	Find the working hours (if the calendar should be analyzed)
+	var hours workingHours
+	if request.Analyze {
+		hours, err = calendarWorkingHours(request)
+		if err != nil {
+			sendErrorResponse(rw, newBadRequestError(err))
+			return
+		}
+	}
 
 	retval.CurrentLocalTime = t
+	retval.Start = start
+	retval.End = end
 
 	zlog.Infow(
 		"Time debugging",
@@ -104,113 +169,214 @@ func (s Service) GetCalendar(rw http.ResponseWriter, req *http.Request) {
 		"start", start,
 		"end", end,
 		"timezone", timezone,
-		"url", url,
+		"calendars", len(sources),
 	)
 
-	//	First, get the ical calendar at the url given
-	clientRequest, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		zlog.Errorw(
-			"problem creating request to the ical url given",
-			"err", err,
-		)
-		txn.NoticeError(err)
-		sendErrorResponse(rw, err, http.StatusInternalServerError)
+	//	Get the events from each calendar at the same time
+	results := make([]calendarResult, len(sources))
+	wg := sync.WaitGroup{}
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source CalendarSource) {
+			defer wg.Done()
+			results[i].events, results[i].err = getCalendarEvents(req.Context(), source, start, end, location)
+		}(i, source)
+	}
+	wg.Wait()
+
+	//	Merge the events (and keep track of any problems)
+	problems := []ProviderError{}
+	for i, result := range results {
+		if result.err != nil {
+			txn.NoticeError(result.err)
+			problems = append(problems, newProviderError(sources[i].Name, result.err))
+			continue
+		}
+
+		retval.Events = append(retval.Events, result.events...)
+	}
+
+	//	If nothing worked, it's an error.  With a single calendar, just report its error
+	if len(sources) > 0 && len(problems) == len(sources) {
+		if len(sources) == 1 {
+			sendErrorResponse(rw, results[0].err)
+			return
+		}
+
+		sendErrorResponse(rw, newProvidersFailedError(fmt.Errorf("all %v calendars failed", len(sources)), problems))
 		return
 	}
 
-	//	Set our headers
-	clientRequest.Header.Set("Content-Type", "application/geo+json; charset=UTF-8")
-	clientRequest = newrelic.RequestWithTransactionContext(clientRequest, txn)
+	if len(problems) > 0 {
+		retval.Errors = problems
+	}
+
+	if request.Holidays != nil {
+		retval.Events = append(retval.Events, getHolidayEvents(holidayCalendars, *request.Holidays, start, end, location)...)
+	}
+
+	//	Leave out cancelled events unless they were asked for
+	if !request.IncludeCancelled {
+		retval.Events = withoutCancelledEvents(retval.Events)
+	}
+
+	//	Put the events in order and group them by day
+	sortCalendarEvents(retval.Events)
+	retval.Days = groupEventsByDay(retval.Events, start, end, location)
+
+	//	Find the free time, conflicts and next event (if they were asked for)
+	if request.Analyze {
+		analyzeCalendarDays(retval.Days, hours, location)
+		retval.NextEvent = nextCalendarEvent(retval.Events, t)
+	}
+
+	//	Serialize to JSON & return the response:
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(rw).Encode(retval)
+
+}
+
+// calendarResult is the outcome of getting the events from a single calendar
+type calendarResult struct {
+	events []CalendarEvent
+	err    error
+}
+
+// calendarSources gets the calendars in a request (the single url, if there is one, comes first).
+// Calendars without a name are named after their url host (or CalDAV calendar name)
+func calendarSources(request CalendarRequest) []CalendarSource {
+	retval := []CalendarSource{}
+
+	if request.CalendarURL != "" {
+		retval = append(retval, CalendarSource{URL: request.CalendarURL})
+	}
+
+	for _, source := range request.Calendars {
+		if strings.TrimSpace(source.URL) == "" && strings.TrimSpace(source.CalDAV) == "" {
+			continue
+		}
+		retval = append(retval, source)
+	}
+
+	for i := range retval {
+		if retval[i].Name == "" && retval[i].CalDAV != "" {
+			retval[i].Name = retval[i].CalDAV
+		}
+		if retval[i].Name == "" {
+			retval[i].Name = retval[i].URL
+			if parsed, err := url.Parse(retval[i].URL); err == nil && parsed.Host != "" {
+				retval[i].Name = parsed.Host
+			}
+		}
+	}
 
-	//	Execute the request
-	client := &http.Client{}
-	client.Transport = newrelic.NewRoundTripper(client.Transport)
-	calendarDataResponse, err := ctxhttp.Do(req.Context(), client, clientRequest)
+	return retval
+}
+
+// getCalendarEvents gets the events from start to end for a single calendar
+func getCalendarEvents(ctx context.Context, source CalendarSource, start, end time.Time, location *time.Location) ([]CalendarEvent, error) {
+
+	txn := newrelic.FromContext(ctx)
+	segment := txn.StartSegment("Calendar getCalendarEvents")
+	defer segment.End()
+
+	//	Our return value
+	retval := []CalendarEvent{}
+
+	//	Floating times are read by the parser in the server's time zone, so ask for an extra day on
+	//	each side to make sure we don't miss any
+	queryStart, queryEnd := start.AddDate(0, 0, -1), end.AddDate(0, 0, 1)
+
+	//	First, get the ical calendar at the url given (or the events from the CalDAV calendar)
+	var feed *calendarFeed
+	var err error
+	if source.CalDAV != "" {
+		feed, err = fetchCalDAVFeed(ctx, source, queryStart, queryEnd)
+	} else {
+		feed, err = fetchCalendarFeed(ctx, source)
+	}
 	if err != nil {
-		zlog.Errorw(
-			"error when sending request to get the calendar data from the url",
-			"err", err,
-		)
-		txn.NoticeError(err)
-		sendErrorResponse(rw, err, http.StatusInternalServerError)
-		return
+		return retval, err
 	}
-	defer calendarDataResponse.Body.Close()
 
-	//	Create a parser and use our start/end times
-	calEvents, err := GetEventsForDay(calendarDataResponse.Body, start, end, location)
+	//	Get the events in our start/end times
+	calEvents, err := feed.events(queryStart, queryEnd, location)
 	if err != nil {
 		zlog.Errorw(
 			"problem getting events for day",
+			"calendar", source.Name,
 			"err", err,
 			"start", start,
 			"end", end,
 			"location", location,
 		)
-		txn.NoticeError(err)
-		sendErrorResponse(rw, err, http.StatusInternalServerError)
-		return
+		return retval, newUpstreamBadDataError(err)
 	}
 
 	//	Track our event IDs
 	eventIDs := make(map[string]struct{})
 
-	//	Google calendar is so fucking weird.
-	//	Regular events (even if they recur) are returned as UTC start/end times.  They can be converted to local time easily.  This is good.
-	//	All-day events are going to be returned without a timezone.  A parser will assume a time of midnight IN THE UTC TIMEZONE.  This will lead to bullshit weirdness.
+	//	Every calendar host has its own idea of how to describe all-day events and local times, so
+	//	the times are normalized (see normalizeEventTimes) rather than trusting what the parser assumed
 	for _, e := range calEvents {
 
-		//	If we have duplicate event ids (and we shouldn't ... but I've seen this in the wild) discard anything after the first instance
+		calEvent := newCalendarEvent(e, source)
+		calEvent.StartTime, calEvent.EndTime, calEvent.AllDay = normalizeEventTimes(e, location)
+
+		//	We asked for a little extra (in case of floating times) -- only keep what's in our range
+		if !eventOnDay(calEvent, start, end.Add(time.Second)) {
+			continue
+		}
+
+		//	If we have duplicate events (and we shouldn't ... but I've seen this in the wild) discard anything after the first instance.
+		//	Instances of a recurring event share an id, so they're told apart by when they start
 		//	See https://stackoverflow.com/a/10486196/19020 for more info on using an empty struct in a map to track this
-		if _, containsEvent := eventIDs[e.Uid]; containsEvent {
+		eventID := fmt.Sprintf("%s|%d", e.Uid, calEvent.StartTime.Unix())
+		if _, containsEvent := eventIDs[eventID]; containsEvent {
 			continue
 		} else {
-			eventIDs[e.Uid] = struct{}{}
+			eventIDs[eventID] = struct{}{}
 		}
 
-		diff := e.End.Sub(*e.Start)
-
-		//	If it looks like it's an all-day event, and the url includes 'calendar.google.com', then don't use the timezone
-		if diff.Hours() > 23 && strings.Contains(url, "calendar.google.com") {
-
-			zlog.Infow(
-				"Google all-day event detected.  Using the UTC start/end times and rewriting them as local",
-				"url", url,
-				"summary", e.Summary,
-				"description", e.Description,
-				"starttime", e.Start.UTC(),
-				"endtime", e.End.UTC(),
-				"rewritten-starttime", RewriteToLocal(e.Start.UTC(), location),
-				"rewritten-endtime", RewriteToLocal(e.End.UTC(), location),
-			)
-
-			calEvent := CalendarEvent{
-				UID:         e.Uid,
-				Summary:     e.Summary,
-				Description: e.Description,
-				StartTime:   RewriteToLocal(e.Start.UTC(), location), // Rewrite the UTC time to appear as local time
-				EndTime:     RewriteToLocal(e.End.UTC(), location),   // Rewrite the UTC time to appear as local time
-			}
+		retval = append(retval, calEvent)
+	}
 
-			retval.Events = append(retval.Events, calEvent)
-		} else {
-			calEvent := CalendarEvent{
-				UID:         e.Uid,
-				Summary:     e.Summary,
-				Description: e.Description,
-				StartTime:   e.Start.In(location),
-				EndTime:     e.End.In(location),
-			}
+	return retval, nil
+}
 
-			retval.Events = append(retval.Events, calEvent)
+// newCalendarEvent creates an event (without start and end times) from a parsed iCal event
+func newCalendarEvent(e gocal.Event, source CalendarSource) CalendarEvent {
+	retval := CalendarEvent{
+		UID:         e.Uid,
+		Summary:     e.Summary,
+		Description: e.Description,
+		Location:    e.Location,
+		Status:      strings.ToLower(e.Status),
+		URL:         e.URL,
+		Recurring:   e.IsRecurring || e.RecurrenceID != "",
+		Calendar:    source.Name,
+		Color:       source.Color,
+	}
+
+	if e.Organizer != nil {
+		retval.Organizer = e.Organizer.Cn
+		if retval.Organizer == "" {
+			retval.Organizer = strings.TrimPrefix(strings.TrimPrefix(e.Organizer.Value, "mailto:"), "MAILTO:")
 		}
 	}
 
-	//	Serialize to JSON & return the response:
-	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(rw).Encode(retval)
+	return retval
+}
 
+// withoutCancelledEvents gets the events that haven't been cancelled
+func withoutCancelledEvents(events []CalendarEvent) []CalendarEvent {
+	retval := []CalendarEvent{}
+	for _, event := range events {
+		if event.Status != "cancelled" {
+			retval = append(retval, event)
+		}
+	}
+	return retval
 }
 
 // RewriteToLocal - rewrites a given time to use the passed location data
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// The working hours used for free/busy blocks when they aren't configured
+const (
+	defaultWorkdayStart = "09:00"
+	defaultWorkdayEnd   = "17:00"
+)
+
+// workingHoursLayout is the format of the working hours in calendar requests and the config
+const workingHoursLayout = "15:04"
+
+// CalendarBlock is a free or busy stretch of time within working hours
+type CalendarBlock struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Busy  bool      `json:"busy"` // True if any event happens during the block
+}
+
+// CalendarConflict is a group of events that overlap each other
+type CalendarConflict struct {
+	Start  time.Time       `json:"start"`  // When the first event in the group starts
+	End    time.Time       `json:"end"`    // When the last event in the group ends
+	Events []CalendarEvent `json:"events"` // The overlapping events (sorted by start time)
+}
+
+// CalendarNextEvent is the next event to start after the current local time
+type CalendarNextEvent struct {
+	Event    CalendarEvent `json:"event"`
+	StartsIn int           `json:"startsin"` // Minutes until the event starts (rounded up)
+}
+
+// workingHours is the time of day (as an offset from midnight) that working hours start and end
+type workingHours struct {
+	start time.Duration
+	end   time.Duration
+}
+
+// calendarWorkingHours gets the working hours for a calendar request (from the config, if the request
+// doesn't include them)
+func calendarWorkingHours(request CalendarRequest) (workingHours, error) {
+	retval := workingHours{}
+
+	times := []struct {
+		name     string
+		value    string
+		config   string
+		fallback string
+		result   *time.Duration
+	}{
+		{"workday_start", request.WorkdayStart, viper.GetString("calendar.workinghours.start"), defaultWorkdayStart, &retval.start},
+		{"workday_end", request.WorkdayEnd, viper.GetString("calendar.workinghours.end"), defaultWorkdayEnd, &retval.end},
+	}
+	for _, t := range times {
+		value := t.value
+		if value == "" {
+			value = t.config
+		}
+		if value == "" {
+			value = t.fallback
+		}
+
+		parsed, err := time.Parse(workingHoursLayout, value)
+		if err != nil {
+			return retval, fmt.Errorf("%s must be a time of day (like 09:00)", t.name)
+		}
+		*t.result = time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute
+	}
+
+	if retval.end <= retval.start {
+		return retval, fmt.Errorf("workday_end must be after workday_start")
+	}
+
+	return retval, nil
+}
+
+// busyEvent returns true if the event takes up time.  All-day events (like holidays or birthdays) and
+// cancelled events don't
+func busyEvent(event CalendarEvent) bool {
+	return !event.AllDay && event.Status != "cancelled" && event.EndTime.After(event.StartTime)
+}
+
+// analyzeCalendarDays adds the free/busy blocks (within working hours) and conflicts to each day
+func analyzeCalendarDays(days []CalendarDay, hours workingHours, location *time.Location) {
+	for i, day := range days {
+		date, err := time.ParseInLocation(calendarDateLayout, day.Date, location)
+		if err != nil {
+			continue
+		}
+
+		//	Use the wall clock on the day (so working hours are right on daylight saving days)
+		dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
+		workStart := time.Date(date.Year(), date.Month(), date.Day(), 0, int(hours.start/time.Minute), 0, 0, location)
+		workEnd := time.Date(date.Year(), date.Month(), date.Day(), 0, int(hours.end/time.Minute), 0, 0, location)
+
+		days[i].FreeBusy = freeBusyBlocks(day.Events, workStart, workEnd)
+		days[i].Conflicts = eventConflicts(day.Events, dayStart, dayStart.AddDate(0, 0, 1))
+	}
+}
+
+// freeBusyBlocks splits the time from start to end into free and busy blocks.  Back to back (or
+// overlapping) events make a single busy block
+func freeBusyBlocks(events []CalendarEvent, start, end time.Time) []CalendarBlock {
+	retval := []CalendarBlock{}
+
+	sorted := append([]CalendarEvent{}, events...)
+	sortCalendarEvents(sorted)
+
+	free := start
+	for _, event := range sorted {
+		if !busyEvent(event) || !event.StartTime.Before(end) || !event.EndTime.After(free) {
+			continue
+		}
+
+		busyStart := event.StartTime
+		if busyStart.Before(free) {
+			busyStart = free
+		}
+		busyEnd := event.EndTime
+		if busyEnd.After(end) {
+			busyEnd = end
+		}
+
+		//	Grow the last busy block if this event starts before (or as) it ends
+		if last := len(retval) - 1; last >= 0 && retval[last].Busy && !busyStart.After(retval[last].End) {
+			if busyEnd.After(retval[last].End) {
+				retval[last].End = busyEnd
+			}
+			free = retval[last].End
+			continue
+		}
+
+		if busyStart.After(free) {
+			retval = append(retval, CalendarBlock{Start: free, End: busyStart})
+		}
+		retval = append(retval, CalendarBlock{Start: busyStart, End: busyEnd, Busy: true})
+		free = busyEnd
+	}
+
+	if free.Before(end) {
+		retval = append(retval, CalendarBlock{Start: free, End: end})
+	}
+
+	return retval
+}
+
+// eventConflicts finds groups of events that overlap each other (at least partly during the day from
+// dayStart to dayEnd).  Events that only touch (one ends as the next starts) don't conflict
+func eventConflicts(events []CalendarEvent, dayStart, dayEnd time.Time) []CalendarConflict {
+	retval := []CalendarConflict{}
+
+	sorted := []CalendarEvent{}
+	for _, event := range events {
+		if busyEvent(event) && eventOnDay(event, dayStart, dayEnd) {
+			sorted = append(sorted, event)
+		}
+	}
+	sortCalendarEvents(sorted)
+
+	group := CalendarConflict{}
+	for _, event := range sorted {
+		if len(group.Events) > 0 && event.StartTime.Before(group.End) {
+			group.Events = append(group.Events, event)
+			if event.EndTime.After(group.End) {
+				group.End = event.EndTime
+			}
+			continue
+		}
+
+		if len(group.Events) > 1 {
+			retval = append(retval, group)
+		}
+		group = CalendarConflict{Start: event.StartTime, End: event.EndTime, Events: []CalendarEvent{event}}
+	}
+
+	if len(group.Events) > 1 {
+		retval = append(retval, group)
+	}
+
+	return retval
+}
+
+// nextCalendarEvent gets the first event (of the events sorted by start time) that starts after now, or
+// nil if there isn't one.  All-day and cancelled events are skipped
+func nextCalendarEvent(events []CalendarEvent, now time.Time) *CalendarNextEvent {
+	for _, event := range events {
+		if event.AllDay || event.Status == "cancelled" || !event.StartTime.After(now) {
+			continue
+		}
+
+		return &CalendarNextEvent{
+			Event:    event,
+			StartsIn: int(math.Ceil(event.StartTime.Sub(now).Minutes())),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testEvent builds a calendar event on June 1, 2022 in the given location
+func testEvent(uid string, startHour, startMinute, minutes int, location *time.Location) CalendarEvent {
+	start := time.Date(2022, 6, 1, startHour, startMinute, 0, 0, location)
+	return CalendarEvent{UID: uid, Summary: uid, StartTime: start, EndTime: start.Add(time.Duration(minutes) * time.Minute)}
+}
+
+func TestFreeBusyBlocks_MergesEventsWithinWorkingHours(t *testing.T) {
+	//	Arrange
+	location, _ := time.LoadLocation("America/New_York")
+	holiday := CalendarEvent{UID: "holiday", StartTime: time.Date(2022, 6, 1, 0, 0, 0, 0, location), EndTime: time.Date(2022, 6, 2, 0, 0, 0, 0, location), AllDay: true}
+	cancelled := testEvent("cancelled", 15, 0, 60, location)
+	cancelled.Status = "cancelled"
+
+	events := []CalendarEvent{
+		testEvent("breakfast", 8, 0, 90, location), // Starts before working hours
+		testEvent("standup", 10, 0, 30, location),
+		testEvent("review", 10, 30, 60, location), // Back to back with standup
+		testEvent("overlap", 11, 0, 15, location), // Inside review
+		testEvent("late", 16, 30, 120, location),  // Ends after working hours
+		holiday,
+		cancelled,
+	}
+
+	//	Act
+	blocks := freeBusyBlocks(events, time.Date(2022, 6, 1, 9, 0, 0, 0, location), time.Date(2022, 6, 1, 17, 0, 0, 0, location))
+
+	//	Assert
+	expected := []struct {
+		start, end string
+		busy       bool
+	}{
+		{"09:00", "09:30", true},
+		{"09:30", "10:00", false},
+		{"10:00", "11:30", true},
+		{"11:30", "16:30", false},
+		{"16:30", "17:00", true},
+	}
+
+	if len(blocks) != len(expected) {
+		t.Fatalf("Expected %v blocks but got %+v instead", len(expected), blocks)
+	}
+
+	for i, block := range blocks {
+		start, end := block.Start.Format(workingHoursLayout), block.End.Format(workingHoursLayout)
+		if start != expected[i].start || end != expected[i].end || block.Busy != expected[i].busy {
+			t.Errorf("Expected block %v to be %v - %v (busy: %v) but got %v - %v (busy: %v) instead", i, expected[i].start, expected[i].end, expected[i].busy, start, end, block.Busy)
+		}
+	}
+}
+
+func TestFreeBusyBlocks_NoEvents_IsOneFreeBlock(t *testing.T) {
+	//	Arrange
+	start := time.Date(2022, 6, 1, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2022, 6, 1, 17, 0, 0, 0, time.UTC)
+
+	//	Act
+	blocks := freeBusyBlocks([]CalendarEvent{}, start, end)
+
+	//	Assert
+	if len(blocks) != 1 || blocks[0].Busy || !blocks[0].Start.Equal(start) || !blocks[0].End.Equal(end) {
+		t.Errorf("Expected a single free block but got %+v instead", blocks)
+	}
+}
+
+func TestEventConflicts_GroupsOverlappingEvents(t *testing.T) {
+	//	Arrange
+	location := time.UTC
+	events := []CalendarEvent{
+		testEvent("a", 9, 0, 60, location),
+		testEvent("b", 9, 30, 60, location),  // Overlaps a
+		testEvent("c", 10, 15, 30, location), // Overlaps b (but not a)
+		testEvent("d", 10, 45, 30, location), // Starts as c ends
+		testEvent("e", 13, 0, 30, location),
+		testEvent("f", 13, 0, 30, location), // Same time as e
+		testEvent("g", 15, 0, 30, location),
+	}
+	dayStart := time.Date(2022, 6, 1, 0, 0, 0, 0, location)
+
+	//	Act
+	conflicts := eventConflicts(events, dayStart, dayStart.AddDate(0, 0, 1))
+
+	//	Assert
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected 2 conflict groups but got %+v instead", conflicts)
+	}
+
+	uids := func(conflict CalendarConflict) string {
+		retval := ""
+		for _, event := range conflict.Events {
+			retval += event.UID
+		}
+		return retval
+	}
+
+	if uids(conflicts[0]) != "abc" || !conflicts[0].End.Equal(time.Date(2022, 6, 1, 10, 45, 0, 0, location)) {
+		t.Errorf("Expected a, b and c to conflict until 10:45 but got %v until %v instead", uids(conflicts[0]), conflicts[0].End)
+	}
+
+	if uids(conflicts[1]) != "ef" {
+		t.Errorf("Expected e and f to conflict but got %v instead", uids(conflicts[1]))
+	}
+}
+
+func TestNextCalendarEvent_SkipsStartedAndAllDayEvents(t *testing.T) {
+	//	Arrange
+	location := time.UTC
+	now := time.Date(2022, 6, 1, 9, 10, 30, 0, location)
+	allDay := testEvent("allday", 10, 0, 60, location)
+	allDay.AllDay = true
+
+	events := []CalendarEvent{
+		testEvent("started", 9, 0, 60, location),
+		allDay,
+		testEvent("next", 10, 0, 30, location),
+		testEvent("later", 11, 0, 30, location),
+	}
+
+	//	Act
+	next := nextCalendarEvent(events, now)
+	none := nextCalendarEvent(events, time.Date(2022, 6, 1, 12, 0, 0, 0, location))
+
+	//	Assert
+	if next == nil || next.Event.UID != "next" || next.StartsIn != 50 {
+		t.Errorf("Expected the next event to start in 50 minutes but got %+v instead", next)
+	}
+
+	if none != nil {
+		t.Errorf("Expected no next event but got %+v instead", none)
+	}
+}
+
+func TestCalendarWorkingHours_ValidatesTimes(t *testing.T) {
+	//	Arrange
+	tests := []struct {
+		start, end string
+		expectErr  bool
+	}{
+		{"", "", false},
+		{"08:30", "18:00", false},
+		{"9am", "", true},
+		{"17:00", "09:00", true},
+	}
+
+	for _, test := range tests {
+		//	Act
+		hours, err := calendarWorkingHours(CalendarRequest{WorkdayStart: test.start, WorkdayEnd: test.end})
+
+		//	Assert
+		if (err != nil) != test.expectErr {
+			t.Errorf("Expected error %v for %v - %v but got %v instead", test.expectErr, test.start, test.end, err)
+		}
+
+		if test.start == "" && !test.expectErr && (hours.start != 9*time.Hour || hours.end != 17*time.Hour) {
+			t.Errorf("Expected the default working hours but got %+v instead", hours)
+		}
+	}
+}
+
+func TestGetCalendar_Analyze_IncludesFreeBusyAndConflicts(t *testing.T) {
+	//	Arrange
+	feed := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//daydash//test//EN\r\n"
+	for _, event := range []struct {
+		uid        string
+		start, end string
+	}{
+		{"standup", "20220601T140000Z", "20220601T143000Z"},
+		{"review", "20220601T141500Z", "20220601T150000Z"},
+	} {
+		feed += fmt.Sprintf("BEGIN:VEVENT\r\nUID:%s\r\nDTSTAMP:20220501T000000Z\r\nSUMMARY:%s\r\nDTSTART:%s\r\nDTEND:%s\r\nEND:VEVENT\r\n", event.uid, event.uid, event.start, event.end)
+	}
+	feed += "END:VCALENDAR\r\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(rw, feed)
+	}))
+	defer server.Close()
+
+	body, _ := json.Marshal(CalendarRequest{CalendarURL: server.URL, Timezone: "America/New_York", Start: "2022-06-01", Analyze: true, WorkdayStart: "08:00", WorkdayEnd: "12:00"})
+	req := httptest.NewRequest("POST", "/v2/calendar", bytes.NewReader(body))
+	rw := httptest.NewRecorder()
+
+	//	Act
+	Service{}.GetCalendar(rw, req)
+
+	//	Assert
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected a 200 but got %v instead: %s", rw.Code, rw.Body.String())
+	}
+
+	response := CalendarResponse{}
+	json.NewDecoder(rw.Body).Decode(&response)
+
+	day := response.Days[0]
+	if len(day.FreeBusy) != 3 || !day.FreeBusy[1].Busy || day.FreeBusy[1].Start.Hour() != 10 || day.FreeBusy[1].End.Hour() != 11 {
+		t.Errorf("Expected free, busy 10:00 - 11:00 and free blocks but got %+v instead", day.FreeBusy)
+	}
+
+	if len(day.Conflicts) != 1 || len(day.Conflicts[0].Events) != 2 {
+		t.Errorf("Expected the standup and review to conflict but got %+v instead", day.Conflicts)
+	}
+
+	//	The events are in the past, so there's no next event
+	if response.NextEvent != nil {
+		t.Errorf("Expected no next event but got %+v instead", response.NextEvent)
+	}
+}
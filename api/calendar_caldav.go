@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apognu/gocal"
+	"github.com/danesparza/daydash-service/internal/caldav"
+	"github.com/danesparza/daydash-service/internal/httpclient"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/spf13/viper"
+)
+
+// calDAVCalendar is a CalDAV calendar configured on the server (in calendar.caldav.<name>).  Dashboards
+// refer to it by name, so the credentials never leave the server
+type calDAVCalendar struct {
+	URL         string `mapstructure:"url"`
+	caldav.Auth `mapstructure:",squash"`
+}
+
+// getCalDAVCalendar gets the configured CalDAV calendar with the given name
+func getCalDAVCalendar(name string) (calDAVCalendar, bool) {
+	calendars := map[string]calDAVCalendar{}
+	if err := viper.UnmarshalKey("calendar.caldav", &calendars); err != nil {
+		zlog.Errorw(
+			"problem reading the CalDAV calendar settings",
+			"error", err,
+		)
+		return calDAVCalendar{}, false
+	}
+
+	//	Config keys aren't case sensitive
+	retval, found := calendars[strings.ToLower(name)]
+	return retval, found && retval.URL != ""
+}
+
+// fetchCalDAVFeed gets the events from start to end from a configured CalDAV calendar
+func fetchCalDAVFeed(ctx context.Context, source CalendarSource, start, end time.Time) (*calendarFeed, error) {
+
+	txn := newrelic.FromContext(ctx)
+	segment := txn.StartSegment("Calendar fetchCalDAVFeed")
+	defer segment.End()
+
+	calendar, found := getCalDAVCalendar(source.CalDAV)
+	if !found {
+		return nil, newBadRequestError(fmt.Errorf("there is no CalDAV calendar named '%s' configured", source.CalDAV))
+	}
+
+	maxBodySize := viper.GetInt64("calendar.maxbodysize")
+	if maxBodySize <= 0 {
+		maxBodySize = defaultCalendarMaxBodySize
+	}
+
+	client := caldav.Client{
+		URL:         calendar.URL,
+		Auth:        calendar.Auth,
+		HTTPClient:  httpclient.Default(),
+		MaxBodySize: maxBodySize,
+	}
+
+	body, err := client.Events(newrelic.NewContext(ctx, txn), start, end)
+	if err != nil {
+		zlog.Errorw(
+			"problem getting events from the CalDAV calendar",
+			"calendar", source.Name,
+			"error", err,
+		)
+
+		var statusErr caldav.StatusError
+		switch {
+		case errors.As(err, &statusErr):
+			return nil, newUpstreamStatusError(err, statusErr.StatusCode)
+		case errors.Is(err, caldav.ErrInvalidResponse):
+			return nil, newUpstreamBadDataError(err)
+		case errors.Is(err, caldav.ErrUnknownAuthType):
+			return nil, newInternalError(err)
+		}
+		return nil, newUpstreamError(err)
+	}
+
+	return &calendarFeed{body: body, parsed: map[string][]gocal.Event{}}, nil
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxCalendarDays is the most days a single calendar request can cover
+const maxCalendarDays = 31
+
+// calendarDateLayout is the format of the dates in calendar requests and responses
+const calendarDateLayout = "2006-01-02"
+
+// CalendarDay is the list of events on a single (local) day
+type CalendarDay struct {
+	Date      string             `json:"date"`                // The local date (YYYY-MM-DD)
+	Events    []CalendarEvent    `json:"events"`              // Events on the day (including events that started earlier or end later), sorted by start time
+	FreeBusy  []CalendarBlock    `json:"freebusy,omitempty"`  // Free and busy blocks within working hours (when analyze is set)
+	Conflicts []CalendarConflict `json:"conflicts,omitempty"` // Groups of events that overlap each other (when analyze is set)
+}
+
+// calendarRange gets the start and end times for the days in a calendar request.  Without a start date
+// the range starts today.  Without an end date (or a number of days) the range is a single day
+func calendarRange(request CalendarRequest, now time.Time, location *time.Location) (time.Time, time.Time, error) {
+
+	//	Find the first day
+	first := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if request.Start != "" {
+		parsed, err := time.ParseInLocation(calendarDateLayout, request.Start, location)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("start must be a date (like 2022-06-01): %v", err)
+		}
+		first = parsed
+	}
+
+	//	Find the last day
+	last := first
+	switch {
+	case request.End != "" && request.Days != 0:
+		return time.Time{}, time.Time{}, fmt.Errorf("you can include end or days, but not both")
+
+	case request.End != "":
+		parsed, err := time.ParseInLocation(calendarDateLayout, request.End, location)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("end must be a date (like 2022-06-07): %v", err)
+		}
+		if parsed.Before(first) {
+			return time.Time{}, time.Time{}, fmt.Errorf("end can't be before start")
+		}
+		last = parsed
+
+	case request.Days < 0:
+		return time.Time{}, time.Time{}, fmt.Errorf("days must be at least 1")
+
+	case request.Days > 0:
+		last = first.AddDate(0, 0, request.Days-1)
+	}
+
+	if last.After(first.AddDate(0, 0, maxCalendarDays-1)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("calendar requests can cover at most %v days", maxCalendarDays)
+	}
+
+	start := first
+	end := time.Date(last.Year(), last.Month(), last.Day(), 23, 59, 59, 0, location)
+
+	return start, end, nil
+}
+
+// groupEventsByDay puts the events on each local day from start to end.  Events that span more than one
+// day show up on each of them.  An event that ends exactly at midnight doesn't show up on the next day
+func groupEventsByDay(events []CalendarEvent, start, end time.Time, location *time.Location) []CalendarDay {
+	retval := []CalendarDay{}
+
+	sorted := append([]CalendarEvent{}, events...)
+	sortCalendarEvents(sorted)
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, location)
+		dayEnd := dayStart.AddDate(0, 0, 1)
+
+		calendarDay := CalendarDay{
+			Date:   dayStart.Format(calendarDateLayout),
+			Events: []CalendarEvent{},
+		}
+
+		for _, event := range sorted {
+			if eventOnDay(event, dayStart, dayEnd) {
+				calendarDay.Events = append(calendarDay.Events, event)
+			}
+		}
+
+		retval = append(retval, calendarDay)
+	}
+
+	return retval
+}
+
+// eventOnDay returns true if any part of the event happens from dayStart up to (but not including) dayEnd.
+// Events with no duration are on the day they happen
+func eventOnDay(event CalendarEvent, dayStart, dayEnd time.Time) bool {
+	if !event.EndTime.After(event.StartTime) {
+		return !event.StartTime.Before(dayStart) && event.StartTime.Before(dayEnd)
+	}
+
+	return event.StartTime.Before(dayEnd) && event.EndTime.After(dayStart)
+}
+
+// sortCalendarEvents sorts events by when they start (then by when they end, then by summary)
+func sortCalendarEvents(events []CalendarEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].StartTime.Equal(events[j].StartTime) {
+			return events[i].StartTime.Before(events[j].StartTime)
+		}
+		if !events[i].EndTime.Equal(events[j].EndTime) {
+			return events[i].EndTime.Before(events[j].EndTime)
+		}
+		return events[i].Summary < events[j].Summary
+	})
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarRange_ValidRequests_ReturnsRange(t *testing.T) {
+	//	Arrange
+	location, _ := time.LoadLocation("America/New_York")
+	now := time.Date(2022, 6, 1, 14, 30, 0, 0, location)
+
+	tests := []struct {
+		request       CalendarRequest
+		expectedStart time.Time
+		expectedEnd   time.Time
+	}{
+		{CalendarRequest{}, time.Date(2022, 6, 1, 0, 0, 0, 0, location), time.Date(2022, 6, 1, 23, 59, 59, 0, location)},
+		{CalendarRequest{Days: 7}, time.Date(2022, 6, 1, 0, 0, 0, 0, location), time.Date(2022, 6, 7, 23, 59, 59, 0, location)},
+		{CalendarRequest{Start: "2022-06-10", End: "2022-06-12"}, time.Date(2022, 6, 10, 0, 0, 0, 0, location), time.Date(2022, 6, 12, 23, 59, 59, 0, location)},
+		{CalendarRequest{Start: "2022-06-10"}, time.Date(2022, 6, 10, 0, 0, 0, 0, location), time.Date(2022, 6, 10, 23, 59, 59, 0, location)},
+	}
+
+	for _, test := range tests {
+		//	Act
+		start, end, err := calendarRange(test.request, now, location)
+
+		//	Assert
+		if err != nil {
+			t.Errorf("Returned error and we didn't expect that: %v", err)
+		}
+
+		if !start.Equal(test.expectedStart) || !end.Equal(test.expectedEnd) {
+			t.Errorf("Expected %v to %v for %+v but got %v to %v instead", test.expectedStart, test.expectedEnd, test.request, start, end)
+		}
+	}
+}
+
+func TestCalendarRange_BadRequests_ReturnsError(t *testing.T) {
+	//	Arrange
+	now := time.Date(2022, 6, 1, 14, 30, 0, 0, time.UTC)
+
+	tests := []CalendarRequest{
+		{Start: "June 1st"},
+		{End: "2022-05-31"},
+		{End: "2022-06-03", Days: 3},
+		{Days: -1},
+		{Days: maxCalendarDays + 1},
+	}
+
+	for _, request := range tests {
+		//	Act
+		_, _, err := calendarRange(request, now, time.UTC)
+
+		//	Assert
+		if err == nil {
+			t.Errorf("Expected an error for %+v but didn't get one", request)
+		}
+	}
+}
+
+func TestGroupEventsByDay_MultiDayEvents_AppearOnEachDay(t *testing.T) {
+	//	Arrange
+	location, _ := time.LoadLocation("America/New_York")
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, location)
+	end := time.Date(2022, 6, 3, 23, 59, 59, 0, location)
+
+	events := []CalendarEvent{
+		{UID: "conference", StartTime: time.Date(2022, 6, 1, 9, 0, 0, 0, location), EndTime: time.Date(2022, 6, 2, 17, 0, 0, 0, location)},
+		{UID: "allday", StartTime: time.Date(2022, 6, 2, 0, 0, 0, 0, location), EndTime: time.Date(2022, 6, 3, 0, 0, 0, 0, location)},
+		{UID: "reminder", StartTime: time.Date(2022, 6, 3, 8, 0, 0, 0, location), EndTime: time.Date(2022, 6, 3, 8, 0, 0, 0, location)},
+		{UID: "breakfast", StartTime: time.Date(2022, 6, 1, 7, 0, 0, 0, location), EndTime: time.Date(2022, 6, 1, 8, 0, 0, 0, location)},
+	}
+
+	//	Act
+	days := groupEventsByDay(events, start, end, location)
+
+	//	Assert
+	expected := map[string][]string{
+		"2022-06-01": {"breakfast", "conference"},
+		"2022-06-02": {"conference", "allday"},
+		"2022-06-03": {"reminder"},
+	}
+
+	if len(days) != len(expected) {
+		t.Fatalf("Expected %v days but got %v instead", len(expected), len(days))
+	}
+
+	for _, day := range days {
+		uids := []string{}
+		for _, event := range day.Events {
+			uids = append(uids, event.UID)
+		}
+
+		if len(uids) != len(expected[day.Date]) {
+			t.Errorf("Expected %v on %v but got %v instead", expected[day.Date], day.Date, uids)
+			continue
+		}
+
+		for i := range uids {
+			if uids[i] != expected[day.Date][i] {
+				t.Errorf("Expected %v on %v but got %v instead", expected[day.Date], day.Date, uids)
+				break
+			}
+		}
+	}
+}
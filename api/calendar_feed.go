@@ -0,0 +1,248 @@
+package api
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/apognu/gocal"
+	"github.com/danesparza/daydash-service/internal/httpclient"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// defaultCalendarMaxBodySize is the largest iCal body we'll read when calendar.maxbodysize isn't set
+const defaultCalendarMaxBodySize = 10 * 1024 * 1024
+
+// maxParsedRanges is the most date ranges we keep parsed events for in each feed
+const maxParsedRanges = 16
+
+// defaultCalendarCacheMaxBytes is the most iCal data we keep when calendar.cache.maxbytes isn't set
+const defaultCalendarCacheMaxBytes = 64 * 1024 * 1024
+
+// calendarFeeds keeps the feeds we can ask about conditionally
+var calendarFeeds = newCalendarFeedCache()
+
+// calendarFeed is the last iCal body we got for a url, along with what we need to ask the server
+// if it has changed (and the events we've already parsed out of it)
+type calendarFeed struct {
+	body         []byte
+	etag         string
+	lastModified string
+
+	mu     sync.Mutex
+	parsed map[string][]gocal.Event // Parsed events, keyed by date range and timezone
+}
+
+// calendarFeedCache keeps the most recently used feeds, up to calendar.cache.maxbytes of iCal data
+// (the parsed events for each feed are limited by maxParsedRanges).  Feeds expire after the
+// calendar cache ttl, and the least recently used feeds are dropped first to stay under the limit
+type calendarFeedCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // Most recently used first
+	size    int64      // The total size of the cached bodies
+}
+
+// calendarFeedEntry is a single feed in the cache
+type calendarFeedEntry struct {
+	url     string
+	feed    *calendarFeed
+	expires time.Time // Zero time means the feed never expires
+}
+
+func newCalendarFeedCache() *calendarFeedCache {
+	return &calendarFeedCache{
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// get gets the feed for the url (if we have it and it hasn't expired)
+func (c *calendarFeedCache) get(url string) (*calendarFeed, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, found := c.entries[url]
+	if !found {
+		return nil, false
+	}
+
+	entry := element.Value.(*calendarFeedEntry)
+	if !entry.expires.IsZero() && !time.Now().Before(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.feed, true
+}
+
+// set stores the feed for the url (for the calendar cache ttl), dropping the least recently used
+// feeds to make room.  A feed that's larger than the whole cache isn't kept
+func (c *calendarFeedCache) set(url string, feed *calendarFeed) {
+	ttl := cacheTTL("calendar")
+	maxBytes := viper.GetInt64("calendar.cache.maxbytes")
+	if maxBytes <= 0 {
+		maxBytes = defaultCalendarCacheMaxBytes
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, found := c.entries[url]; found {
+		c.remove(element)
+	}
+
+	if ttl < 0 || int64(len(feed.body)) > maxBytes {
+		return
+	}
+
+	entry := &calendarFeedEntry{url: url, feed: feed}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	c.entries[url] = c.order.PushFront(entry)
+	c.size += int64(len(feed.body))
+
+	for c.size > maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+// delete removes the feed for the url
+func (c *calendarFeedCache) delete(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, found := c.entries[url]; found {
+		c.remove(element)
+	}
+}
+
+// remove removes an element from the cache.  The caller must hold the lock
+func (c *calendarFeedCache) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*calendarFeedEntry)
+	delete(c.entries, entry.url)
+	c.size -= int64(len(entry.feed.body))
+}
+
+// fetchCalendarFeed gets the iCal feed at the source url.  If we've fetched it before, we ask the
+// server if it has changed, and reuse what we have if it hasn't.  Bodies larger than
+// calendar.maxbodysize are rejected
+func fetchCalendarFeed(ctx context.Context, source CalendarSource) (*calendarFeed, error) {
+
+	txn := newrelic.FromContext(ctx)
+	segment := txn.StartSegment("Calendar fetchCalendarFeed")
+	defer segment.End()
+
+	//	See what we have from last time
+	previous, _ := calendarFeeds.get(source.URL)
+
+	//	First, get the ical calendar at the url given
+	clientRequest, err := http.NewRequest("GET", source.URL, nil)
+	if err != nil {
+		zlog.Errorw(
+			"problem creating request to the ical url given",
+			"calendar", source.Name,
+			"err", err,
+		)
+		return nil, newBadRequestError(fmt.Errorf("you must include a valid url param: %v", err))
+	}
+
+	//	Set our headers (and only ask for the feed if it has changed)
+	clientRequest.Header.Set("Accept", "text/calendar, */*;q=0.5")
+	if previous != nil {
+		if previous.etag != "" {
+			clientRequest.Header.Set("If-None-Match", previous.etag)
+		}
+		if previous.lastModified != "" {
+			clientRequest.Header.Set("If-Modified-Since", previous.lastModified)
+		}
+	}
+	clientRequest = newrelic.RequestWithTransactionContext(clientRequest, txn)
+
+	//	Execute the request
+	calendarDataResponse, err := ctxhttp.Do(ctx, httpclient.Default(), clientRequest)
+	if err != nil {
+		zlog.Errorw(
+			"error when sending request to get the calendar data from the url",
+			"calendar", source.Name,
+			"err", err,
+		)
+		return nil, newUpstreamError(fmt.Errorf("error when sending request to get the calendar data from the url: %w", err))
+	}
+	defer calendarDataResponse.Body.Close()
+
+	//	If it hasn't changed, use what we have
+	if calendarDataResponse.StatusCode == http.StatusNotModified && previous != nil {
+		calendarFeeds.set(source.URL, previous)
+		return previous, nil
+	}
+
+	//	If the HTTP status code indicates an error, report it and get out
+	if calendarDataResponse.StatusCode >= 400 {
+		return nil, newUpstreamStatusError(fmt.Errorf("error getting the calendar data from the url: %s", calendarDataResponse.Status), calendarDataResponse.StatusCode)
+	}
+
+	//	Read the body (but not too much of it)
+	maxBodySize := viper.GetInt64("calendar.maxbodysize")
+	if maxBodySize <= 0 {
+		maxBodySize = defaultCalendarMaxBodySize
+	}
+	body, err := io.ReadAll(io.LimitReader(calendarDataResponse.Body, maxBodySize+1))
+	if err != nil {
+		return nil, newUpstreamError(fmt.Errorf("problem reading the calendar data from the url: %w", err))
+	}
+	if int64(len(body)) > maxBodySize {
+		return nil, newUpstreamBadDataError(fmt.Errorf("the calendar data is larger than the %v byte limit", maxBodySize))
+	}
+
+	retval := &calendarFeed{
+		body:         body,
+		etag:         calendarDataResponse.Header.Get("ETag"),
+		lastModified: calendarDataResponse.Header.Get("Last-Modified"),
+		parsed:       map[string][]gocal.Event{},
+	}
+
+	//	Only keep the feed if the server told us how to ask if it has changed
+	if retval.etag != "" || retval.lastModified != "" {
+		calendarFeeds.set(source.URL, retval)
+	} else if previous != nil {
+		calendarFeeds.delete(source.URL)
+	}
+
+	return retval, nil
+}
+
+// events gets the events in the feed from start to end (parsing the feed, if we haven't already
+// for this range)
+func (f *calendarFeed) events(start, end time.Time, location *time.Location) ([]gocal.Event, error) {
+	key := fmt.Sprintf("%d|%d|%s", start.Unix(), end.Unix(), location.String())
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if events, found := f.parsed[key]; found {
+		return events, nil
+	}
+
+	events, err := GetEventsForDay(bytes.NewReader(f.body), start, end, location)
+	if err != nil {
+		return events, err
+	}
+
+	//	Don't let the parsed ranges grow forever
+	if len(f.parsed) >= maxParsedRanges {
+		f.parsed = map[string][]gocal.Event{}
+	}
+	f.parsed[key] = events
+
+	return events, nil
+}
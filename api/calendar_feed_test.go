@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestFetchCalendarFeed_NotModified_ReusesFeed(t *testing.T) {
+	//	Arrange
+	requests := 0
+	conditional := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		rw.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(rw, testCalendar("standup", "Standup", 13))
+	}))
+	defer server.Close()
+	defer calendarFeeds.delete(server.URL)
+
+	source := CalendarSource{URL: server.URL, Name: "Work"}
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2022, 6, 1, 23, 59, 59, 0, time.UTC)
+
+	//	Act
+	first, err := fetchCalendarFeed(context.Background(), source)
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	firstEvents, _ := first.events(start, end, time.UTC)
+
+	second, err := fetchCalendarFeed(context.Background(), source)
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	secondEvents, _ := second.events(start, end, time.UTC)
+
+	//	Assert
+	if requests != 2 || conditional != 1 {
+		t.Errorf("Expected 2 requests (1 conditional) but got %v (%v conditional) instead", requests, conditional)
+	}
+
+	if first != second {
+		t.Errorf("Expected the feed to be reused when it wasn't modified")
+	}
+
+	if len(firstEvents) != 1 || len(secondEvents) != 1 || &firstEvents[0] != &secondEvents[0] {
+		t.Errorf("Expected the parsed events to be reused but got %v and %v", firstEvents, secondEvents)
+	}
+}
+
+func TestFetchCalendarFeed_TooLarge_ReturnsError(t *testing.T) {
+	//	Arrange
+	viper.Set("calendar.maxbodysize", 100)
+	defer viper.Set("calendar.maxbodysize", nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(rw, strings.Repeat("X", 101))
+	}))
+	defer server.Close()
+
+	//	Act
+	_, err := fetchCalendarFeed(context.Background(), CalendarSource{URL: server.URL})
+
+	//	Assert
+	if err == nil || errorCode(err) != ErrorCodeUpstreamBadData {
+		t.Errorf("Expected an upstream bad data error but got %v instead", err)
+	}
+}
+
+func TestCalendarFeedCache_DropsLeastRecentlyUsed(t *testing.T) {
+	//	Arrange
+	viper.Set("calendar.cache.maxbytes", 10)
+	defer viper.Set("calendar.cache.maxbytes", nil)
+
+	feeds := newCalendarFeedCache()
+	feeds.set("a", &calendarFeed{body: []byte("1234")})
+	feeds.set("b", &calendarFeed{body: []byte("1234")})
+
+	//	Act
+	feeds.get("a")
+	feeds.set("c", &calendarFeed{body: []byte("1234")})
+	feeds.set("huge", &calendarFeed{body: []byte("12345678901")})
+
+	//	Assert
+	_, foundA := feeds.get("a")
+	_, foundB := feeds.get("b")
+	_, foundC := feeds.get("c")
+	_, foundHuge := feeds.get("huge")
+
+	if !foundA || foundB || !foundC || foundHuge {
+		t.Errorf("Expected only a and c to be kept but got a: %v, b: %v, c: %v, huge: %v", foundA, foundB, foundC, foundHuge)
+	}
+
+	if feeds.size != 8 {
+		t.Errorf("Expected 8 bytes cached but got %v instead", feeds.size)
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/apognu/gocal"
+	"github.com/apognu/gocal/parser"
+)
+
+// icalDatePattern matches an iCal DATE value (like 20220601)
+var icalDatePattern = regexp.MustCompile(`^\d{8}$`)
+
+// windowsTimezones maps the Windows time zone names Outlook and Exchange use in TZIDs to IANA time zones
+var windowsTimezones = map[string]string{
+	"utc":                            "UTC",
+	"eastern standard time":          "America/New_York",
+	"central standard time":          "America/Chicago",
+	"mountain standard time":         "America/Denver",
+	"us mountain standard time":      "America/Phoenix",
+	"pacific standard time":          "America/Los_Angeles",
+	"alaskan standard time":          "America/Anchorage",
+	"hawaiian standard time":         "Pacific/Honolulu",
+	"atlantic standard time":         "America/Halifax",
+	"gmt standard time":              "Europe/London",
+	"w. europe standard time":        "Europe/Berlin",
+	"romance standard time":          "Europe/Paris",
+	"central europe standard time":   "Europe/Budapest",
+	"e. europe standard time":        "Europe/Chisinau",
+	"india standard time":            "Asia/Kolkata",
+	"china standard time":            "Asia/Shanghai",
+	"tokyo standard time":            "Asia/Tokyo",
+	"aus eastern standard time":      "Australia/Sydney",
+	"new zealand standard time":      "Pacific/Auckland",
+	"e. south america standard time": "America/Sao_Paulo",
+}
+
+func init() {
+	//	Let the parser understand Outlook's time zone names (anything else falls back to the IANA name)
+	parser.TZMapper = windowsTimezone
+}
+
+// windowsTimezone gets the location for a Windows time zone name
+func windowsTimezone(name string) (*time.Location, error) {
+	if iana, found := windowsTimezones[strings.ToLower(strings.TrimSpace(name))]; found {
+		return time.LoadLocation(iana)
+	}
+	return nil, fmt.Errorf("'%s' isn't a Windows time zone name", name)
+}
+
+// normalizeEventTimes gets the start and end of an event in the given location, and whether it's an
+// all-day event.  Calendars describe times in a few different ways, and we don't care which host they
+// came from:
+//
+//   - All-day events (VALUE=DATE, or Outlook's X-MICROSOFT-CDO-ALLDAYEVENT) start at local midnight on
+//     their date and end at local midnight after their last day -- even on days that are 23 or 25 hours
+//     long because of daylight saving time
+//   - Floating times (no 'Z' and no TZID) are wall clock times, so they're read in the given location
+//   - Everything else is an exact time, and is just converted to the given location
+func normalizeEventTimes(e gocal.Event, location *time.Location) (time.Time, time.Time, bool) {
+
+	if isAllDayEvent(e) {
+		//	The parser may have moved the date (recurring instances, or midnight in another time zone).
+		//	Midnight is never more than a couple of hours off, so the date 12 hours later is the right one
+		date := e.Start.Add(12 * time.Hour)
+		start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
+		end := start.AddDate(0, 0, allDayEventDays(e))
+
+		return start, end, true
+	}
+
+	start := e.Start.In(location)
+	if isFloatingTime(e.RawStart) {
+		start = RewriteToLocal(*e.Start, location)
+	}
+
+	end := e.End.In(location)
+	switch {
+	case e.RawEnd.Value == "":
+		//	The end came from a duration
+		end = start.Add(e.End.Sub(*e.Start))
+	case isFloatingTime(e.RawEnd):
+		end = RewriteToLocal(*e.End, location)
+	}
+
+	return start, end, false
+}
+
+// isAllDayEvent returns true if the event is for whole days rather than a time
+func isAllDayEvent(e gocal.Event) bool {
+	if isDateValue(e.RawStart) {
+		return true
+	}
+
+	//	Outlook sometimes sends all-day events as midnight-to-midnight times with a flag
+	return strings.EqualFold(e.CustomAttributes["X-MICROSOFT-CDO-ALLDAYEVENT"], "TRUE")
+}
+
+// isDateValue returns true if the raw iCal value is a date (without a time)
+func isDateValue(raw gocal.RawDate) bool {
+	return strings.EqualFold(raw.Params["VALUE"], "DATE") || icalDatePattern.MatchString(raw.Value)
+}
+
+// isFloatingTime returns true if the raw iCal value is a time that isn't tied to any time zone
+func isFloatingTime(raw gocal.RawDate) bool {
+	return raw.Value != "" && !isDateValue(raw) && !strings.HasSuffix(strings.ToUpper(raw.Value), "Z") && raw.Params["TZID"] == ""
+}
+
+// allDayEventDays gets how many days an all-day event lasts (at least 1).  The end date is exclusive, but
+// some calendars use the same start and end date for a single day
+func allDayEventDays(e gocal.Event) int {
+	startDate, startErr := time.Parse("20060102", icalDatePart(e.RawStart.Value))
+	endDate, endErr := time.Parse("20060102", icalDatePart(e.RawEnd.Value))
+
+	days := 1
+	switch {
+	case startErr == nil && endErr == nil:
+		days = int(endDate.Sub(startDate).Hours()/24 + 0.5)
+	case e.Duration != nil:
+		days = int((*e.Duration + 23*time.Hour) / (24 * time.Hour))
+	}
+
+	if days < 1 {
+		days = 1
+	}
+	return days
+}
+
+// icalDatePart gets the date part of a raw iCal date or date-time (like 20220601 from 20220601T000000)
+func icalDatePart(value string) string {
+	if len(value) < 8 {
+		return value
+	}
+	return value[:8]
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apognu/gocal"
+)
+
+func TestGetCalendarEvents_ProviderFeeds_AreNormalized(t *testing.T) {
+	//	Arrange
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata/calendar")))
+	defer server.Close()
+
+	location, _ := time.LoadLocation("America/New_York")
+	pacific, _ := time.LoadLocation("America/Los_Angeles")
+
+	type expectedEvent struct {
+		start  time.Time
+		end    time.Time
+		allDay bool
+	}
+
+	tests := []struct {
+		feed     string
+		start    time.Time
+		end      time.Time
+		expected map[string]expectedEvent
+	}{
+		{
+			feed:  "google.ics",
+			start: time.Date(2022, 3, 12, 0, 0, 0, 0, location),
+			end:   time.Date(2022, 3, 14, 23, 59, 59, 0, location),
+			expected: map[string]expectedEvent{
+				"Daylight saving time starts": {time.Date(2022, 3, 13, 0, 0, 0, 0, location), time.Date(2022, 3, 14, 0, 0, 0, 0, location), true},
+				"Brunch":                      {time.Date(2022, 3, 13, 11, 0, 0, 0, location), time.Date(2022, 3, 13, 12, 0, 0, 0, location), false},
+				"Recycling day":               {time.Date(2022, 3, 13, 0, 0, 0, 0, location), time.Date(2022, 3, 14, 0, 0, 0, 0, location), true},
+			},
+		},
+		{
+			feed:  "fastmail.ics",
+			start: time.Date(2022, 3, 12, 0, 0, 0, 0, location),
+			end:   time.Date(2022, 3, 14, 23, 59, 59, 0, location),
+			expected: map[string]expectedEvent{
+				"Yoga":            {time.Date(2022, 3, 13, 9, 0, 0, 0, location), time.Date(2022, 3, 13, 10, 0, 0, 0, location), false},
+				"Spring cleaning": {time.Date(2022, 3, 13, 0, 0, 0, 0, location), time.Date(2022, 3, 14, 0, 0, 0, 0, location), true},
+				"Pi day":          {time.Date(2022, 3, 14, 0, 0, 0, 0, location), time.Date(2022, 3, 15, 0, 0, 0, 0, location), true},
+			},
+		},
+		{
+			feed:  "outlook.ics",
+			start: time.Date(2022, 11, 5, 0, 0, 0, 0, location),
+			end:   time.Date(2022, 11, 7, 23, 59, 59, 0, location),
+			expected: map[string]expectedEvent{
+				"Daylight saving time ends": {time.Date(2022, 11, 6, 0, 0, 0, 0, location), time.Date(2022, 11, 7, 0, 0, 0, 0, location), true},
+				"Out of office":             {time.Date(2022, 11, 7, 0, 0, 0, 0, location), time.Date(2022, 11, 8, 0, 0, 0, 0, location), true},
+				"Team sync":                 {time.Date(2022, 11, 7, 9, 0, 0, 0, location), time.Date(2022, 11, 7, 9, 30, 0, 0, location), false},
+			},
+		},
+		{
+			feed:  "icloud.ics",
+			start: time.Date(2022, 11, 5, 0, 0, 0, 0, location),
+			end:   time.Date(2022, 11, 7, 23, 59, 59, 0, location),
+			expected: map[string]expectedEvent{
+				"Marathon":          {time.Date(2022, 11, 6, 0, 0, 0, 0, location), time.Date(2022, 11, 7, 0, 0, 0, 0, location), true},
+				"Beach trip":        {time.Date(2022, 11, 5, 0, 0, 0, 0, location), time.Date(2022, 11, 8, 0, 0, 0, 0, location), true},
+				"Call with Grandma": {time.Date(2022, 11, 6, 9, 0, 0, 0, pacific), time.Date(2022, 11, 6, 10, 0, 0, 0, pacific), false},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		//	Act
+		events, err := getCalendarEvents(context.Background(), CalendarSource{URL: server.URL + "/" + test.feed, Name: test.feed}, test.start, test.end, location)
+
+		//	Assert
+		if err != nil {
+			t.Fatalf("Returned error and we didn't expect that (%v): %v", test.feed, err)
+		}
+
+		if len(events) != len(test.expected) {
+			t.Errorf("Expected %v events from %v but got %+v instead", len(test.expected), test.feed, events)
+		}
+
+		for _, event := range events {
+			expected, found := test.expected[event.Summary]
+			if !found {
+				t.Errorf("Didn't expect '%v' from %v", event.Summary, test.feed)
+				continue
+			}
+
+			if !event.StartTime.Equal(expected.start) || !event.EndTime.Equal(expected.end) || event.AllDay != expected.allDay {
+				t.Errorf("Expected '%v' from %v to be %v - %v (all day: %v) but got %v - %v (all day: %v) instead",
+					event.Summary, test.feed, expected.start, expected.end, expected.allDay, event.StartTime, event.EndTime, event.AllDay)
+			}
+		}
+	}
+}
+
+func TestNormalizeEventTimes_AllDayEvents_LastWholeLocalDays(t *testing.T) {
+	//	Arrange
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata/calendar")))
+	defer server.Close()
+
+	location, _ := time.LoadLocation("America/New_York")
+	start := time.Date(2022, 11, 6, 0, 0, 0, 0, location)
+
+	//	Act
+	events, err := getCalendarEvents(context.Background(), CalendarSource{URL: server.URL + "/outlook.ics"}, start, start.Add(23*time.Hour), location)
+
+	//	Assert
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	found := false
+	for _, event := range events {
+		if event.Summary != "Daylight saving time ends" {
+			continue
+		}
+		found = true
+
+		if event.EndTime.Sub(event.StartTime) != 25*time.Hour {
+			t.Errorf("Expected the all-day event on the end of daylight saving time to last 25 hours but got %v instead", event.EndTime.Sub(event.StartTime))
+		}
+	}
+
+	if !found {
+		t.Errorf("Expected the all-day event on the end of daylight saving time but got %+v instead", events)
+	}
+}
+
+func TestNormalizeEventTimes_FloatingTimes_IgnoreServerTimeZone(t *testing.T) {
+	//	Arrange
+	location, _ := time.LoadLocation("America/New_York")
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+
+	//	The parser reads floating times in the server's time zone -- pretend the server is in Tokyo
+	start := time.Date(2022, 3, 13, 11, 0, 0, 0, tokyo)
+	end := time.Date(2022, 3, 13, 12, 0, 0, 0, tokyo)
+	event := gocal.Event{
+		Start:    &start,
+		RawStart: gocal.RawDate{Value: "20220313T110000", Params: map[string]string{}},
+		End:      &end,
+		RawEnd:   gocal.RawDate{Value: "20220313T120000", Params: map[string]string{}},
+	}
+
+	//	Act
+	normalizedStart, normalizedEnd, allDay := normalizeEventTimes(event, location)
+
+	//	Assert
+	expectedStart := time.Date(2022, 3, 13, 11, 0, 0, 0, location)
+	expectedEnd := time.Date(2022, 3, 13, 12, 0, 0, 0, location)
+	if !normalizedStart.Equal(expectedStart) || !normalizedEnd.Equal(expectedEnd) || allDay {
+		t.Errorf("Expected %v - %v but got %v - %v (all day: %v) instead", expectedStart, expectedEnd, normalizedStart, normalizedEnd, allDay)
+	}
+}
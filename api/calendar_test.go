@@ -0,0 +1,233 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// testCalendar builds a simple iCal feed with a single event on June 1, 2022
+func testCalendar(uid, summary string, hour int) string {
+	return fmt.Sprintf("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//daydash//test//EN\r\n"+
+		"BEGIN:VEVENT\r\nUID:%s\r\nDTSTAMP:20220501T000000Z\r\nSUMMARY:%s\r\nDTSTART:20220601T%02d0000Z\r\nDTEND:20220601T%02d3000Z\r\nEND:VEVENT\r\n"+
+		"END:VCALENDAR\r\n", uid, summary, hour, hour)
+}
+
+func TestCalendarSources_NamesSourcesAfterHost(t *testing.T) {
+	//	Arrange
+	request := CalendarRequest{
+		CalendarURL: "https://calendar.google.com/calendar/ical/family/basic.ics",
+		Calendars: []CalendarSource{
+			{URL: "https://outlook.office365.com/owa/calendar/work.ics", Name: "Work", Color: "#3366cc"},
+			{URL: " "},
+		},
+	}
+
+	//	Act
+	sources := calendarSources(request)
+
+	//	Assert
+	if len(sources) != 2 {
+		t.Fatalf("Expected 2 sources but got %v instead", len(sources))
+	}
+
+	if sources[0].Name != "calendar.google.com" || sources[1].Name != "Work" {
+		t.Errorf("Expected the sources to be named calendar.google.com and Work but got %v and %v instead", sources[0].Name, sources[1].Name)
+	}
+}
+
+func TestGetCalendar_MultipleCalendars_MergesAndReportsErrors(t *testing.T) {
+	//	Arrange
+	family := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(rw, testCalendar("dinner", "Dinner", 22))
+	}))
+	defer family.Close()
+
+	work := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(rw, testCalendar("standup", "Standup", 13))
+	}))
+	defer work.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer broken.Close()
+
+	body, _ := json.Marshal(CalendarRequest{
+		Calendars: []CalendarSource{
+			{URL: family.URL, Name: "Family", Color: "#ff0000"},
+			{URL: work.URL, Name: "Work"},
+			{URL: broken.URL, Name: "Broken"},
+		},
+		Timezone: "America/New_York",
+		Start:    "2022-06-01",
+	})
+	req := httptest.NewRequest("POST", "/v2/calendar", bytes.NewReader(body))
+	rw := httptest.NewRecorder()
+
+	//	Act
+	Service{}.GetCalendar(rw, req)
+
+	//	Assert
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected a 200 but got %v instead: %s", rw.Code, rw.Body.String())
+	}
+
+	response := CalendarResponse{}
+	json.NewDecoder(rw.Body).Decode(&response)
+
+	if len(response.Events) != 2 {
+		t.Fatalf("Expected 2 events but got %+v instead", response.Events)
+	}
+
+	if response.Events[0].UID != "standup" || response.Events[0].Calendar != "Work" {
+		t.Errorf("Expected the work standup first but got %+v instead", response.Events[0])
+	}
+
+	if response.Events[1].Calendar != "Family" || response.Events[1].Color != "#ff0000" {
+		t.Errorf("Expected the family event to be tagged but got %+v instead", response.Events[1])
+	}
+
+	if !response.Events[0].StartTime.Equal(time.Date(2022, 6, 1, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the standup at 13:00 UTC but got %v instead", response.Events[0].StartTime)
+	}
+
+	if len(response.Errors) != 1 || response.Errors[0].Provider != "Broken" || response.Errors[0].Code != ErrorCodeUpstreamBadData {
+		t.Errorf("Expected an error for the broken calendar but got %+v instead", response.Errors)
+	}
+}
+
+func TestGetCalendar_AllCalendarsFail_ReturnsError(t *testing.T) {
+	//	Arrange
+	broken := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	body, _ := json.Marshal(CalendarRequest{
+		Calendars: []CalendarSource{{URL: broken.URL}, {URL: broken.URL + "/other"}},
+		Timezone:  "America/New_York",
+	})
+	req := httptest.NewRequest("POST", "/v2/calendar", bytes.NewReader(body))
+	rw := httptest.NewRecorder()
+
+	//	Act
+	Service{}.GetCalendar(rw, req)
+
+	//	Assert
+	response := ErrorResponse{}
+	json.NewDecoder(rw.Body).Decode(&response)
+
+	if rw.Code != http.StatusBadGateway || response.Code != ErrorCodeProvidersFailed || len(response.Providers) != 2 {
+		t.Errorf("Expected a providers_failed error for both calendars but got %v: %+v instead", rw.Code, response)
+	}
+}
+
+func TestGetCalendar_EventDetails_ArePopulated(t *testing.T) {
+	//	Arrange
+	feed := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//daydash//test//EN\r\n" +
+		"BEGIN:VEVENT\r\nUID:review\r\nDTSTAMP:20220501T000000Z\r\nSUMMARY:Design review\r\nDTSTART:20220601T140000Z\r\nDTEND:20220601T150000Z\r\n" +
+		"LOCATION:Room 4\r\nSTATUS:TENTATIVE\r\nORGANIZER;CN=Pat Smith:mailto:pat@example.com\r\nURL:https://example.com/review\r\nRRULE:FREQ=WEEKLY;COUNT=4\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:holiday\r\nDTSTAMP:20220501T000000Z\r\nSUMMARY:Day off\r\nDTSTART;VALUE=DATE:20220601\r\nDTEND;VALUE=DATE:20220602\r\nORGANIZER:mailto:boss@example.com\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:lunch\r\nDTSTAMP:20220501T000000Z\r\nSUMMARY:Lunch\r\nDTSTART:20220601T160000Z\r\nDTEND:20220601T170000Z\r\nSTATUS:CANCELLED\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(rw, feed)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		includeCancelled bool
+		expectedCount    int
+	}{
+		{false, 2},
+		{true, 3},
+	}
+
+	for _, test := range tests {
+		body, _ := json.Marshal(CalendarRequest{CalendarURL: server.URL, Timezone: "America/New_York", Start: "2022-06-01", IncludeCancelled: test.includeCancelled})
+		req := httptest.NewRequest("POST", "/v2/calendar", bytes.NewReader(body))
+		rw := httptest.NewRecorder()
+
+		//	Act
+		Service{}.GetCalendar(rw, req)
+
+		//	Assert
+		response := CalendarResponse{}
+		json.NewDecoder(rw.Body).Decode(&response)
+
+		if len(response.Events) != test.expectedCount {
+			t.Fatalf("Expected %v events (include_cancelled=%v) but got %+v instead", test.expectedCount, test.includeCancelled, response.Events)
+		}
+
+		events := map[string]CalendarEvent{}
+		for _, event := range response.Events {
+			events[event.UID] = event
+		}
+
+		review := events["review"]
+		if review.Location != "Room 4" || review.Status != "tentative" || review.Organizer != "Pat Smith" || review.URL != "https://example.com/review" || !review.Recurring || review.AllDay {
+			t.Errorf("Expected the review details to be filled in but got %+v instead", review)
+		}
+
+		holiday := events["holiday"]
+		if !holiday.AllDay || holiday.Organizer != "boss@example.com" || holiday.Recurring {
+			t.Errorf("Expected an all-day event organized by boss@example.com but got %+v instead", holiday)
+		}
+
+		if lunch, found := events["lunch"]; found && lunch.Status != "cancelled" {
+			t.Errorf("Expected lunch to be cancelled but got %v instead", lunch.Status)
+		}
+	}
+}
+
+func TestGetCalendar_CalDAVCalendar_UsesConfiguredCredentials(t *testing.T) {
+	//	Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != "REPORT" || req.Header.Get("Authorization") != "Bearer family-token" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		rw.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(rw, `<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:response><d:href>/1.ics</d:href>`+
+			`<d:propstat><d:prop><c:calendar-data>%s</c:calendar-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>`+
+			`</d:response></d:multistatus>`, testCalendar("soccer", "Soccer", 21))
+	}))
+	defer server.Close()
+
+	viper.Set("calendar.caldav", map[string]interface{}{
+		"family": map[string]interface{}{"url": server.URL, "auth": "bearer", "token": "family-token"},
+	})
+	defer viper.Set("calendar.caldav", nil)
+
+	body, _ := json.Marshal(CalendarRequest{
+		Calendars: []CalendarSource{{CalDAV: "Family"}, {CalDAV: "unknown"}},
+		Timezone:  "America/New_York",
+		Start:     "2022-06-01",
+	})
+	req := httptest.NewRequest("POST", "/v2/calendar", bytes.NewReader(body))
+	rw := httptest.NewRecorder()
+
+	//	Act
+	Service{}.GetCalendar(rw, req)
+
+	//	Assert
+	response := CalendarResponse{}
+	json.NewDecoder(rw.Body).Decode(&response)
+
+	if len(response.Events) != 1 || response.Events[0].UID != "soccer" || response.Events[0].Calendar != "Family" {
+		t.Errorf("Expected the soccer event from the Family calendar but got %+v instead", response.Events)
+	}
+
+	if len(response.Errors) != 1 || response.Errors[0].Provider != "unknown" || response.Errors[0].Code != ErrorCodeBadRequest {
+		t.Errorf("Expected a bad request error for the unknown calendar but got %+v instead", response.Errors)
+	}
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/holidays"
+	"github.com/spf13/viper"
+)
+
+// HolidayReport defines the public holidays for a country (or region) in a year
+type HolidayReport struct {
+	Country  string        `json:"country"`
+	Region   string        `json:"region,omitempty"`
+	Year     int           `json:"year"`
+	Holidays []HolidayItem `json:"holidays"` // Sorted by the date they're observed
+}
+
+// HolidayItem defines a single public holiday
+type HolidayItem struct {
+	Name     string `json:"name"`
+	Date     string `json:"date"`     // The date of the holiday (YYYY-MM-DD)
+	Observed string `json:"observed"` // The date the holiday is observed (YYYY-MM-DD).  Different from the date when it falls on a weekend
+	Country  string `json:"country"`
+	Region   string `json:"region,omitempty"` // Set for regional holidays
+}
+
+// CalendarHolidays describes the public holidays to include in a calendar request
+type CalendarHolidays struct {
+	Country string `json:"country"` // Country code (like US).  Defaults to the configured country
+	Region  string `json:"region"`  // Optional region code (like GA) to include regional holidays
+	Name    string `json:"name"`    // Optional calendar name for the holidays (defaults to Holidays)
+	Color   string `json:"color"`   // Optional display color (like '#3366cc')
+}
+
+// holidayRules is the last rules file we loaded.  It's only read again when it changes
+var holidayRules struct {
+	mu        sync.Mutex
+	path      string
+	modTime   time.Time
+	calendars []holidays.Calendar // The last rules that loaded successfully
+	err       error               // The problem loading the file at modTime (if there was one)
+}
+
+// LoadHolidayRules loads the configured holiday rules file.  It's called when the service starts,
+// so a bad rules file is reported then (and not just when somebody asks for holidays)
+func LoadHolidayRules() {
+	path := viper.GetString("holidays.rules")
+	if path == "" {
+		return
+	}
+
+	calendars, err := getHolidayRules(path)
+	if err != nil {
+		return
+	}
+
+	zlog.Infow(
+		"loaded the holiday rules",
+		"path", path,
+		"calendars", len(calendars),
+	)
+}
+
+// getHolidayCalendars gets the built-in holiday calendars, plus any from the configured rules file.
+// A broken rules file doesn't stop the built-in calendars from working (getHolidayRules logs it)
+func getHolidayCalendars() []holidays.Calendar {
+	retval := holidays.Builtin()
+
+	path := viper.GetString("holidays.rules")
+	if path == "" {
+		return retval
+	}
+
+	calendars, _ := getHolidayRules(path)
+	return append(retval, calendars...)
+}
+
+// getHolidayRules gets the calendars in the rules file at the path.  The file is only read again
+// if it has changed since we last read it (so changes don't need a restart).  If the file can't be
+// loaded, the error is logged and the last rules that loaded successfully are returned with it
+func getHolidayRules(path string) ([]holidays.Calendar, error) {
+	holidayRules.mu.Lock()
+	defer holidayRules.mu.Unlock()
+
+	info, err := os.Stat(path)
+	if err == nil && path == holidayRules.path && info.ModTime().Equal(holidayRules.modTime) {
+		return holidayRules.calendars, holidayRules.err
+	}
+
+	//	Rules from a different file aren't a fallback for this one
+	if path != holidayRules.path {
+		holidayRules.calendars = nil
+	}
+	holidayRules.path = path
+	holidayRules.modTime = time.Time{}
+
+	calendars := []holidays.Calendar{}
+	if err == nil {
+		holidayRules.modTime = info.ModTime()
+		calendars, err = holidays.LoadRules(path)
+	}
+	holidayRules.err = err
+
+	if err != nil {
+		zlog.Errorw(
+			"problem loading the holiday rules -- using the last rules that loaded",
+			"path", path,
+			"calendars", len(holidayRules.calendars),
+			"error", err,
+		)
+		return holidayRules.calendars, err
+	}
+
+	holidayRules.calendars = calendars
+
+	return calendars, nil
+}
+
+// findHolidayCalendars gets the holiday calendars for a country (the configured country if it's blank)
+// and region.  It's a bad request if there aren't any
+func findHolidayCalendars(country, region string) ([]holidays.Calendar, string, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" {
+		country = strings.ToUpper(viper.GetString("holidays.country"))
+	}
+	if country == "" {
+		return nil, country, newBadRequestError(fmt.Errorf("you must include a country"))
+	}
+
+	retval := holidays.Find(getHolidayCalendars(), country, strings.TrimSpace(region))
+	if len(retval) == 0 {
+		return nil, country, newBadRequestError(fmt.Errorf("there are no holiday rules for country '%s'", country))
+	}
+
+	return retval, country, nil
+}
+
+// newHolidayItem formats a holiday for a response
+func newHolidayItem(holiday holidays.Holiday) HolidayItem {
+	return HolidayItem{
+		Name:     holiday.Name,
+		Date:     holiday.Date.Format(calendarDateLayout),
+		Observed: holiday.Observed.Format(calendarDateLayout),
+		Country:  holiday.Country,
+		Region:   holiday.Region,
+	}
+}
+
+// GetHolidays godoc
+// @Summary Gets the public holidays for a country (or region) and year
+// @Description Gets the public holidays (and the dates they're observed) for a country and year.  Calculated locally from the built-in rules (US federal holidays) and the configured rules file
+// @Tags dashboard
+// @Produce  json
+// @Param country query string false "Country code (like US).  Defaults to the configured country"
+// @Param region query string false "Region code (like GA) to include regional holidays"
+// @Param year query int false "The year (defaults to the current year)"
+// @Success 200 {object} api.HolidayReport
+// @Failure 400 {object} api.ErrorResponse
+// @Router /holidays [get]
+func (s Service) GetHolidays(rw http.ResponseWriter, req *http.Request) {
+
+	//	Parse the request
+	values := req.URL.Query()
+	region := strings.ToUpper(strings.TrimSpace(values.Get("region")))
+
+	year := time.Now().Year()
+	if raw := values.Get("year"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < holidays.MinYear || parsed > holidays.MaxYear {
+			sendErrorResponse(rw, newBadRequestError(fmt.Errorf("year must be a number from %v to %v", holidays.MinYear, holidays.MaxYear)))
+			return
+		}
+		year = parsed
+	}
+
+	calendars, country, err := findHolidayCalendars(values.Get("country"), region)
+	if err != nil {
+		sendErrorResponse(rw, err)
+		return
+	}
+
+	retval := HolidayReport{
+		Country:  country,
+		Region:   region,
+		Year:     year,
+		Holidays: []HolidayItem{},
+	}
+	for _, holiday := range holidays.ForYear(calendars, year) {
+		retval.Holidays = append(retval.Holidays, newHolidayItem(holiday))
+	}
+
+	//	Serialize to JSON & return the response:
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(rw).Encode(retval)
+}
+
+// getHolidayEvents gets the holidays from start to end as all-day calendar events.  A holiday that's
+// observed on a different day shows up on both days (the observed day is marked as observed)
+func getHolidayEvents(calendars []holidays.Calendar, options CalendarHolidays, start, end time.Time, location *time.Location) []CalendarEvent {
+	retval := []CalendarEvent{}
+
+	name := options.Name
+	if name == "" {
+		name = "Holidays"
+	}
+
+	type holidayDay struct {
+		date    time.Time
+		summary string
+	}
+
+	for _, holiday := range holidays.Between(calendars, start, end) {
+		days := []holidayDay{{holiday.Date, holiday.Name}}
+		if !holiday.Observed.Equal(holiday.Date) {
+			days = append(days, holidayDay{holiday.Observed, holiday.Name + " (observed)"})
+		}
+
+		for _, day := range days {
+			dayStart := time.Date(day.date.Year(), day.date.Month(), day.date.Day(), 0, 0, 0, 0, location)
+			event := CalendarEvent{
+				UID:       fmt.Sprintf("holiday:%s:%s:%s", holiday.Country, day.date.Format(calendarDateLayout), day.summary),
+				Summary:   day.summary,
+				StartTime: dayStart,
+				EndTime:   dayStart.AddDate(0, 0, 1),
+				AllDay:    true,
+				Calendar:  name,
+				Color:     options.Color,
+			}
+
+			if eventOnDay(event, start, end.Add(time.Second)) {
+				retval = append(retval, event)
+			}
+		}
+	}
+
+	return retval
+}
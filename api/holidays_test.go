@@ -0,0 +1,250 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestGetHolidays_USFederal_ReturnsYear(t *testing.T) {
+	//	Arrange
+	req := httptest.NewRequest("GET", "/v2/holidays?country=us&year=2022", nil)
+	rw := httptest.NewRecorder()
+
+	//	Act
+	Service{}.GetHolidays(rw, req)
+
+	//	Assert
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected a 200 but got %v instead: %s", rw.Code, rw.Body.String())
+	}
+
+	response := HolidayReport{}
+	json.NewDecoder(rw.Body).Decode(&response)
+
+	if response.Country != "US" || response.Year != 2022 || len(response.Holidays) != 11 {
+		t.Fatalf("Expected 11 US holidays for 2022 but got %+v instead", response)
+	}
+
+	first := response.Holidays[0]
+	if first.Name != "New Year's Day" || first.Date != "2022-01-01" || first.Observed != "2021-12-31" {
+		t.Errorf("Expected New Year's Day 2022 observed on 2021-12-31 but got %+v instead", first)
+	}
+}
+
+func TestGetHolidays_BadRequests_ReturnErrors(t *testing.T) {
+	//	Arrange
+	tests := []string{
+		"/v2/holidays?country=ZZ",
+		"/v2/holidays?country=US&year=later",
+		"/v2/holidays?country=US&year=1200",
+	}
+
+	for _, url := range tests {
+		req := httptest.NewRequest("GET", url, nil)
+		rw := httptest.NewRecorder()
+
+		//	Act
+		Service{}.GetHolidays(rw, req)
+
+		//	Assert
+		if rw.Code != http.StatusBadRequest {
+			t.Errorf("Expected a 400 for %v but got %v instead", url, rw.Code)
+		}
+	}
+}
+
+func TestGetHolidays_RulesFile_AddsRegionalHolidays(t *testing.T) {
+	//	Arrange
+	path := filepath.Join(t.TempDir(), "holidays.yaml")
+	os.WriteFile(path, []byte(`calendars:
+  - country: US
+    region: GA
+    rules:
+      - { name: State Holiday, type: fixed, month: 4, day: 26 }
+`), 0644)
+	viper.Set("holidays.rules", path)
+	defer viper.Set("holidays.rules", nil)
+
+	req := httptest.NewRequest("GET", "/v2/holidays?country=US&region=ga&year=2022", nil)
+	rw := httptest.NewRecorder()
+
+	//	Act
+	Service{}.GetHolidays(rw, req)
+
+	//	Assert
+	response := HolidayReport{}
+	json.NewDecoder(rw.Body).Decode(&response)
+
+	found := false
+	for _, holiday := range response.Holidays {
+		if holiday.Name == "State Holiday" && holiday.Region == "GA" && holiday.Date == "2022-04-26" {
+			found = true
+		}
+	}
+
+	if len(response.Holidays) != 12 || !found {
+		t.Errorf("Expected the federal holidays plus the Georgia state holiday but got %+v instead", response.Holidays)
+	}
+}
+
+func TestGetCalendar_Holidays_AreMergedAsAllDayEvents(t *testing.T) {
+	//	Arrange
+	body, _ := json.Marshal(CalendarRequest{
+		Timezone: "America/New_York",
+		Start:    "2022-07-01",
+		Days:     7,
+		Holidays: &CalendarHolidays{Country: "US", Color: "#cc0000"},
+	})
+	req := httptest.NewRequest("POST", "/v2/calendar", bytes.NewReader(body))
+	rw := httptest.NewRecorder()
+
+	//	Act
+	Service{}.GetCalendar(rw, req)
+
+	//	Assert
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected a 200 but got %v instead: %s", rw.Code, rw.Body.String())
+	}
+
+	response := CalendarResponse{}
+	json.NewDecoder(rw.Body).Decode(&response)
+
+	if len(response.Events) != 1 {
+		t.Fatalf("Expected Independence Day but got %+v instead", response.Events)
+	}
+
+	event := response.Events[0]
+	if event.Summary != "Independence Day" || !event.AllDay || event.Calendar != "Holidays" || event.Color != "#cc0000" {
+		t.Errorf("Expected an all-day Independence Day event but got %+v instead", event)
+	}
+
+	if response.Days[3].Date != "2022-07-04" || len(response.Days[3].Events) != 1 {
+		t.Errorf("Expected Independence Day on 2022-07-04 but got %+v instead", response.Days[3])
+	}
+}
+
+func TestGetCalendar_ObservedHoliday_ShowsOnBothDays(t *testing.T) {
+	//	Arrange
+	viper.Set("holidays.country", "US")
+	defer viper.Set("holidays.country", nil)
+
+	body, _ := json.Marshal(CalendarRequest{
+		Timezone: "America/Chicago",
+		Start:    "2022-12-31",
+		Days:     1,
+		Holidays: &CalendarHolidays{},
+	})
+	req := httptest.NewRequest("POST", "/v2/calendar", bytes.NewReader(body))
+	rw := httptest.NewRecorder()
+
+	//	Act
+	Service{}.GetCalendar(rw, req)
+
+	//	Assert
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected a 200 but got %v instead: %s", rw.Code, rw.Body.String())
+	}
+
+	response := CalendarResponse{}
+	json.NewDecoder(rw.Body).Decode(&response)
+
+	//	Christmas 2022 is a Sunday, and New Year's Day 2023 is observed on Monday -- nothing on Saturday the 31st
+	if len(response.Events) != 0 {
+		t.Errorf("Expected no holidays on 2022-12-31 but got %+v instead", response.Events)
+	}
+
+	body, _ = json.Marshal(CalendarRequest{Timezone: "America/Chicago", Start: "2023-01-01", Days: 2, Holidays: &CalendarHolidays{}})
+	req = httptest.NewRequest("POST", "/v2/calendar", bytes.NewReader(body))
+	rw = httptest.NewRecorder()
+	Service{}.GetCalendar(rw, req)
+
+	response = CalendarResponse{}
+	json.NewDecoder(rw.Body).Decode(&response)
+
+	if len(response.Events) != 2 || response.Events[0].Summary != "New Year's Day" || response.Events[1].Summary != "New Year's Day (observed)" {
+		t.Errorf("Expected New Year's Day and the observed day but got %+v instead", response.Events)
+	}
+}
+
+func TestGetHolidayRules_OnlyRereadsChangedFile(t *testing.T) {
+	//	Arrange
+	path := filepath.Join(t.TempDir(), "holidays.yaml")
+	modTime := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	writeRules := func(name string, modTime time.Time) {
+		os.WriteFile(path, []byte(`calendars:
+  - country: US
+    region: GA
+    rules:
+      - { name: `+name+`, type: fixed, month: 4, day: 26 }
+`), 0644)
+		os.Chtimes(path, modTime, modTime)
+	}
+
+	//	Act
+	writeRules("First", modTime)
+	first, err := getHolidayRules(path)
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	writeRules("Unchanged", modTime)
+	unchanged, _ := getHolidayRules(path)
+
+	writeRules("Second", modTime.Add(time.Minute))
+	second, _ := getHolidayRules(path)
+
+	//	Assert
+	if first[0].Rules[0].Name != "First" || unchanged[0].Rules[0].Name != "First" {
+		t.Errorf("Expected the rules to be reused while the file's modification time is the same but got %v and %v instead", first[0].Rules[0].Name, unchanged[0].Rules[0].Name)
+	}
+
+	if second[0].Rules[0].Name != "Second" {
+		t.Errorf("Expected the rules to be read again once the file changed but got %v instead", second[0].Rules[0].Name)
+	}
+}
+
+func TestGetHolidayCalendars_BadRulesFile_KeepsLastRulesAndBuiltins(t *testing.T) {
+	//	Arrange
+	path := filepath.Join(t.TempDir(), "holidays.yaml")
+	modTime := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	viper.Set("holidays.rules", path)
+	defer viper.Set("holidays.rules", nil)
+
+	os.WriteFile(path, []byte(`calendars:
+  - country: US
+    region: GA
+    rules:
+      - { name: Confederate Memorial Day, type: fixed, month: 4, day: 26 }
+`), 0644)
+	os.Chtimes(path, modTime, modTime)
+	loaded := len(getHolidayCalendars())
+
+	os.WriteFile(path, []byte("calendars: [this isn't valid"), 0644)
+	os.Chtimes(path, modTime.Add(time.Minute), modTime.Add(time.Minute))
+
+	//	Act
+	rules, err := getHolidayRules(path)
+	calendars := getHolidayCalendars()
+
+	//	Assert
+	if err == nil {
+		t.Errorf("Expected an error for the broken rules file but didn't get one")
+	}
+
+	if len(rules) != 1 || rules[0].Rules[0].Name != "Confederate Memorial Day" {
+		t.Errorf("Expected the last rules that loaded but got %+v instead", rules)
+	}
+
+	if len(calendars) != loaded {
+		t.Errorf("Expected %v calendars (the built-in calendars and the last rules) but got %v instead", loaded, len(calendars))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"image/color"
 	"image/jpeg"
 	"net/http"
@@ -36,6 +37,8 @@ type MapImageResponse struct {
 // @Success 200 {object} api.MapImageResponse
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
+// @Failure 503 {object} api.ErrorResponse
+// @Failure 504 {object} api.ErrorResponse
 // @Router /mapimage [post]
 func (s Service) GetMapImageForCoordinates(rw http.ResponseWriter, req *http.Request) {
 
@@ -51,7 +54,7 @@ func (s Service) GetMapImageForCoordinates(rw http.ResponseWriter, req *http.Req
 			"error", err,
 		)
 		txn.NoticeError(err)
-		sendErrorResponse(rw, err, http.StatusBadRequest)
+		sendErrorResponse(rw, newBadRequestError(err))
 		return
 	}
 
@@ -90,14 +93,25 @@ func (s Service) GetMapImageForCoordinates(rw http.ResponseWriter, req *http.Req
 			"lat", lat,
 			"long", long,
 			"zoom", zoom,
+			"error", err,
 		)
 		txn.NoticeError(err)
+		sendErrorResponse(rw, newUpstreamError(fmt.Errorf("error rendering map image: %w", err)))
 		return
 	}
 
 	//	Encode to jpg
 	buffer := new(bytes.Buffer)
-	jpeg.Encode(buffer, img, nil)
+	err = jpeg.Encode(buffer, img, nil)
+	if err != nil {
+		zlog.Errorw(
+			"error encoding map image",
+			"error", err,
+		)
+		txn.NoticeError(err)
+		sendErrorResponse(rw, newInternalError(fmt.Errorf("error encoding map image: %v", err)))
+		return
+	}
 
 	//	Encode the jpeg to base64
 	retval.Image = base64.StdEncoding.EncodeToString(buffer.Bytes())
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media types the service can respond with
+const (
+	MediaTypeJSON = "application/json"
+	MediaTypeCAP  = "application/cap+xml"
+	MediaTypeAtom = "application/atom+xml"
+)
+
+// acceptRange is a single media range from an Accept header
+type acceptRange struct {
+	mediaType string  // Like 'application/json', 'application/*' or '*/*'
+	quality   float64 // How much the client wants it (0 means 'not at all')
+}
+
+// negotiateContentType picks the offered media type the client would most like, based on the request's
+// Accept header.  When the client likes more than one equally (or doesn't send an Accept header), the
+// earliest offer wins.  If the client won't accept any of the offers, an empty string is returned
+func negotiateContentType(req *http.Request, offers ...string) string {
+	accept := strings.Join(req.Header.Values("Accept"), ",")
+	if strings.TrimSpace(accept) == "" && len(offers) > 0 {
+		return offers[0]
+	}
+
+	ranges := parseAccept(accept)
+
+	retval := ""
+	best := 0.0
+	for _, offer := range offers {
+		if quality := acceptQuality(ranges, offer); quality > best {
+			retval = offer
+			best = quality
+		}
+	}
+
+	return retval
+}
+
+// parseAccept parses an Accept header into its media ranges.  Ranges that can't be parsed are skipped
+func parseAccept(accept string) []acceptRange {
+	retval := []acceptRange{}
+
+	for _, part := range strings.Split(accept, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, found := params["q"]; found {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		retval = append(retval, acceptRange{mediaType: mediaType, quality: quality})
+	}
+
+	return retval
+}
+
+// acceptQuality gets how much the client wants the given media type.  The most specific matching
+// range is used (so 'application/json' beats 'application/*', which beats '*/*')
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	retval := 0.0
+	specificity := -1
+
+	mainType := strings.SplitN(mediaType, "/", 2)[0]
+	for _, r := range ranges {
+		matched := -1
+		switch {
+		case r.mediaType == mediaType:
+			matched = 2
+		case r.mediaType == mainType+"/*":
+			matched = 1
+		case r.mediaType == "*/*":
+			matched = 0
+		}
+
+		if matched > specificity {
+			specificity = matched
+			retval = r.quality
+		}
+	}
+
+	return retval
+}
+
+// writeXMLResponse serializes the value to XML (with the XML declaration) and sends it with the given media type
+func writeXMLResponse(rw http.ResponseWriter, mediaType string, value interface{}) {
+	rw.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+	io.WriteString(rw, xml.Header)
+
+	encoder := xml.NewEncoder(rw)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		zlog.Errorw(
+			"problem encoding XML response",
+			"mediatype", mediaType,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateContentType_PicksBestOffer(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{"", MediaTypeJSON},
+		{"*/*", MediaTypeJSON},
+		{"application/cap+xml", MediaTypeCAP},
+		{"application/atom+xml, application/json;q=0.5", MediaTypeAtom},
+		{"application/json;q=0.1, application/*", MediaTypeCAP},
+		{"application/*, application/json;q=0", MediaTypeCAP},
+		{"text/html", ""},
+		{"text/html, */*;q=0.1", MediaTypeJSON},
+	}
+
+	for _, test := range tests {
+		//	Arrange
+		req := httptest.NewRequest("POST", "/v2/alerts", nil)
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+
+		//	Act
+		contentType := negotiateContentType(req, MediaTypeJSON, MediaTypeCAP, MediaTypeAtom)
+
+		//	Assert
+		if contentType != test.expected {
+			t.Errorf("Expected '%v' for Accept '%v' but got '%v' instead", test.expected, test.accept, contentType)
+		}
+	}
+}
@@ -54,6 +54,8 @@ type NewsService interface {
 // @Success 200 {object} api.NewsReport
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
+// @Failure 503 {object} api.ErrorResponse
+// @Failure 504 {object} api.ErrorResponse
 // @Router /news [get]
 func (s Service) GetNewsReport(rw http.ResponseWriter, req *http.Request) {
 
@@ -68,7 +70,8 @@ func (s Service) GetNewsReport(rw http.ResponseWriter, req *http.Request) {
 	newsItems, err := news.GetRecentNewsStories(req.Context(), 6)
 	if err != nil {
 		txn.NoticeError(err)
-		sendErrorResponse(rw, err, http.StatusBadRequest)
+		sendErrorResponse(rw, newUpstreamError(err))
+		return
 	}
 
 	//	Spit out what we know:
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/httpclient"
+	"github.com/danesparza/daydash-service/internal/pointstore"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// openPointStore opens the on-disk points store.  It's only held open for a single operation, so the
+// 'points' commands can use it while the service is running.  If it can't be opened we carry on
+// without it -- lookups just go to the NWS points service
+func openPointStore() *pointstore.Store {
+	path := viper.GetString("nws.points.path")
+	if path == "" {
+		return nil
+	}
+
+	store, err := pointstore.Open(path, time.Second)
+	if err != nil {
+		zlog.Errorw(
+			"problem opening the NWS points store -- continuing without it",
+			"path", path,
+			"error", err,
+		)
+		return nil
+	}
+
+	return store
+}
+
+// getNWSPoints gets the NWS points information for the given lat/long.  The points response
+// describes the forecast office, grid, zones and forecast urls for the location.  It hardly
+// ever changes, so it's kept in memory and on disk
+func getNWSPoints(ctx context.Context, lat, long string) (NWSPointsResponse, error) {
+	cacheKey := fmt.Sprintf("nwspoints:%s", coordinateKey(lat, long))
+	cached, _, err := responseCache.GetOrLoad(cacheKey, nwsPointsMemoryTTL(), func() (interface{}, error) {
+		loadCtx, cancel := loaderContext(ctx)
+		defer cancel()
+		return loadNWSPoints(loadCtx, lat, long)
+	})
+	if err != nil {
+		return NWSPointsResponse{}, err
+	}
+
+	return cached.(NWSPointsResponse), nil
+}
+
+// nwsPointsMemoryTTL gets how long NWS points data is kept in memory.  It's never longer than the
+// on-disk refresh interval (nws.points.refresh) -- otherwise the stored data would never be refreshed
+func nwsPointsMemoryTTL() time.Duration {
+	ttl := cacheTTL("nwspoints")
+	refresh := viper.GetDuration("nws.points.refresh")
+	if refresh > 0 && ttl >= 0 && (ttl == 0 || ttl > refresh) {
+		return refresh
+	}
+
+	return ttl
+}
+
+// loadNWSPoints gets the NWS points information from the on-disk store.  If it's not there (or it's
+// older than the refresh interval) we fetch it from the NWS points service and store it.  If the
+// NWS points service fails, an old entry is better than nothing
+func loadNWSPoints(ctx context.Context, lat, long string) (NWSPointsResponse, error) {
+
+	//	See what we have stored
+	key := coordinateKey(lat, long)
+	stored, entry, found := getStoredNWSPoints(key)
+
+	//	If it's fresh enough, use it
+	refresh := viper.GetDuration("nws.points.refresh")
+	if found && (refresh <= 0 || entry.Age() < refresh) {
+		return stored, nil
+	}
+
+	//	Otherwise, go get it
+	retval, err := fetchNWSPoints(ctx, lat, long)
+	if err != nil {
+		if found {
+			zlog.Warnw(
+				"problem refreshing NWS points data -- using the stored data",
+				"key", key,
+				"fetchedAt", entry.FetchedAt,
+				"error", err,
+			)
+			return stored, nil
+		}
+		return retval, err
+	}
+
+	//	Store it for next time
+	saveNWSPoints(key, retval)
+
+	return retval, nil
+}
+
+// getStoredNWSPoints gets the NWS points information for the given key from the on-disk store.
+// The bool is false if there's no usable entry
+func getStoredNWSPoints(key string) (NWSPointsResponse, pointstore.Entry, bool) {
+	retval := NWSPointsResponse{}
+
+	store := openPointStore()
+	if store == nil {
+		return retval, pointstore.Entry{}, false
+	}
+	defer store.Close()
+
+	entry, found, err := store.Get(key)
+	if err != nil {
+		zlog.Errorw(
+			"problem reading from the NWS points store",
+			"key", key,
+			"error", err,
+		)
+		return retval, entry, false
+	}
+	if !found {
+		return retval, entry, false
+	}
+
+	if err := json.Unmarshal(entry.Data, &retval); err != nil || len(retval.Geometry.Coordinates) < 2 {
+		return retval, entry, false
+	}
+
+	return retval, entry, true
+}
+
+// saveNWSPoints saves the NWS points information for the given key to the on-disk store
+func saveNWSPoints(key string, points NWSPointsResponse) {
+	store := openPointStore()
+	if store == nil {
+		return
+	}
+	defer store.Close()
+
+	data, err := json.Marshal(points)
+	if err == nil {
+		err = store.Put(key, data)
+	}
+	if err != nil {
+		zlog.Errorw(
+			"problem saving to the NWS points store",
+			"key", key,
+			"error", err,
+		)
+	}
+}
+
+// fetchNWSPoints calls the NWS points service for the given lat/long
+func fetchNWSPoints(ctx context.Context, lat, long string) (NWSPointsResponse, error) {
+
+	txn := newrelic.FromContext(ctx)
+	segment := txn.StartSegment("NWS fetchNWSPoints")
+	defer segment.End()
+
+	//	Our return value
+	retval := NWSPointsResponse{}
+
+	pointsUrl := fmt.Sprintf("https://api.weather.gov/points/%s,%s", lat, long)
+	clientRequest, err := http.NewRequest("GET", pointsUrl, nil)
+	if err != nil {
+		zlog.Errorw(
+			"problem creating request to the NWS points service",
+			"error", err,
+		)
+		return retval, newInternalError(fmt.Errorf("problem creating request to the NWS points service: %v", err))
+	}
+
+	//	Set our headers
+	clientRequest.Header.Set("Content-Type", "application/geo+json; charset=UTF-8")
+	clientRequest = newrelic.RequestWithTransactionContext(clientRequest, txn)
+
+	//	Execute the request
+	pointClientResponse, err := ctxhttp.Do(ctx, httpclient.Default(), clientRequest)
+	if err != nil {
+		zlog.Errorw(
+			"error when sending request to the NWS points service",
+			"error", err,
+		)
+		return retval, newUpstreamError(fmt.Errorf("error when sending request to the NWS points service: %w", err))
+	}
+	defer pointClientResponse.Body.Close()
+
+	//	If the HTTP status code indicates an error, report it and get out
+	if pointClientResponse.StatusCode >= 400 {
+		return retval, newUpstreamStatusError(fmt.Errorf("error getting information from the NWS points service: %s", pointClientResponse.Status), pointClientResponse.StatusCode)
+	}
+
+	//	Decode the response:
+	err = json.NewDecoder(pointClientResponse.Body).Decode(&retval)
+	if err != nil {
+		zlog.Errorw(
+			"problem decoding the response from the NWS points service",
+			"error", err,
+		)
+		return retval, newUpstreamBadDataError(fmt.Errorf("problem decoding the response from the NWS points service: %v", err))
+	}
+
+	//	Make sure we have coordinates we can use
+	if len(retval.Geometry.Coordinates) < 2 {
+		return retval, newUpstreamBadDataError(fmt.Errorf("the NWS points service didn't return coordinates for %s,%s", lat, long))
+	}
+
+	return retval, nil
+}
+
+// NWSPointsList is the list of NWS points lookups in the on-disk store
+type NWSPointsList struct {
+	Path    string           `json:"path"`    // Where the store is
+	Entries []NWSPointsEntry `json:"entries"` // The stored lookups
+}
+
+// NWSPointsEntry is a single stored NWS points lookup
+type NWSPointsEntry struct {
+	Key       string    `json:"key"`       // The (rounded) coordinates for the lookup
+	FetchedAt time.Time `json:"fetchedAt"` // When the lookup was fetched from the NWS points service
+}
+
+// NWSPointsPurgeResult describes the NWS points lookups that were purged
+type NWSPointsPurgeResult struct {
+	Removed int `json:"removed"` // How many lookups were removed from the on-disk store
+	Cleared int `json:"cleared"` // How many lookups were cleared from memory
+}
+
+// ListNWSPoints godoc
+// @Summary Lists the stored NWS points lookups
+// @Description Lists the NWS points lookups in the on-disk store.  Requires the admin token (server.admintoken) as a bearer token
+// @Tags admin
+// @Produce  json
+// @Success 200 {object} api.NWSPointsList
+// @Failure 401 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /nwspoints [get]
+func (s Service) ListNWSPoints(rw http.ResponseWriter, req *http.Request) {
+
+	if err := checkAdminToken(req); err != nil {
+		sendErrorResponse(rw, err)
+		return
+	}
+
+	store := openPointStore()
+	if store == nil {
+		sendErrorResponse(rw, newInternalError(fmt.Errorf("the NWS points store isn't available")))
+		return
+	}
+	defer store.Close()
+
+	entries, err := store.List()
+	if err != nil {
+		sendErrorResponse(rw, newInternalError(err))
+		return
+	}
+
+	retval := NWSPointsList{
+		Path:    viper.GetString("nws.points.path"),
+		Entries: []NWSPointsEntry{},
+	}
+	for _, entry := range entries {
+		retval.Entries = append(retval.Entries, NWSPointsEntry{Key: entry.Key, FetchedAt: entry.FetchedAt})
+	}
+
+	//	Serialize to JSON & return the response:
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(rw).Encode(retval)
+}
+
+// PurgeNWSPoints godoc
+// @Summary Purges the stored NWS points lookups
+// @Description Removes the NWS points lookups from the on-disk store (all of them, unless olderthan is used) and clears them from memory.  Requires the admin token (server.admintoken) as a bearer token
+// @Tags admin
+// @Produce  json
+// @Param olderthan query string false "Only remove lookups fetched longer ago than this (like 720h)"
+// @Success 200 {object} api.NWSPointsPurgeResult
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 401 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /nwspoints [delete]
+func (s Service) PurgeNWSPoints(rw http.ResponseWriter, req *http.Request) {
+
+	if err := checkAdminToken(req); err != nil {
+		sendErrorResponse(rw, err)
+		return
+	}
+
+	olderThan := time.Duration(0)
+	if value := req.URL.Query().Get("olderthan"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			sendErrorResponse(rw, newBadRequestError(fmt.Errorf("olderthan should be a duration like 720h")))
+			return
+		}
+		olderThan = parsed
+	}
+
+	retval := NWSPointsPurgeResult{}
+
+	store := openPointStore()
+	if store == nil {
+		sendErrorResponse(rw, newInternalError(fmt.Errorf("the NWS points store isn't available")))
+		return
+	}
+	defer store.Close()
+
+	removed, err := store.Purge(olderThan)
+	if err != nil {
+		sendErrorResponse(rw, newInternalError(err))
+		return
+	}
+	retval.Removed = removed
+
+	//	Anything left on disk is reloaded from there the next time it's needed
+	retval.Cleared = responseCache.DeletePrefix("nwspoints:")
+
+	zlog.Infow(
+		"purged the NWS points lookups",
+		"olderThan", olderThan,
+		"removed", retval.Removed,
+		"cleared", retval.Cleared,
+	)
+
+	//	Serialize to JSON & return the response:
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(rw).Encode(retval)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/pointstore"
+	"github.com/spf13/viper"
+)
+
+func TestPurgeNWSPoints_ClearsStoreAndMemory(t *testing.T) {
+	//	Arrange
+	viper.Set("nws.points.path", filepath.Join(t.TempDir(), "nwspoints.db"))
+	defer viper.Set("nws.points.path", nil)
+	viper.Set("server.admintoken", "secret")
+	defer viper.Set("server.admintoken", nil)
+
+	store, err := pointstore.Open(viper.GetString("nws.points.path"), 0)
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	store.Put("33.98,-83.96", []byte(`{}`))
+	store.Close()
+
+	responseCache.Set("nwspoints:33.98,-83.96", NWSPointsResponse{}, 0)
+	defer responseCache.Delete("nwspoints:33.98,-83.96")
+
+	req := httptest.NewRequest("DELETE", "/v2/nwspoints", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rw := httptest.NewRecorder()
+
+	//	Act
+	Service{}.PurgeNWSPoints(rw, req)
+
+	//	Assert
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected a 200 but got %v instead: %s", rw.Code, rw.Body.String())
+	}
+
+	result := NWSPointsPurgeResult{}
+	json.NewDecoder(rw.Body).Decode(&result)
+	if result.Removed != 1 || result.Cleared != 1 {
+		t.Errorf("Expected 1 lookup removed and 1 cleared but got %+v instead", result)
+	}
+
+	if _, _, found := responseCache.Get("nwspoints:33.98,-83.96"); found {
+		t.Errorf("Expected the lookup to be cleared from memory")
+	}
+}
+
+func TestPurgeNWSPoints_AdminToken_IsRequired(t *testing.T) {
+	//	Arrange
+	tests := []struct {
+		token    string
+		header   string
+		expected int
+	}{
+		{"", "Bearer secret", http.StatusForbidden},
+		{"secret", "", http.StatusUnauthorized},
+		{"secret", "Bearer wrong", http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		viper.Set("server.admintoken", test.token)
+
+		req := httptest.NewRequest("DELETE", "/v2/nwspoints", nil)
+		req.Header.Set("Authorization", test.header)
+		rw := httptest.NewRecorder()
+
+		//	Act
+		Service{}.PurgeNWSPoints(rw, req)
+
+		//	Assert
+		if rw.Code != test.expected {
+			t.Errorf("Expected a %v for token '%v' and header '%v' but got %v instead", test.expected, test.token, test.header, rw.Code)
+		}
+	}
+
+	viper.Set("server.admintoken", nil)
+}
+
+func TestNWSPointsMemoryTTL_NoLongerThanRefresh(t *testing.T) {
+	//	Arrange
+	defer viper.Set("cache.ttl.nwspoints", nil)
+	defer viper.Set("nws.points.refresh", nil)
+
+	tests := []struct {
+		ttl, refresh string
+		expected     time.Duration
+	}{
+		{"24h", "720h", 24 * time.Hour},
+		{"0s", "720h", 720 * time.Hour},
+		{"1000h", "720h", 720 * time.Hour},
+		{"24h", "0s", 24 * time.Hour},
+		{"-1s", "720h", -time.Second},
+	}
+
+	for _, test := range tests {
+		viper.Set("cache.ttl.nwspoints", test.ttl)
+		viper.Set("nws.points.refresh", test.refresh)
+
+		//	Act
+		ttl := nwsPointsMemoryTTL()
+
+		//	Assert
+		if ttl != test.expected {
+			t.Errorf("Expected %v for a ttl of %v and refresh of %v but got %v instead", test.expected, test.ttl, test.refresh, ttl)
+		}
+	}
+}
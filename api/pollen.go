@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/spf13/viper"
 )
 
 // PollenReport represents the report of pollen data
@@ -26,6 +28,9 @@ type PollenRequest struct {
 
 // PollenService is the interface for all services that can fetch pollen data
 type PollenService interface {
+	// Name gets the name of the service (used when reporting problems)
+	Name() string
+
 	// GetPollenReport gets the pollen report
 	GetPollenReport(ctx context.Context, zipcode string) (PollenReport, error)
 }
@@ -38,13 +43,14 @@ type PollenService interface {
 // @Produce  json
 // @Param config body api.PollenRequest true "The location to get data for"
 // @Success 200 {object} api.PollenReport
+// @Header 200 {string} X-Cache "hit if the response came from the cache, otherwise miss"
+// @Header 200 {integer} Age "How old the cached response is (in seconds)"
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
+// @Failure 502 {object} api.ErrorResponse
 // @Router /pollen [post]
 func (s Service) GetPollenReport(rw http.ResponseWriter, req *http.Request) {
 
-	ch := make(chan PollenReport, 1)
-
 	txn := newrelic.FromContext(req.Context())
 	defer txn.StartSegment("Pollen GetPollenReport").End()
 
@@ -57,13 +63,13 @@ func (s Service) GetPollenReport(rw http.ResponseWriter, req *http.Request) {
 			"error", err,
 		)
 		txn.NoticeError(err)
-		sendErrorResponse(rw, err, http.StatusBadRequest)
+		sendErrorResponse(rw, newBadRequestError(err))
 		return
 	}
 
 	//	Make sure we have minimum args:
 	if request.Zipcode == "" {
-		sendErrorResponse(rw, fmt.Errorf("you must include a valid zipcode param"), http.StatusBadRequest)
+		sendErrorResponse(rw, newBadRequestError(fmt.Errorf("you must include a valid zipcode param")))
 		return
 	}
 
@@ -73,6 +79,52 @@ func (s Service) GetPollenReport(rw http.ResponseWriter, req *http.Request) {
 		PollencomService{},
 	}
 
+	//	Get the first good report (from the cache, if we have it)
+	cacheKey := fmt.Sprintf("pollen:%s", strings.TrimSpace(request.Zipcode))
+	cached, cacheResult, err := responseCache.GetOrLoad(cacheKey, cacheTTL("pollen"), func() (interface{}, error) {
+		ctx, cancel := loaderContext(req.Context())
+		defer cancel()
+		return getFirstPollenReport(ctx, services, request.Zipcode, viper.GetDuration("pollen.timeout"))
+	})
+	if err != nil {
+		zlog.Errorw(
+			"problem getting the pollen report",
+			"zipcode", request.Zipcode,
+			"error", err,
+		)
+		txn.NoticeError(err)
+		sendErrorResponse(rw, err)
+		return
+	}
+	retval := cached.(PollenReport)
+
+	//	Serialize to JSON & return the response:
+	setCacheHeaders(rw, cacheResult)
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(rw).Encode(retval)
+}
+
+// pollenResult is the result of asking a single pollen service for a report
+type pollenResult struct {
+	service string
+	report  PollenReport
+	err     error
+}
+
+// getFirstPollenReport asks all of the services for a pollen report at the same time and returns
+// the first usable one.  If every service fails (or we run out of time) the error lists why each one failed
+func getFirstPollenReport(ctx context.Context, services []PollenService, zipcode string, timeout time.Duration) (PollenReport, error) {
+
+	//	Don't wait forever
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	//	The channel is big enough for every service to report back without blocking
+	ch := make(chan pollenResult, len(services))
+
 	//	For each passed service ...
 	for _, service := range services {
 
@@ -82,25 +134,44 @@ func (s Service) GetPollenReport(rw http.ResponseWriter, req *http.Request) {
 			//	Get its pollen report ...
 			result, err := s.GetPollenReport(c, zip)
 
-			//	As long as we don't have an error, return what we found on the result channel
-			if err == nil {
-
-				//	Make sure we also have more than one datapoint!
-				if len(result.Data) > 1 {
-					select {
-					case ch <- result:
-					default:
-					}
-				}
+			//	Make sure we also have more than one datapoint!
+			if err == nil && len(result.Data) < 2 {
+				err = newUpstreamBadDataError(fmt.Errorf("only %d pollen data points were returned", len(result.Data)))
 			}
-		}(req.Context(), service, request.Zipcode)
+
+			ch <- pollenResult{service: s.Name(), report: result, err: err}
+		}(ctx, service, zipcode)
 
 	}
 
-	//	Capture the first result passed on the channel
-	retval := <-ch
+	//	Keep track of which services haven't answered yet, and the problems we run into
+	pending := map[string]bool{}
+	for _, service := range services {
+		pending[service.Name()] = true
+	}
+	problems := []ProviderError{}
+
+	for len(pending) > 0 {
+		select {
+		case result := <-ch:
+			//	Capture the first good result passed on the channel
+			if result.err == nil {
+				return result.report, nil
+			}
 
-	//	Serialize to JSON & return the response:
-	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(rw).Encode(retval)
+			delete(pending, result.service)
+			problems = append(problems, newProviderError(result.service, result.err))
+
+		case <-ctx.Done():
+			//	Anything that hasn't answered yet ran out of time
+			for _, service := range services {
+				if pending[service.Name()] {
+					problems = append(problems, newProviderError(service.Name(), newUpstreamError(fmt.Errorf("no response: %w", ctx.Err()))))
+				}
+			}
+			pending = map[string]bool{}
+		}
+	}
+
+	return PollenReport{}, newProvidersFailedError(fmt.Errorf("all pollen services failed for zipcode %s", zipcode), problems)
 }
@@ -4,11 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 	"strconv"
 	"time"
 
+	"github.com/danesparza/daydash-service/internal/httpclient"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"golang.org/x/net/context/ctxhttp"
 )
@@ -32,6 +32,11 @@ type NasacortResponse struct {
 	} `json:"response"`
 }
 
+// Name gets the name of the service
+func (s NasacortService) Name() string {
+	return "Nasacort"
+}
+
 // GetPollenReport gets the pollen report
 func (s NasacortService) GetPollenReport(ctx context.Context, zipcode string) (PollenReport, error) {
 
@@ -45,9 +50,7 @@ func (s NasacortService) GetPollenReport(ctx context.Context, zipcode string) (P
 	//	Format the url:
 	apiurl := "https://www.nasacort.com/wp-json/pollen/get/"
 
-	client := &http.Client{}
-	client.Transport = newrelic.NewRoundTripper(client.Transport)
-	resp, err := ctxhttp.PostForm(ctx, client, apiurl, url.Values{
+	resp, err := ctxhttp.PostForm(ctx, httpclient.Default(), apiurl, url.Values{
 		"zipcode": {zipcode},
 	})
 
@@ -57,14 +60,14 @@ func (s NasacortService) GetPollenReport(ctx context.Context, zipcode string) (P
 			"error", err,
 		)
 		txn.NoticeError(err)
-		apperr := fmt.Errorf("problem calling Nasacort API: %s", err)
+		apperr := newUpstreamError(fmt.Errorf("problem calling Nasacort API: %w", err))
 		return retval, apperr
 	}
 	defer resp.Body.Close()
 
 	//	If the HTTP status code indicates an error, report it and get out
 	if resp.StatusCode >= 400 {
-		apperr := fmt.Errorf("error getting information from Nasacort API: %s", resp.Status)
+		apperr := newUpstreamStatusError(fmt.Errorf("error getting information from Nasacort API: %s", resp.Status), resp.StatusCode)
 		return retval, apperr
 	}
 
@@ -77,7 +80,7 @@ func (s NasacortService) GetPollenReport(ctx context.Context, zipcode string) (P
 			"error", err,
 		)
 		txn.NoticeError(err)
-		apperr := fmt.Errorf("problem decoding the response from Nasacort API: %s", err)
+		apperr := newUpstreamBadDataError(fmt.Errorf("problem decoding the response from Nasacort API: %v", err))
 		return retval, apperr
 	}
 
@@ -97,7 +100,7 @@ func (s NasacortService) GetPollenReport(ctx context.Context, zipcode string) (P
 
 	//	Set the properties in the return object:
 	retval = PollenReport{
-		ReportingService:  "Nasacort",
+		ReportingService:  s.Name(),
 		PredominantPollen: serviceResponse.Response.Source,
 		Zipcode:           zipcode,
 		Location:          fmt.Sprintf("%s, %s", serviceResponse.Response.City, serviceResponse.Response.State),
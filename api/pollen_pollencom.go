@@ -8,10 +8,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/danesparza/daydash-service/internal/httpclient"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"golang.org/x/net/context/ctxhttp"
 )
 
+// pollencomForecastURL is the base url for the Pollen.com forecast API
+var pollencomForecastURL = "https://www.pollen.com/api/forecast"
+
 // PollencomService is a pollen service for Pollen.com formatted data
 type PollencomService struct{}
 
@@ -54,6 +58,11 @@ type PollencomCurrentResponse struct {
 	} `json:"Location"`
 }
 
+// Name gets the name of the service
+func (s PollencomService) Name() string {
+	return "Pollen.com"
+}
+
 // GetPollenReport gets the pollen report
 func (s PollencomService) GetPollenReport(ctx context.Context, zipcode string) (PollenReport, error) {
 
@@ -65,7 +74,7 @@ func (s PollencomService) GetPollenReport(ctx context.Context, zipcode string) (
 	retval := PollenReport{}
 
 	//	Format the extended forecast url (to get the pollen indices):
-	apiurl := fmt.Sprintf("https://www.pollen.com/api/forecast/extended/pollen/%s", zipcode)
+	apiurl := fmt.Sprintf("%s/extended/pollen/%s", pollencomForecastURL, zipcode)
 
 	req, _ := http.NewRequest("GET", apiurl, nil)
 	req.Header.Add("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/65.0.3325.146 Safari/537.36")
@@ -73,9 +82,7 @@ func (s PollencomService) GetPollenReport(ctx context.Context, zipcode string) (
 	req.Header.Add("Referer", apiurl)
 	req = newrelic.RequestWithTransactionContext(req, txn)
 
-	client := &http.Client{}
-	client.Transport = newrelic.NewRoundTripper(client.Transport)
-	resp, err := ctxhttp.Do(ctx, client, req)
+	resp, err := ctxhttp.Do(ctx, httpclient.Default(), req)
 
 	if err != nil {
 		zlog.Errorw(
@@ -83,14 +90,14 @@ func (s PollencomService) GetPollenReport(ctx context.Context, zipcode string) (
 			"error", err,
 		)
 		txn.NoticeError(err)
-		apperr := fmt.Errorf("problem calling Pollen.com extended forecast API: %s", err)
+		apperr := newUpstreamError(fmt.Errorf("problem calling Pollen.com extended forecast API: %w", err))
 		return retval, apperr
 	}
 	defer resp.Body.Close()
 
 	//	If the HTTP status code indicates an error, report it and get out
 	if resp.StatusCode >= 400 {
-		apperr := fmt.Errorf("error getting information from Pollen.com extended forecast API: %s", resp.Status)
+		apperr := newUpstreamStatusError(fmt.Errorf("error getting information from Pollen.com extended forecast API: %s", resp.Status), resp.StatusCode)
 		zlog.Errorw(
 			"There was an error getting information from Pollen.com extended forecast API",
 			"error", apperr,
@@ -108,7 +115,7 @@ func (s PollencomService) GetPollenReport(ctx context.Context, zipcode string) (
 			"error", err,
 		)
 		txn.NoticeError(err)
-		apperr := fmt.Errorf("problem decoding the response from Pollen.com extended forecast API: %s", err)
+		apperr := newUpstreamBadDataError(fmt.Errorf("problem decoding the response from Pollen.com extended forecast API: %v", err))
 		return retval, apperr
 	}
 
@@ -130,7 +137,7 @@ func (s PollencomService) GetPollenReport(ctx context.Context, zipcode string) (
 	}
 
 	//	Format the current conditions url (to get predominant pollen):
-	currentapiurl := fmt.Sprintf("https://www.pollen.com/api/forecast/current/pollen/%s", zipcode)
+	currentapiurl := fmt.Sprintf("%s/current/pollen/%s", pollencomForecastURL, zipcode)
 
 	currreq, _ := http.NewRequest("GET", currentapiurl, nil)
 	currreq.Header.Add("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/65.0.3325.146 Safari/537.36")
@@ -138,9 +145,7 @@ func (s PollencomService) GetPollenReport(ctx context.Context, zipcode string) (
 	currreq.Header.Add("Referer", currentapiurl)
 	currreq = newrelic.RequestWithTransactionContext(currreq, txn)
 
-	curclient := &http.Client{}
-	curclient.Transport = newrelic.NewRoundTripper(curclient.Transport)
-	currresp, err := ctxhttp.Do(ctx, curclient, currreq)
+	currresp, err := ctxhttp.Do(ctx, httpclient.Default(), currreq)
 
 	if err != nil {
 		zlog.Errorw(
@@ -148,14 +153,14 @@ func (s PollencomService) GetPollenReport(ctx context.Context, zipcode string) (
 			"error", err,
 		)
 		txn.NoticeError(err)
-		apperr := fmt.Errorf("problem calling Pollen.com current forecast API: %s", err)
+		apperr := newUpstreamError(fmt.Errorf("problem calling Pollen.com current forecast API: %w", err))
 		return retval, apperr
 	}
-	defer resp.Body.Close()
+	defer currresp.Body.Close()
 
 	//	If the HTTP status code indicates an error, report it and get out
 	if currresp.StatusCode >= 400 {
-		apperr := fmt.Errorf("error getting information from Pollen.com current forecast API: %s", resp.Status)
+		apperr := newUpstreamStatusError(fmt.Errorf("error getting information from Pollen.com current forecast API: %s", currresp.Status), currresp.StatusCode)
 		zlog.Errorw(
 			"There was an error getting information from Pollen.com current forecast API",
 			"error", apperr,
@@ -173,7 +178,7 @@ func (s PollencomService) GetPollenReport(ctx context.Context, zipcode string) (
 			"error", err,
 		)
 		txn.NoticeError(err)
-		apperr := fmt.Errorf("problem decoding the response from Pollen.com current forecast API: %s", err)
+		apperr := newUpstreamBadDataError(fmt.Errorf("problem decoding the response from Pollen.com current forecast API: %v", err))
 		return retval, apperr
 	}
 
@@ -187,7 +192,7 @@ func (s PollencomService) GetPollenReport(ctx context.Context, zipcode string) (
 
 	//	Set the properties in the return object:
 	retval = PollenReport{
-		ReportingService:  "Pollen.com",
+		ReportingService:  s.Name(),
 		PredominantPollen: predomPollen,
 		Zipcode:           zipcode,
 		Location:          fmt.Sprintf("%s, %s", serviceResponse.Location.City, serviceResponse.Location.State),
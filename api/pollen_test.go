@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakePollenService is a pollen service that returns canned results
+type fakePollenService struct {
+	name   string
+	report PollenReport
+	err    error
+	hang   bool // Wait until the context is done
+}
+
+func (s fakePollenService) Name() string {
+	return s.name
+}
+
+func (s fakePollenService) GetPollenReport(ctx context.Context, zipcode string) (PollenReport, error) {
+	if s.hang {
+		<-ctx.Done()
+		return PollenReport{}, ctx.Err()
+	}
+	return s.report, s.err
+}
+
+func TestGetFirstPollenReport_OneServiceWorks_ReturnsReport(t *testing.T) {
+	//	Arrange
+	services := []PollenService{
+		fakePollenService{name: "Broken", err: newUpstreamError(fmt.Errorf("connection refused"))},
+		fakePollenService{name: "Working", report: PollenReport{ReportingService: "Working", Data: []float64{1, 2}}},
+	}
+
+	//	Act
+	report, err := getFirstPollenReport(context.Background(), services, "30019", time.Second)
+
+	//	Assert
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+
+	if report.ReportingService != "Working" {
+		t.Errorf("Expected a report from Working but got '%v' instead", report.ReportingService)
+	}
+}
+
+func TestGetFirstPollenReport_AllServicesFail_ReturnsEachProblem(t *testing.T) {
+	//	Arrange
+	services := []PollenService{
+		fakePollenService{name: "Broken", err: newUpstreamError(fmt.Errorf("connection refused"))},
+		fakePollenService{name: "Sparse", report: PollenReport{Data: []float64{1}}},
+		fakePollenService{name: "Slow", hang: true},
+	}
+
+	//	Act
+	start := time.Now()
+	_, err := getFirstPollenReport(context.Background(), services, "30019", 50*time.Millisecond)
+
+	//	Assert
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Took %v to give up -- expected the timeout to stop us", time.Since(start))
+	}
+
+	serviceErr := ServiceError{}
+	if !errors.As(err, &serviceErr) {
+		t.Fatalf("Expected a ServiceError but got '%v' instead", err)
+	}
+
+	if serviceErr.StatusCode() != 502 {
+		t.Errorf("Expected status 502 but got %v instead", serviceErr.StatusCode())
+	}
+
+	codes := map[string]string{}
+	for _, problem := range serviceErr.Providers {
+		codes[problem.Provider] = problem.Code
+	}
+
+	expected := map[string]string{
+		"Broken": ErrorCodeUpstreamUnavailable,
+		"Sparse": ErrorCodeUpstreamBadData,
+		"Slow":   ErrorCodeUpstreamTimeout,
+	}
+	for provider, code := range expected {
+		if codes[provider] != code {
+			t.Errorf("Expected %v to fail with %v but got '%v' instead", provider, code, codes[provider])
+		}
+	}
+}
+
+func TestPollencomService_CurrentForecastFails_ReportsItsStatus(t *testing.T) {
+	//	Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "/current/") {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(rw, `{"Location":{"City":"Dacula","State":"GA","periods":[{"Index":1},{"Index":2},{"Index":3},{"Index":4}]}}`)
+	}))
+	defer server.Close()
+
+	originalURL := pollencomForecastURL
+	pollencomForecastURL = server.URL
+	defer func() { pollencomForecastURL = originalURL }()
+
+	//	Act
+	_, err := PollencomService{}.GetPollenReport(context.Background(), "30019")
+
+	//	Assert
+	if errorCode(err) != ErrorCodeUpstreamUnavailable {
+		t.Errorf("Expected %v but got '%v' instead", ErrorCodeUpstreamUnavailable, errorCode(err))
+	}
+
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Expected the current forecast status in the error but got '%v' instead", err)
+	}
+}
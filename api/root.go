@@ -1,7 +1,10 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
 	"time"
 
@@ -28,17 +31,163 @@ type SystemResponse struct {
 
 // ErrorResponse represents an API response
 type ErrorResponse struct {
-	Message string `json:"message"`
+	Message   string          `json:"message"`
+	Code      string          `json:"code"`                // Machine readable error code (like 'upstream_timeout')
+	Providers []ProviderError `json:"providers,omitempty"` // When several upstream providers were tried, why each one failed
 }
 
-// sendErrorResponse is used to send back an error:
-func sendErrorResponse(rw http.ResponseWriter, err error, code int) {
+// ProviderError describes why a single upstream provider failed
+type ProviderError struct {
+	Provider string `json:"provider"` // The provider name
+	Code     string `json:"code"`     // Machine readable error code
+	Message  string `json:"message"`  // What went wrong
+}
+
+// Machine readable error codes
+const (
+	ErrorCodeBadRequest          = "bad_request"          // The request was invalid (400)
+	ErrorCodeUnauthorized        = "unauthorized"         // The request didn't include valid credentials (401)
+	ErrorCodeForbidden           = "forbidden"            // The request isn't allowed (403)
+	ErrorCodeNotAcceptable       = "not_acceptable"       // We can't respond in any format the client accepts (406)
+	ErrorCodeInternal            = "internal_error"       // Something went wrong in the service (500)
+	ErrorCodeUpstreamBadData     = "upstream_bad_data"    // An upstream service returned data we couldn't use (502)
+	ErrorCodeUpstreamUnavailable = "upstream_unavailable" // An upstream service couldn't be reached or returned an error (503)
+	ErrorCodeUpstreamTimeout     = "upstream_timeout"     // An upstream service took too long to respond (504)
+	ErrorCodeProvidersFailed     = "providers_failed"     // Every upstream provider we tried failed (502)
+)
+
+// ServiceError is an error that knows how it should be reported to the client
+type ServiceError struct {
+	Code      string          // Machine readable error code
+	Err       error           // The underlying error
+	Providers []ProviderError // Optional details for each upstream provider that failed
+}
+
+// Error gets the error message
+func (e ServiceError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap gets the underlying error
+func (e ServiceError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode gets the HTTP status code for the error
+func (e ServiceError) StatusCode() int {
+	switch e.Code {
+	case ErrorCodeBadRequest:
+		return http.StatusBadRequest
+	case ErrorCodeUnauthorized:
+		return http.StatusUnauthorized
+	case ErrorCodeForbidden:
+		return http.StatusForbidden
+	case ErrorCodeNotAcceptable:
+		return http.StatusNotAcceptable
+	case ErrorCodeUpstreamBadData, ErrorCodeProvidersFailed:
+		return http.StatusBadGateway
+	case ErrorCodeUpstreamUnavailable:
+		return http.StatusServiceUnavailable
+	case ErrorCodeUpstreamTimeout:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// newBadRequestError creates an error for an invalid request
+func newBadRequestError(err error) error {
+	return ServiceError{Code: ErrorCodeBadRequest, Err: err}
+}
+
+// newUnauthorizedError creates an error for a request without valid credentials
+func newUnauthorizedError(err error) error {
+	return ServiceError{Code: ErrorCodeUnauthorized, Err: err}
+}
+
+// newForbiddenError creates an error for a request that isn't allowed
+func newForbiddenError(err error) error {
+	return ServiceError{Code: ErrorCodeForbidden, Err: err}
+}
+
+// newNotAcceptableError creates an error for a request we can't respond to in a format the client accepts
+func newNotAcceptableError(err error) error {
+	return ServiceError{Code: ErrorCodeNotAcceptable, Err: err}
+}
+
+// newInternalError creates an error for a problem in the service itself
+func newInternalError(err error) error {
+	return ServiceError{Code: ErrorCodeInternal, Err: err}
+}
+
+// newUpstreamBadDataError creates an error for upstream data we can't use
+func newUpstreamBadDataError(err error) error {
+	return ServiceError{Code: ErrorCodeUpstreamBadData, Err: err}
+}
+
+// newUpstreamError creates an error for a failed call to an upstream service.
+// Timeouts are reported as timeouts -- everything else means the service is unavailable
+func newUpstreamError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ServiceError{Code: ErrorCodeUpstreamTimeout, Err: err}
+	}
+
+	return ServiceError{Code: ErrorCodeUpstreamUnavailable, Err: err}
+}
+
+// newUpstreamStatusError creates an error for an upstream HTTP error status.  Server errors
+// (and rate limiting) mean the service is unavailable -- anything else means we can't use the response
+func newUpstreamStatusError(err error, statusCode int) error {
+	if statusCode >= 500 || statusCode == http.StatusTooManyRequests {
+		return ServiceError{Code: ErrorCodeUpstreamUnavailable, Err: err}
+	}
+
+	return ServiceError{Code: ErrorCodeUpstreamBadData, Err: err}
+}
+
+// newProvidersFailedError creates an error for when every upstream provider we tried failed.  The
+// providers explain why each one failed
+func newProvidersFailedError(err error, providers []ProviderError) error {
+	return ServiceError{Code: ErrorCodeProvidersFailed, Err: err, Providers: providers}
+}
+
+// newProviderError describes why the named provider failed
+func newProviderError(provider string, err error) ProviderError {
+	return ProviderError{
+		Provider: provider,
+		Code:     errorCode(err),
+		Message:  err.Error(),
+	}
+}
+
+// errorCode gets the machine readable code for an error (errors we don't know about are internal errors)
+func errorCode(err error) string {
+	var serviceErr ServiceError
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Code
+	}
+
+	return ErrorCodeInternal
+}
+
+// sendErrorResponse is used to send back an error.  The HTTP status code is based on the type of error
+func sendErrorResponse(rw http.ResponseWriter, err error) {
+	//	Find out what kind of error we have
+	serviceErr := ServiceError{}
+	if !errors.As(err, &serviceErr) {
+		serviceErr = ServiceError{Code: ErrorCodeInternal, Err: err}
+	}
+
 	//	Our return value
-	response := ErrorResponse{Message: "Error: " + err.Error()}
+	response := ErrorResponse{
+		Message:   "Error: " + err.Error(),
+		Code:      serviceErr.Code,
+		Providers: serviceErr.Providers,
+	}
 
 	//	Serialize to JSON & return the response:
 	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
-	rw.WriteHeader(code)
+	rw.WriteHeader(serviceErr.StatusCode())
 	json.NewEncoder(rw).Encode(response)
 }
 
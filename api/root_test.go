@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendErrorResponse_UsesStatusForErrorType(t *testing.T) {
+	//	Arrange
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{newBadRequestError(fmt.Errorf("bad")), http.StatusBadRequest, ErrorCodeBadRequest},
+		{newUpstreamBadDataError(fmt.Errorf("bad data")), http.StatusBadGateway, ErrorCodeUpstreamBadData},
+		{newUpstreamError(fmt.Errorf("connection refused")), http.StatusServiceUnavailable, ErrorCodeUpstreamUnavailable},
+		{newUpstreamError(fmt.Errorf("calling: %w", context.DeadlineExceeded)), http.StatusGatewayTimeout, ErrorCodeUpstreamTimeout},
+		{newUpstreamStatusError(fmt.Errorf("500"), http.StatusInternalServerError), http.StatusServiceUnavailable, ErrorCodeUpstreamUnavailable},
+		{newUpstreamStatusError(fmt.Errorf("404"), http.StatusNotFound), http.StatusBadGateway, ErrorCodeUpstreamBadData},
+		{fmt.Errorf("something else"), http.StatusInternalServerError, ErrorCodeInternal},
+	}
+
+	for _, test := range tests {
+		//	Act
+		rw := httptest.NewRecorder()
+		sendErrorResponse(rw, test.err)
+
+		//	Assert
+		if rw.Code != test.status {
+			t.Errorf("Expected status %v for '%v' but got %v instead", test.status, test.err, rw.Code)
+		}
+
+		response := ErrorResponse{}
+		if err := json.NewDecoder(rw.Body).Decode(&response); err != nil {
+			t.Errorf("Returned error and we didn't expect that: %v", err)
+		}
+
+		if response.Code != test.code {
+			t.Errorf("Expected code %v for '%v' but got %v instead", test.code, test.err, response.Code)
+		}
+	}
+}
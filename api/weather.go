@@ -1,13 +1,17 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
+	"strings"
+	"time"
+	"unicode"
 
+	"github.com/danesparza/daydash-service/internal/astronomy"
 	"github.com/newrelic/go-agent/v3/newrelic"
-	"golang.org/x/net/context/ctxhttp"
+	"github.com/spf13/viper"
 )
 
 // WeatherReport defines a weather report
@@ -20,6 +24,9 @@ type WeatherReport struct {
 	Minutely  []MinuteDataPoint  `json:"minutely"`
 	APICalls  int                `json:"apicalls"`
 	Code      int                `json:"code"`
+	Provider  string             `json:"provider"` // The weather provider that answered
+	Units     WeatherUnits       `json:"units"`    // The units used in the report
+	Alerts    []AlertItem        `json:"alerts"`   // Active alerts (if the provider includes them)
 }
 
 // WeatherDataBlock defines a group of data points
@@ -52,6 +59,14 @@ type WeatherDataPoint struct {
 	Visibility          float64 `json:"visibility"`
 	Ozone               float64 `json:"ozone"`
 	UVIndex             float64 `json:"uvindex"`
+	DewPoint            float64 `json:"dewPoint"`
+	Sunrise             int64   `json:"sunrise"`          // Sunrise time (unix)
+	Sunset              int64   `json:"sunset"`           // Sunset time (unix)
+	DaylightDuration    int64   `json:"daylightDuration"` // Seconds between sunrise and sunset
+	Moonrise            int64   `json:"moonrise"`         // Moonrise time (unix)
+	Moonset             int64   `json:"moonset"`          // Moonset time (unix)
+	MoonPhase           float64 `json:"moonPhase"`        // 0 and 1 are 'new moon', 0.25 is 'first quarter', 0.5 is 'full moon', 0.75 is 'last quarter'
+	MoonPhaseName       string  `json:"moonPhaseName"`    // Human readable moon phase (like 'Waxing Gibbous')
 }
 
 type MinuteDataPoint struct {
@@ -62,119 +77,239 @@ type MinuteDataPoint struct {
 type WeatherRequest struct {
 	Latitude  string `json:"lat"`
 	Longitude string `json:"long"`
+	Units     string `json:"units"` // Unit system: imperial (default), metric or standard
+	Language  string `json:"lang"`  // Language for descriptions (like 'en' or 'de').  Only supported by some providers
 }
 
-type OpenWeatherRequest struct {
-	Lat     string `json:"lat"`     // Latitude
-	Long    string `json:"lon"`     // Longitude
-	Exclude string `json:"exclude"` // Exclude data from response
-	Units   string `json:"units"`   // Units to use
-	AppId   string `json:"appid"`   // Application id / token
+// WeatherProvider is the interface for all services that can fetch weather data
+type WeatherProvider interface {
+	// Name gets the display name of the provider
+	Name() string
+
+	// GetWeatherReport gets the weather report (in canonical metric units)
+	GetWeatherReport(ctx context.Context, request WeatherRequest) (WeatherReport, error)
+}
+
+// getWeatherProvider returns the weather provider with the given (configured) name
+func getWeatherProvider(name string) (WeatherProvider, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "openweather":
+		return OpenWeatherService{}, nil
+	case "nws":
+		return NWSWeatherService{}, nil
+	case "openmeteo":
+		return OpenMeteoService{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown weather provider: %s", name)
+}
+
+// getWeatherProviders gets the weather providers with the given (configured) names, in order.  Unknown
+// names are reported as problems
+func getWeatherProviders(names []string) ([]WeatherProvider, []ProviderError) {
+	retval := []WeatherProvider{}
+	problems := []ProviderError{}
+
+	for _, name := range names {
+		provider, err := getWeatherProvider(name)
+		if err != nil {
+			problems = append(problems, newProviderError(name, newInternalError(err)))
+			continue
+		}
+
+		retval = append(retval, provider)
+	}
+
+	return retval, problems
+}
+
+// getWeatherReportWithFailover tries each of the providers in order and returns the first plausible
+// weather report.  Each provider gets its own (configurable) timeout.  Any problems already found
+// (like unknown providers) are included if every provider fails
+func getWeatherReportWithFailover(ctx context.Context, request WeatherRequest, providers []WeatherProvider, problems []ProviderError) (WeatherReport, error) {
+
+	txn := newrelic.FromContext(ctx)
+	segment := txn.StartSegment("Weather getWeatherReportWithFailover")
+	defer segment.End()
+
+	//	Keep track of the problems we run into along the way
+	problems = append([]ProviderError{}, problems...)
+
+	for _, provider := range providers {
+
+		//	Give the provider its own deadline
+		providerCtx, cancel := context.WithTimeout(ctx, weatherProviderTimeout(provider.Name()))
+		report, err := provider.GetWeatherReport(providerCtx, request)
+		cancel()
+
+		//	If we didn't get an error, make sure the data looks reasonable
+		if err == nil {
+			err = checkWeatherReport(report)
+		}
+
+		//	If the provider ran out of time, make sure we report it as a timeout
+		if err != nil && providerCtx.Err() == context.DeadlineExceeded {
+			err = newUpstreamError(fmt.Errorf("%v: %w", err, context.DeadlineExceeded))
+		}
+
+		if err != nil {
+			zlog.Warnw(
+				"weather provider failed -- trying the next one",
+				"provider", provider.Name(),
+				"error", err,
+			)
+			problems = append(problems, newProviderError(provider.Name(), err))
+			continue
+		}
+
+		//	Report which provider actually answered
+		report.Provider = provider.Name()
+		return report, nil
+	}
+
+	if len(problems) == 0 {
+		return WeatherReport{}, newInternalError(fmt.Errorf("no weather providers are configured"))
+	}
+
+	//	Sum up what went wrong
+	messages := []string{}
+	for _, problem := range problems {
+		messages = append(messages, fmt.Sprintf("%s: %s", problem.Provider, problem.Message))
+	}
+
+	return WeatherReport{}, newProvidersFailedError(fmt.Errorf("all weather providers failed: %s", strings.Join(messages, "; ")), problems)
+}
+
+// weatherProviderTimeout gets the timeout for the named provider.  A provider specific
+// timeout (weather.timeouts.<name>, like weather.timeouts.openmeteo) overrides the general weather.timeout
+func weatherProviderTimeout(name string) time.Duration {
+	key := fmt.Sprintf("weather.timeouts.%s", weatherProviderKey(name))
+	if viper.IsSet(key) {
+		return viper.GetDuration(key)
+	}
+
+	return viper.GetDuration("weather.timeout")
+}
+
+// weatherProviderKey gets the config name for a provider (its name in lower case, without any punctuation)
+func weatherProviderKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
 }
 
-type OpenWeatherResponse struct {
-	Lat            float64 `json:"lat"`
-	Lon            float64 `json:"lon"`
-	Timezone       string  `json:"timezone"`
-	TimezoneOffset int     `json:"timezone_offset"`
-	Current        struct {
-		Dt        int     `json:"dt"`
-		Sunrise   int     `json:"sunrise"`
-		Sunset    int     `json:"sunset"`
-		Temp      float64 `json:"temp"`
-		FeelsLike float64 `json:"feels_like"`
-		Pressure  int     `json:"pressure"`
-		Humidity  int     `json:"humidity"`
-		DewPoint  float64 `json:"dew_point"`
-		Uvi       float64 `json:"uvi"`
-		Clouds    int     `json:"clouds"`
-		Rain      struct {
-			LastHour float64 `json:"1h"`
+// addWeatherAstronomy fills in the sun & moon details for a weather report.  Anything the provider
+// didn't include is calculated locally.  The current conditions get the sun & moon details for today
+func addWeatherAstronomy(report WeatherReport) WeatherReport {
+
+	for i := range report.Daily.Data {
+		report.Daily.Data[i] = calculateDataPointAstronomy(report.Daily.Data[i], report.Latitude, report.Longitude)
+	}
+
+	if len(report.Daily.Data) > 0 {
+		today := report.Daily.Data[0]
+
+		if report.Currently.Sunrise == 0 && report.Currently.Sunset == 0 {
+			report.Currently.Sunrise = today.Sunrise
+			report.Currently.Sunset = today.Sunset
+		}
+
+		if report.Currently.Moonrise == 0 && report.Currently.Moonset == 0 && report.Currently.MoonPhase == 0 {
+			report.Currently.Moonrise = today.Moonrise
+			report.Currently.Moonset = today.Moonset
+			report.Currently.MoonPhase = today.MoonPhase
 		}
-		Visibility int     `json:"visibility"`
-		WindSpeed  float64 `json:"wind_speed"`
-		WindDeg    int     `json:"wind_deg"`
-		Weather    []struct {
-			ID          int    `json:"id"`
-			Main        string `json:"main"`
-			Description string `json:"description"`
-			Icon        string `json:"icon"`
-		} `json:"weather"`
-	} `json:"current"`
-	Hourly []struct {
-		Dt         int     `json:"dt"`
-		Temp       float64 `json:"temp"`
-		FeelsLike  float64 `json:"feels_like"`
-		Pressure   int     `json:"pressure"`
-		Humidity   int     `json:"humidity"`
-		DewPoint   float64 `json:"dew_point"`
-		Uvi        float64 `json:"uvi"`
-		Clouds     int     `json:"clouds"`
-		Visibility int     `json:"visibility"`
-		WindSpeed  float64 `json:"wind_speed"`
-		WindDeg    float64 `json:"wind_deg"`
-		WindGust   float64 `json:"wind_gust"`
-		Weather    []struct {
-			ID          int    `json:"id"`
-			Main        string `json:"main"`
-			Description string `json:"description"`
-			Icon        string `json:"icon"`
-		} `json:"weather"`
-		Pop  float64 `json:"pop"`
-		Rain struct {
-			OneH float64 `json:"1h"`
-		} `json:"rain,omitempty"`
-	} `json:"hourly"`
-	Daily []struct {
-		Dt        int     `json:"dt"`
-		Sunrise   int     `json:"sunrise"`
-		Sunset    int     `json:"sunset"`
-		Moonrise  int     `json:"moonrise"`
-		Moonset   int     `json:"moonset"`
-		MoonPhase float64 `json:"moon_phase"`
-		Temp      struct {
-			Day   float64 `json:"day"`
-			Min   float64 `json:"min"`
-			Max   float64 `json:"max"`
-			Night float64 `json:"night"`
-			Eve   float64 `json:"eve"`
-			Morn  float64 `json:"morn"`
-		} `json:"temp"`
-		FeelsLike struct {
-			Day   float64 `json:"day"`
-			Night float64 `json:"night"`
-			Eve   float64 `json:"eve"`
-			Morn  float64 `json:"morn"`
-		} `json:"feels_like"`
-		Pressure  int     `json:"pressure"`
-		Humidity  int     `json:"humidity"`
-		DewPoint  float64 `json:"dew_point"`
-		WindSpeed float64 `json:"wind_speed"`
-		WindDeg   int     `json:"wind_deg"`
-		WindGust  float64 `json:"wind_gust"`
-		Weather   []struct {
-			ID          int    `json:"id"`
-			Main        string `json:"main"`
-			Description string `json:"description"`
-			Icon        string `json:"icon"`
-		} `json:"weather"`
-		Clouds int     `json:"clouds"`
-		Pop    float64 `json:"pop"`
-		Rain   float64 `json:"rain"`
-		Uvi    float64 `json:"uvi"`
-	} `json:"daily"`
-	Minutely []struct {
-		Dt            int     `json:"dt"`
-		Precipitation float64 `json:"precipitation"`
-	} `json:"minutely"`
-	Alerts []struct {
-		SenderName  string   `json:"sender_name"`
-		Event       string   `json:"event"`
-		Start       float64  `json:"start"`
-		End         float64  `json:"end"`
-		Description string   `json:"description"`
-		Tags        []string `json:"tags"`
-	} `json:"alerts"`
+	}
+
+	report.Currently = addDataPointAstronomy(report.Currently)
+	for i := range report.Daily.Data {
+		report.Daily.Data[i] = addDataPointAstronomy(report.Daily.Data[i])
+	}
+
+	return report
+}
+
+// calculateDataPointAstronomy calculates the sun & moon times for a daily data point
+// if the provider didn't include them
+func calculateDataPointAstronomy(point WeatherDataPoint, lat, long float64) WeatherDataPoint {
+
+	//	We can't calculate anything without a location
+	if lat == 0 && long == 0 {
+		return point
+	}
+
+	//	We don't know the timezone, but local mean time (based on the longitude)
+	//	is close enough to find the correct calendar day
+	meanTime := time.FixedZone("LMT", int(long/15*3600))
+	date := time.Unix(point.Time, 0).In(meanTime)
+
+	if point.Sunrise == 0 && point.Sunset == 0 {
+		sun := astronomy.GetSunTimes(date, lat, long)
+		point.Sunrise = unixOrZero(sun.Sunrise)
+		point.Sunset = unixOrZero(sun.Sunset)
+	}
+
+	if point.Moonrise == 0 && point.Moonset == 0 && point.MoonPhase == 0 {
+		moon := astronomy.GetMoonInfo(date, lat, long)
+		point.Moonrise = unixOrZero(moon.Moonrise)
+		point.Moonset = unixOrZero(moon.Moonset)
+		point.MoonPhase = moon.Phase
+	}
+
+	return point
+}
+
+// addDataPointAstronomy fills in the daylight duration and moon phase name for a data point
+func addDataPointAstronomy(point WeatherDataPoint) WeatherDataPoint {
+	if point.DaylightDuration == 0 && point.Sunrise != 0 && point.Sunset > point.Sunrise {
+		point.DaylightDuration = point.Sunset - point.Sunrise
+	}
+
+	//	Only name the phase if we actually have moon data
+	if point.MoonPhaseName == "" && (point.MoonPhase != 0 || point.Moonrise != 0 || point.Moonset != 0) {
+		point.MoonPhaseName = astronomy.PhaseName(point.MoonPhase)
+	}
+
+	return point
+}
+
+// unixOrZero gets the unix time for the given time (or zero if it's a zero time)
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// getConvertedWeatherReport gets the weather report from the first configured provider that answers,
+// fills in the sun & moon details and converts the report to the units requested
+func getConvertedWeatherReport(ctx context.Context, request WeatherRequest) (WeatherReport, error) {
+	providers, problems := getWeatherProviders(viper.GetStringSlice("weather.providers"))
+	retval, err := getWeatherReportWithFailover(ctx, request, providers, problems)
+	if err != nil {
+		return retval, err
+	}
+
+	retval = addWeatherAstronomy(retval)
+	retval = convertWeatherReport(retval, request.Units)
+
+	return retval, nil
+}
+
+// checkWeatherReport makes sure a weather report looks plausible
+func checkWeatherReport(report WeatherReport) error {
+	if report.Currently.Summary == "" && report.Currently.Icon == "" {
+		return newUpstreamBadDataError(fmt.Errorf("no current conditions were returned"))
+	}
+
+	if len(report.Hourly) == 0 {
+		return newUpstreamBadDataError(fmt.Errorf("no hourly data points were returned"))
+	}
+
+	return nil
 }
 
 // GetWeatherReport godoc
@@ -185,17 +320,19 @@ type OpenWeatherResponse struct {
 // @Produce  json
 // @Param config body api.WeatherRequest true "The location to fetch data for"
 // @Success 200 {object} api.WeatherReport
+// @Header 200 {string} X-Cache "hit if the response came from the cache, otherwise miss"
+// @Header 200 {integer} Age "How old the cached response is (in seconds)"
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
+// @Failure 502 {object} api.ErrorResponse
+// @Failure 503 {object} api.ErrorResponse
+// @Failure 504 {object} api.ErrorResponse
 // @Router /weather [post]
 func (s Service) GetWeatherReport(rw http.ResponseWriter, req *http.Request) {
 
 	txn := newrelic.FromContext(req.Context())
 	defer txn.StartSegment("Weather GetWeatherReport").End()
 
-	//	The default return value
-	retval := WeatherReport{}
-
 	//	Parse the request
 	request := WeatherRequest{}
 	err := json.NewDecoder(req.Body).Decode(&request)
@@ -205,173 +342,42 @@ func (s Service) GetWeatherReport(rw http.ResponseWriter, req *http.Request) {
 			"error", err,
 		)
 		txn.NoticeError(err)
-		sendErrorResponse(rw, err, http.StatusBadRequest)
+		sendErrorResponse(rw, newBadRequestError(err))
 		return
 	}
 
 	//	Make sure we have minimum args:
 	if request.Latitude == "" || request.Longitude == "" {
-		sendErrorResponse(rw, fmt.Errorf("you must include a valid lat and long param"), http.StatusBadRequest)
-		return
-	}
-
-	//	Get the api key:
-	apikey := os.Getenv("OPENWEATHER_API_KEY")
-	if apikey == "" {
-		zlog.Errorw(
-			"{OPENWEATHER_API_KEY} key is blank but shouldn't be",
-		)
-		sendErrorResponse(rw, fmt.Errorf("{OPENWEATHER_API_KEY} key is blank but shouldn't be"), http.StatusBadRequest)
+		sendErrorResponse(rw, newBadRequestError(fmt.Errorf("you must include a valid lat and long param")))
 		return
 	}
 
-	//	Create our request:
-	clientRequest, err := http.NewRequest("GET", "https://api.openweathermap.org/data/2.5/onecall", nil)
+	//	Make sure we know the units requested
+	request.Units, err = normalizeUnits(request.Units)
 	if err != nil {
-		zlog.Errorw(
-			"problem creating request to openweather",
-			"error", err,
-		)
-		txn.NoticeError(err)
-		return
-	}
-
-	q := clientRequest.URL.Query()
-	q.Add("lat", request.Latitude)
-	q.Add("lon", request.Longitude)
-	q.Add("units", "imperial")
-	q.Add("appid", apikey)
-	clientRequest.URL.RawQuery = q.Encode()
-
-	//	Set our headers
-	clientRequest.Header.Set("Content-Type", "application/json; charset=UTF-8")
-	clientRequest = newrelic.RequestWithTransactionContext(clientRequest, txn)
-
-	//	Execute the request
-	client := &http.Client{}
-	client.Transport = newrelic.NewRoundTripper(client.Transport)
-	clientResponse, err := ctxhttp.Do(req.Context(), client, clientRequest)
-	if err != nil {
-		zlog.Errorw(
-			"error when sending request to OpenWeather API server",
-			"err", err,
-		)
-		txn.NoticeError(err)
+		sendErrorResponse(rw, newBadRequestError(err))
 		return
 	}
-	defer clientResponse.Body.Close()
 
-	//	Decode the response:
-	owResponse := OpenWeatherResponse{}
-	err = json.NewDecoder(clientResponse.Body).Decode(&owResponse)
+	//	Get the weather report (from the cache, if we have it)
+	cacheKey := fmt.Sprintf("weather:%s:%s:%s", coordinateKey(request.Latitude, request.Longitude), request.Units, strings.ToLower(strings.TrimSpace(request.Language)))
+	cached, cacheResult, err := responseCache.GetOrLoad(cacheKey, cacheTTL("weather"), func() (interface{}, error) {
+		ctx, cancel := loaderContext(req.Context())
+		defer cancel()
+		return getConvertedWeatherReport(ctx, request)
+	})
 	if err != nil {
 		zlog.Errorw(
-			"problem decoding the response from the OpenWeather API server",
-			"err", err,
+			"problem getting the weather report",
+			"error", err,
 		)
 		txn.NoticeError(err)
+		sendErrorResponse(rw, err)
 		return
 	}
+	retval := cached.(WeatherReport)
 
-	//	Get the daily points:
-	dailyPoints := []WeatherDataPoint{}
-
-	//	Set the weather data points:
-	for _, item := range owResponse.Daily {
-
-		//	Grab the current daily point
-		dailyPoint := WeatherDataPoint{
-			ApparentTemperature: item.FeelsLike.Day,
-			CloudCover:          float64(item.Clouds),
-			Humidity:            float64(item.Humidity),
-			Icon:                item.Weather[0].Icon,
-			UVIndex:             item.Uvi,
-			PrecipAccumulation:  item.Rain,
-			PrecipProbability:   item.Pop,
-			Pressure:            float64(item.Pressure),
-			Summary:             item.Weather[0].Description,
-			TemperatureMin:      item.Temp.Min,
-			Temperature:         item.Temp.Day,
-			TemperatureMax:      item.Temp.Max,
-			Time:                int64(item.Dt),
-			WindBearing:         float64(item.WindDeg),
-			WindGust:            item.WindGust,
-			WindSpeed:           item.WindSpeed,
-		}
-
-		//	Add our daily point:
-		dailyPoints = append(dailyPoints, dailyPoint)
-	}
-
-	//	Get the hourly points:
-	hourlyPoints := []WeatherDataPoint{}
-
-	//	Set the weather data points:
-	for _, item := range owResponse.Hourly {
-
-		//	Grab the current hourly point
-		hourlyPoint := WeatherDataPoint{
-			ApparentTemperature: item.FeelsLike,
-			CloudCover:          float64(item.Clouds),
-			Humidity:            float64(item.Humidity),
-			Icon:                item.Weather[0].Icon,
-			UVIndex:             item.Uvi,
-			PrecipAccumulation:  item.Rain.OneH,
-			PrecipProbability:   item.Pop,
-			Pressure:            float64(item.Pressure),
-			Summary:             item.Weather[0].Description,
-			Temperature:         item.Temp,
-			TemperatureMax:      item.Temp,
-			Time:                int64(item.Dt),
-			WindBearing:         item.WindDeg,
-			WindGust:            item.WindGust,
-			WindSpeed:           item.WindSpeed,
-		}
-
-		//	Add our point:
-		hourlyPoints = append(hourlyPoints, hourlyPoint)
-	}
-
-	//	Get the minutely points:
-	minutelyPoints := []MinuteDataPoint{}
-
-	//	Set the weather data points:
-	for _, item := range owResponse.Minutely {
-
-		//	Grab the current hourly point
-		minutePoint := MinuteDataPoint{
-			DateTime:      int64(item.Dt),
-			Precipitation: item.Precipitation,
-		}
-
-		//	Add our point:
-		minutelyPoints = append(minutelyPoints, minutePoint)
-	}
-
-	//	Format our weather report
-	retval = WeatherReport{
-		Latitude:  owResponse.Lat,
-		Longitude: owResponse.Lon,
-		Currently: WeatherDataPoint{
-			ApparentTemperature: owResponse.Current.FeelsLike,
-			CloudCover:          float64(owResponse.Current.Clouds),
-			Humidity:            float64(owResponse.Current.Humidity),
-			Icon:                owResponse.Current.Weather[0].Icon,
-			UVIndex:             owResponse.Current.Uvi,
-			PrecipAccumulation:  owResponse.Current.Rain.LastHour,
-			Pressure:            float64(owResponse.Current.Pressure),
-			Summary:             owResponse.Current.Weather[0].Description,
-			Temperature:         owResponse.Current.Temp,
-			Time:                int64(owResponse.Current.Dt),
-			WindBearing:         float64(owResponse.Current.WindDeg),
-			WindSpeed:           owResponse.Current.WindSpeed,
-		},
-		Hourly:   hourlyPoints,
-		Minutely: minutelyPoints,
-		Daily: WeatherDataBlock{
-			Data: dailyPoints,
-		},
-	}
+	setCacheHeaders(rw, cacheResult)
 
 	//	Serialize to JSON & return the response:
 	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
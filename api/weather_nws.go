@@ -0,0 +1,336 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/httpclient"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// NWSWeatherService is a weather provider for the National Weather Service gridpoint forecast
+type NWSWeatherService struct{}
+
+// NWSForecastResponse defines the expected response from the NWS gridpoint forecast services
+// (both the 12 hour 'forecast' and the 'forecastHourly' urls use this format)
+type NWSForecastResponse struct {
+	Type       string `json:"type"`
+	Properties struct {
+		Updated     time.Time           `json:"updated"`
+		Units       string              `json:"units"`
+		GeneratedAt time.Time           `json:"generatedAt"`
+		Periods     []NWSForecastPeriod `json:"periods"`
+	} `json:"properties"`
+}
+
+// NWSForecastPeriod defines a single period in an NWS gridpoint forecast
+type NWSForecastPeriod struct {
+	Number                     int                  `json:"number"`
+	Name                       string               `json:"name"`
+	StartTime                  time.Time            `json:"startTime"`
+	EndTime                    time.Time            `json:"endTime"`
+	IsDaytime                  bool                 `json:"isDaytime"`
+	Temperature                float64              `json:"temperature"`
+	TemperatureUnit            string               `json:"temperatureUnit"`
+	ProbabilityOfPrecipitation NWSQuantitativeValue `json:"probabilityOfPrecipitation"`
+	Dewpoint                   NWSQuantitativeValue `json:"dewpoint"`
+	RelativeHumidity           NWSQuantitativeValue `json:"relativeHumidity"`
+	WindSpeed                  string               `json:"windSpeed"`     // Wind speed (or range) like '5 to 10 mph'
+	WindDirection              string               `json:"windDirection"` // Compass direction like 'NNW'
+	Icon                       string               `json:"icon"`
+	ShortForecast              string               `json:"shortForecast"`
+	DetailedForecast           string               `json:"detailedForecast"`
+}
+
+// NWSQuantitativeValue defines an NWS value with a unit code
+type NWSQuantitativeValue struct {
+	UnitCode string  `json:"unitCode"`
+	Value    float64 `json:"value"`
+}
+
+var (
+	// nwsNumberExp finds the numbers in a string like '5 to 10 mph'
+	nwsNumberExp = regexp.MustCompile(`\d+(\.\d+)?`)
+
+	// nwsCompassPoints are the compass directions used by NWS, in clockwise order from north
+	nwsCompassPoints = []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+
+	// nwsIconCodes maps NWS icon conditions to the OpenWeather icon codes the dashboard uses
+	nwsIconCodes = map[string]string{
+		"skc":             "01",
+		"few":             "02",
+		"sct":             "03",
+		"bkn":             "04",
+		"ovc":             "04",
+		"wind_skc":        "01",
+		"wind_few":        "02",
+		"wind_sct":        "03",
+		"wind_bkn":        "04",
+		"wind_ovc":        "04",
+		"hot":             "01",
+		"cold":            "01",
+		"rain":            "10",
+		"rain_showers":    "09",
+		"rain_showers_hi": "09",
+		"tsra":            "11",
+		"tsra_sct":        "11",
+		"tsra_hi":         "11",
+		"tornado":         "11",
+		"hurricane":       "11",
+		"tropical_storm":  "11",
+		"snow":            "13",
+		"rain_snow":       "13",
+		"rain_sleet":      "13",
+		"snow_sleet":      "13",
+		"fzra":            "13",
+		"rain_fzra":       "13",
+		"snow_fzra":       "13",
+		"sleet":           "13",
+		"blizzard":        "13",
+		"fog":             "50",
+		"dust":            "50",
+		"smoke":           "50",
+		"haze":            "50",
+	}
+)
+
+// Name gets the display name of the provider
+func (s NWSWeatherService) Name() string {
+	return "NWS"
+}
+
+// GetWeatherReport gets the weather report
+func (s NWSWeatherService) GetWeatherReport(ctx context.Context, request WeatherRequest) (WeatherReport, error) {
+
+	txn := newrelic.FromContext(ctx)
+	segment := txn.StartSegment("NWS GetWeatherReport")
+	defer segment.End()
+
+	//	Our return value
+	retval := WeatherReport{}
+
+	//	First, find the forecast urls for the lat/long specified
+	pointsResponse, err := getNWSPoints(ctx, request.Latitude, request.Longitude)
+	if err != nil {
+		txn.NoticeError(err)
+		return retval, err
+	}
+
+	//	Get the hourly forecast (used for the current conditions and hourly points)
+	hourlyForecast, err := getNWSForecast(ctx, pointsResponse.Properties.ForecastHourly)
+	if err != nil {
+		txn.NoticeError(err)
+		return retval, err
+	}
+
+	//	Get the 12 hour forecast (used for the daily points)
+	forecast, err := getNWSForecast(ctx, pointsResponse.Properties.Forecast)
+	if err != nil {
+		txn.NoticeError(err)
+		return retval, err
+	}
+
+	//	Get the hourly points:
+	hourlyPoints := []WeatherDataPoint{}
+
+	//	Set the weather data points:
+	for _, period := range hourlyForecast.Properties.Periods {
+
+		//	Only include the next 48 hours (just like the other providers)
+		if len(hourlyPoints) == 48 {
+			break
+		}
+
+		hourlyPoints = append(hourlyPoints, nwsPeriodToDataPoint(period))
+	}
+
+	//	Get the daily points:
+	dailyPoints := nwsDailyPoints(forecast.Properties.Periods)
+
+	//	Format our weather report
+	retval = WeatherReport{
+		Latitude:  pointsResponse.Geometry.Coordinates[1],
+		Longitude: pointsResponse.Geometry.Coordinates[0],
+		Hourly:    hourlyPoints,
+		Minutely:  []MinuteDataPoint{},
+		Alerts:    []AlertItem{},
+		Daily: WeatherDataBlock{
+			Data: dailyPoints,
+		},
+	}
+
+	//	The current conditions are the first hourly forecast period
+	if len(hourlyPoints) > 0 {
+		retval.Currently = hourlyPoints[0]
+		retval.Currently.Time = time.Now().Unix()
+	}
+
+	return retval, nil
+}
+
+// getNWSForecast gets the NWS gridpoint forecast at the given forecast url
+func getNWSForecast(ctx context.Context, forecastUrl string) (NWSForecastResponse, error) {
+
+	txn := newrelic.FromContext(ctx)
+
+	//	Our return value
+	retval := NWSForecastResponse{}
+
+	if forecastUrl == "" {
+		return retval, newUpstreamBadDataError(fmt.Errorf("the NWS points service didn't include a forecast url"))
+	}
+
+	clientRequest, err := http.NewRequest("GET", forecastUrl, nil)
+	if err != nil {
+		zlog.Errorw(
+			"problem creating request to the NWS forecast service",
+			"error", err,
+		)
+		return retval, newInternalError(fmt.Errorf("problem creating request to the NWS forecast service: %v", err))
+	}
+
+	//	Set our headers
+	clientRequest.Header.Set("Content-Type", "application/geo+json; charset=UTF-8")
+	clientRequest = newrelic.RequestWithTransactionContext(clientRequest, txn)
+
+	//	Execute the request
+	clientResponse, err := ctxhttp.Do(ctx, httpclient.Default(), clientRequest)
+	if err != nil {
+		zlog.Errorw(
+			"error when sending request to the NWS forecast service",
+			"error", err,
+		)
+		return retval, newUpstreamError(fmt.Errorf("error when sending request to the NWS forecast service: %w", err))
+	}
+	defer clientResponse.Body.Close()
+
+	//	If the HTTP status code indicates an error, report it and get out
+	if clientResponse.StatusCode >= 400 {
+		return retval, newUpstreamStatusError(fmt.Errorf("error getting information from the NWS forecast service: %s", clientResponse.Status), clientResponse.StatusCode)
+	}
+
+	//	Decode the response:
+	err = json.NewDecoder(clientResponse.Body).Decode(&retval)
+	if err != nil {
+		zlog.Errorw(
+			"problem decoding the response from the NWS forecast service",
+			"error", err,
+		)
+		return retval, newUpstreamBadDataError(fmt.Errorf("problem decoding the response from the NWS forecast service: %v", err))
+	}
+
+	return retval, nil
+}
+
+// nwsDailyPoints converts the periods of a 12 hour NWS forecast to daily points.  NWS reports daytime
+// and overnight periods separately.  Each daytime period starts a new day, and the overnight period
+// after it provides the low temperature for that day
+func nwsDailyPoints(periods []NWSForecastPeriod) []WeatherDataPoint {
+	retval := []WeatherDataPoint{}
+
+	for _, period := range periods {
+
+		if period.IsDaytime || len(retval) == 0 {
+			dailyPoint := nwsPeriodToDataPoint(period)
+			dailyPoint.TemperatureMin = dailyPoint.Temperature
+			retval = append(retval, dailyPoint)
+			continue
+		}
+
+		//	Overnight period: set the low for the current day
+		retval[len(retval)-1].TemperatureMin = nwsTemperature(period.Temperature, period.TemperatureUnit)
+	}
+
+	return retval
+}
+
+// nwsPeriodToDataPoint converts an NWS forecast period to a weather data point (in canonical units)
+func nwsPeriodToDataPoint(period NWSForecastPeriod) WeatherDataPoint {
+	return WeatherDataPoint{
+		Time:              period.StartTime.Unix(),
+		Summary:           period.ShortForecast,
+		Icon:              nwsIconCode(period.Icon, period.IsDaytime),
+		PrecipProbability: period.ProbabilityOfPrecipitation.Value / 100,
+		Temperature:       nwsTemperature(period.Temperature, period.TemperatureUnit),
+		TemperatureMax:    nwsTemperature(period.Temperature, period.TemperatureUnit),
+		Humidity:          period.RelativeHumidity.Value,
+		DewPoint:          period.Dewpoint.Value,
+		WindSpeed:         nwsWindSpeed(period.WindSpeed),
+		WindBearing:       nwsWindBearing(period.WindDirection),
+	}
+}
+
+// nwsTemperature converts an NWS temperature (in the given 'F' or 'C' unit) to Celsius
+func nwsTemperature(temperature float64, unit string) float64 {
+	if strings.EqualFold(unit, "F") {
+		return fahrenheitToCelsius(temperature)
+	}
+	return temperature
+}
+
+// nwsWindSpeed parses an NWS wind speed (like '10 mph' or '5 to 10 km/h') and returns
+// the highest speed mentioned in meters/sec
+func nwsWindSpeed(windSpeed string) float64 {
+	retval := 0.0
+
+	for _, number := range nwsNumberExp.FindAllString(windSpeed, -1) {
+		speed, err := strconv.ParseFloat(number, 64)
+		if err == nil && speed > retval {
+			retval = speed
+		}
+	}
+
+	if strings.Contains(windSpeed, "km/h") {
+		return kphToMetersPerSecond(retval)
+	}
+
+	return mphToMetersPerSecond(retval)
+}
+
+// nwsWindBearing converts an NWS compass direction (like 'NNW') to a bearing in degrees
+func nwsWindBearing(direction string) float64 {
+	direction = strings.ToUpper(strings.TrimSpace(direction))
+
+	for index, point := range nwsCompassPoints {
+		if point == direction {
+			return float64(index) * 22.5
+		}
+	}
+
+	return 0
+}
+
+// nwsIconCode converts an NWS icon url (like https://api.weather.gov/icons/land/day/tsra_hi,40/sct?size=medium)
+// to an OpenWeather icon code (like '11d')
+func nwsIconCode(iconUrl string, isDaytime bool) string {
+
+	//	Find the first condition in the icon url
+	condition := iconUrl
+	if index := strings.Index(condition, "/land/"); index >= 0 {
+		condition = condition[index+len("/land/"):]
+	}
+	if index := strings.Index(condition, "/"); index >= 0 {
+		condition = condition[index+1:]
+	}
+	if index := strings.IndexAny(condition, ",/?"); index >= 0 {
+		condition = condition[:index]
+	}
+
+	code, found := nwsIconCodes[condition]
+	if !found {
+		return ""
+	}
+
+	if isDaytime {
+		return code + "d"
+	}
+
+	return code + "n"
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestNWSWindSpeed_ParsesHighestSpeed(t *testing.T) {
+	//	Arrange
+	tests := []struct {
+		windSpeed string
+		expected  float64
+	}{
+		{"10 mph", 4.4704},
+		{"5 to 10 mph", 4.4704},
+		{"15 to 25 km/h", 6.9444},
+		{"2.5 mph", 1.1176},
+		{"", 0},
+		{"calm", 0},
+	}
+
+	for _, test := range tests {
+		//	Act
+		speed := nwsWindSpeed(test.windSpeed)
+
+		//	Assert
+		if math.Abs(speed-test.expected) > 0.001 {
+			t.Errorf("Expected %v m/s for '%v' but got %v instead", test.expected, test.windSpeed, speed)
+		}
+	}
+}
+
+func TestNWSWindBearing_ConvertsCompassPoints(t *testing.T) {
+	//	Arrange
+	tests := []struct {
+		direction string
+		expected  float64
+	}{
+		{"N", 0},
+		{"NNE", 22.5},
+		{"E", 90},
+		{"ssw", 202.5},
+		{" WNW ", 292.5},
+		{"NNW", 337.5},
+		{"", 0},
+		{"variable", 0},
+	}
+
+	for _, test := range tests {
+		//	Act
+		bearing := nwsWindBearing(test.direction)
+
+		//	Assert
+		if bearing != test.expected {
+			t.Errorf("Expected a bearing of %v for '%v' but got %v instead", test.expected, test.direction, bearing)
+		}
+	}
+}
+
+func TestNWSIconCode_UsesFirstCondition(t *testing.T) {
+	//	Arrange
+	tests := []struct {
+		icon      string
+		isDaytime bool
+		expected  string
+	}{
+		{"https://api.weather.gov/icons/land/day/skc?size=medium", true, "01d"},
+		{"https://api.weather.gov/icons/land/night/few?size=medium", false, "02n"},
+		{"https://api.weather.gov/icons/land/day/tsra_hi,40/sct?size=medium", true, "11d"},
+		{"https://api.weather.gov/icons/land/night/rain_showers,30/rain,60?size=small", false, "09n"},
+		{"https://api.weather.gov/icons/land/day/snow,80", true, "13d"},
+		{"https://api.weather.gov/icons/land/day/fog", true, "50d"},
+		{"https://api.weather.gov/icons/land/day/something_new?size=medium", true, ""},
+		{"", true, ""},
+	}
+
+	for _, test := range tests {
+		//	Act
+		code := nwsIconCode(test.icon, test.isDaytime)
+
+		//	Assert
+		if code != test.expected {
+			t.Errorf("Expected icon code '%v' for %v but got '%v' instead", test.expected, test.icon, code)
+		}
+	}
+}
+
+func TestNWSDailyPoints_PairsDaytimeHighsWithOvernightLows(t *testing.T) {
+	//	Arrange
+	body, err := os.ReadFile("testdata/weather/nws_forecast.json")
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	forecast := NWSForecastResponse{}
+	if err := json.Unmarshal(body, &forecast); err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	//	Act
+	points := nwsDailyPoints(forecast.Properties.Periods)
+
+	//	Assert
+	//	The forecast starts at night, so tonight is its own day.  Thursday's low comes from Thursday night,
+	//	and Friday has no overnight period yet (so its low is its high)
+	expected := []struct {
+		high, low float64
+		icon      string
+		precip    float64
+		windSpeed float64
+		bearing   float64
+	}{
+		{15, 15, "02n", 0.2, 2.2352, 180},
+		{30, 17.7778, "11d", 0.4, 4.4704, 247.5},
+		{26.1111, 26.1111, "01d", 0, 4.4704, 0},
+	}
+
+	if len(points) != len(expected) {
+		t.Fatalf("Expected %v daily points but got %v instead", len(expected), len(points))
+	}
+
+	for i, point := range points {
+		if math.Abs(point.TemperatureMax-expected[i].high) > 0.001 || math.Abs(point.TemperatureMin-expected[i].low) > 0.001 {
+			t.Errorf("Expected day %v to have a high of %v and low of %v but got %v and %v instead", i, expected[i].high, expected[i].low, point.TemperatureMax, point.TemperatureMin)
+		}
+
+		if point.Icon != expected[i].icon || math.Abs(point.PrecipProbability-expected[i].precip) > 0.001 {
+			t.Errorf("Expected day %v to have icon %v and precipitation chance %v but got %v and %v instead", i, expected[i].icon, expected[i].precip, point.Icon, point.PrecipProbability)
+		}
+
+		if math.Abs(point.WindSpeed-expected[i].windSpeed) > 0.001 || point.WindBearing != expected[i].bearing {
+			t.Errorf("Expected day %v to have wind %v m/s from %v but got %v m/s from %v instead", i, expected[i].windSpeed, expected[i].bearing, point.WindSpeed, point.WindBearing)
+		}
+	}
+
+	if points[1].Time != forecast.Properties.Periods[1].StartTime.Unix() || points[1].Summary != "Chance Showers And Thunderstorms" {
+		t.Errorf("Expected Thursday to start with the daytime period but got %+v instead", points[1])
+	}
+}
@@ -0,0 +1,322 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/danesparza/daydash-service/internal/httpclient"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// OpenMeteoService is a weather provider for the Open-Meteo forecast API
+type OpenMeteoService struct{}
+
+// OpenMeteoResponse is the native service return format
+type OpenMeteoResponse struct {
+	Latitude         float64 `json:"latitude"`
+	Longitude        float64 `json:"longitude"`
+	Timezone         string  `json:"timezone"`
+	UTCOffsetSeconds int     `json:"utc_offset_seconds"`
+	Current          struct {
+		Time                int64   `json:"time"`
+		Temperature         float64 `json:"temperature_2m"`
+		RelativeHumidity    float64 `json:"relative_humidity_2m"`
+		ApparentTemperature float64 `json:"apparent_temperature"`
+		DewPoint            float64 `json:"dew_point_2m"`
+		IsDay               int     `json:"is_day"`
+		Precipitation       float64 `json:"precipitation"`
+		WeatherCode         int     `json:"weather_code"`
+		CloudCover          float64 `json:"cloud_cover"`
+		PressureMSL         float64 `json:"pressure_msl"`
+		WindSpeed           float64 `json:"wind_speed_10m"`
+		WindDirection       float64 `json:"wind_direction_10m"`
+		WindGusts           float64 `json:"wind_gusts_10m"`
+	} `json:"current"`
+	Hourly struct {
+		Time                     []int64   `json:"time"`
+		Temperature              []float64 `json:"temperature_2m"`
+		RelativeHumidity         []float64 `json:"relative_humidity_2m"`
+		ApparentTemperature      []float64 `json:"apparent_temperature"`
+		DewPoint                 []float64 `json:"dew_point_2m"`
+		PrecipitationProbability []float64 `json:"precipitation_probability"`
+		Precipitation            []float64 `json:"precipitation"`
+		WeatherCode              []int     `json:"weather_code"`
+		PressureMSL              []float64 `json:"pressure_msl"`
+		CloudCover               []float64 `json:"cloud_cover"`
+		Visibility               []float64 `json:"visibility"`
+		WindSpeed                []float64 `json:"wind_speed_10m"`
+		WindDirection            []float64 `json:"wind_direction_10m"`
+		WindGusts                []float64 `json:"wind_gusts_10m"`
+		UVIndex                  []float64 `json:"uv_index"`
+		IsDay                    []int     `json:"is_day"`
+	} `json:"hourly"`
+	Daily struct {
+		Time                        []int64   `json:"time"`
+		WeatherCode                 []int     `json:"weather_code"`
+		TemperatureMax              []float64 `json:"temperature_2m_max"`
+		TemperatureMin              []float64 `json:"temperature_2m_min"`
+		ApparentTemperatureMax      []float64 `json:"apparent_temperature_max"`
+		Sunrise                     []int64   `json:"sunrise"`
+		Sunset                      []int64   `json:"sunset"`
+		DaylightDuration            []float64 `json:"daylight_duration"`
+		PrecipitationSum            []float64 `json:"precipitation_sum"`
+		PrecipitationProbabilityMax []float64 `json:"precipitation_probability_max"`
+		WindSpeedMax                []float64 `json:"wind_speed_10m_max"`
+		WindGustsMax                []float64 `json:"wind_gusts_10m_max"`
+		WindDirectionDominant       []float64 `json:"wind_direction_10m_dominant"`
+		UVIndexMax                  []float64 `json:"uv_index_max"`
+	} `json:"daily"`
+}
+
+// openMeteoWeatherCode describes a WMO weather interpretation code
+type openMeteoWeatherCode struct {
+	Description string
+	Icon        string // OpenWeather icon code (without the day/night suffix)
+}
+
+// openMeteoWeatherCodes maps the WMO weather interpretation codes used by Open-Meteo
+// to descriptions and the OpenWeather icon codes the dashboard uses
+var openMeteoWeatherCodes = map[int]openMeteoWeatherCode{
+	0:  {"clear sky", "01"},
+	1:  {"mainly clear", "02"},
+	2:  {"partly cloudy", "03"},
+	3:  {"overcast", "04"},
+	45: {"fog", "50"},
+	48: {"depositing rime fog", "50"},
+	51: {"light drizzle", "09"},
+	53: {"moderate drizzle", "09"},
+	55: {"dense drizzle", "09"},
+	56: {"light freezing drizzle", "09"},
+	57: {"dense freezing drizzle", "09"},
+	61: {"slight rain", "10"},
+	63: {"moderate rain", "10"},
+	65: {"heavy rain", "10"},
+	66: {"light freezing rain", "13"},
+	67: {"heavy freezing rain", "13"},
+	71: {"slight snow fall", "13"},
+	73: {"moderate snow fall", "13"},
+	75: {"heavy snow fall", "13"},
+	77: {"snow grains", "13"},
+	80: {"slight rain showers", "09"},
+	81: {"moderate rain showers", "09"},
+	82: {"violent rain showers", "09"},
+	85: {"slight snow showers", "13"},
+	86: {"heavy snow showers", "13"},
+	95: {"thunderstorm", "11"},
+	96: {"thunderstorm with slight hail", "11"},
+	99: {"thunderstorm with heavy hail", "11"},
+}
+
+// Name gets the display name of the provider
+func (s OpenMeteoService) Name() string {
+	return "Open-Meteo"
+}
+
+// GetWeatherReport gets the weather report
+func (s OpenMeteoService) GetWeatherReport(ctx context.Context, request WeatherRequest) (WeatherReport, error) {
+
+	txn := newrelic.FromContext(ctx)
+	segment := txn.StartSegment("OpenMeteo GetWeatherReport")
+	defer segment.End()
+
+	//	Our return value
+	retval := WeatherReport{}
+
+	//	Create our request:
+	clientRequest, err := http.NewRequest("GET", "https://api.open-meteo.com/v1/forecast", nil)
+	if err != nil {
+		zlog.Errorw(
+			"problem creating request to Open-Meteo",
+			"error", err,
+		)
+		txn.NoticeError(err)
+		return retval, newInternalError(fmt.Errorf("problem creating request to Open-Meteo: %v", err))
+	}
+
+	q := clientRequest.URL.Query()
+	q.Add("latitude", request.Latitude)
+	q.Add("longitude", request.Longitude)
+	q.Add("current", "temperature_2m,relative_humidity_2m,apparent_temperature,dew_point_2m,is_day,precipitation,weather_code,cloud_cover,pressure_msl,wind_speed_10m,wind_direction_10m,wind_gusts_10m")
+	q.Add("hourly", "temperature_2m,relative_humidity_2m,apparent_temperature,dew_point_2m,precipitation_probability,precipitation,weather_code,pressure_msl,cloud_cover,visibility,wind_speed_10m,wind_direction_10m,wind_gusts_10m,uv_index,is_day")
+	q.Add("daily", "weather_code,temperature_2m_max,temperature_2m_min,apparent_temperature_max,sunrise,sunset,daylight_duration,precipitation_sum,precipitation_probability_max,wind_speed_10m_max,wind_gusts_10m_max,wind_direction_10m_dominant,uv_index_max")
+	q.Add("forecast_days", "8")
+	q.Add("forecast_hours", "48")
+	q.Add("wind_speed_unit", "ms")
+	q.Add("timeformat", "unixtime")
+	q.Add("timezone", "auto")
+	clientRequest.URL.RawQuery = q.Encode()
+
+	//	Set our headers
+	clientRequest.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	clientRequest = newrelic.RequestWithTransactionContext(clientRequest, txn)
+
+	//	Execute the request
+	clientResponse, err := ctxhttp.Do(ctx, httpclient.Default(), clientRequest)
+	if err != nil {
+		zlog.Errorw(
+			"error when sending request to Open-Meteo API server",
+			"error", err,
+		)
+		txn.NoticeError(err)
+		return retval, newUpstreamError(fmt.Errorf("error when sending request to Open-Meteo API server: %w", err))
+	}
+	defer clientResponse.Body.Close()
+
+	//	If the HTTP status code indicates an error, report it and get out
+	if clientResponse.StatusCode >= 400 {
+		apperr := newUpstreamStatusError(fmt.Errorf("error getting information from Open-Meteo API server: %s", clientResponse.Status), clientResponse.StatusCode)
+		txn.NoticeError(apperr)
+		return retval, apperr
+	}
+
+	//	Decode the response:
+	omResponse := OpenMeteoResponse{}
+	err = json.NewDecoder(clientResponse.Body).Decode(&omResponse)
+	if err != nil {
+		zlog.Errorw(
+			"problem decoding the response from the Open-Meteo API server",
+			"error", err,
+		)
+		txn.NoticeError(err)
+		return retval, newUpstreamBadDataError(fmt.Errorf("problem decoding the response from the Open-Meteo API server: %v", err))
+	}
+
+	return newOpenMeteoReport(omResponse), nil
+}
+
+// newOpenMeteoReport converts an Open-Meteo response to a weather report.  Open-Meteo returns each value as
+// its own array, so a missing (or short) array just leaves that value as zero
+func newOpenMeteoReport(omResponse OpenMeteoResponse) WeatherReport {
+
+	//	Get the daily points:
+	dailyPoints := []WeatherDataPoint{}
+	daily := omResponse.Daily
+
+	//	Set the weather data points:
+	for i, dt := range daily.Time {
+		weatherCode := openMeteoWeatherCodes[intAt(daily.WeatherCode, i)]
+
+		dailyPoint := WeatherDataPoint{
+			ApparentTemperature: floatAt(daily.ApparentTemperatureMax, i),
+			DaylightDuration:    int64(floatAt(daily.DaylightDuration, i)),
+			Icon:                openMeteoIcon(weatherCode, true),
+			UVIndex:             floatAt(daily.UVIndexMax, i),
+			PrecipAccumulation:  floatAt(daily.PrecipitationSum, i),
+			PrecipProbability:   floatAt(daily.PrecipitationProbabilityMax, i) / 100,
+			Summary:             weatherCode.Description,
+			Sunrise:             int64At(daily.Sunrise, i),
+			Sunset:              int64At(daily.Sunset, i),
+			TemperatureMin:      floatAt(daily.TemperatureMin, i),
+			Temperature:         floatAt(daily.TemperatureMax, i),
+			TemperatureMax:      floatAt(daily.TemperatureMax, i),
+			Time:                dt,
+			WindBearing:         floatAt(daily.WindDirectionDominant, i),
+			WindGust:            floatAt(daily.WindGustsMax, i),
+			WindSpeed:           floatAt(daily.WindSpeedMax, i),
+		}
+
+		//	Add our daily point:
+		dailyPoints = append(dailyPoints, dailyPoint)
+	}
+
+	//	Get the hourly points:
+	hourlyPoints := []WeatherDataPoint{}
+	hourly := omResponse.Hourly
+
+	//	Set the weather data points:
+	for i, dt := range hourly.Time {
+		weatherCode := openMeteoWeatherCodes[intAt(hourly.WeatherCode, i)]
+
+		hourlyPoint := WeatherDataPoint{
+			ApparentTemperature: floatAt(hourly.ApparentTemperature, i),
+			CloudCover:          floatAt(hourly.CloudCover, i),
+			DewPoint:            floatAt(hourly.DewPoint, i),
+			Humidity:            floatAt(hourly.RelativeHumidity, i),
+			Icon:                openMeteoIcon(weatherCode, intAt(hourly.IsDay, i) == 1),
+			UVIndex:             floatAt(hourly.UVIndex, i),
+			PrecipAccumulation:  floatAt(hourly.Precipitation, i),
+			PrecipProbability:   floatAt(hourly.PrecipitationProbability, i) / 100,
+			Pressure:            floatAt(hourly.PressureMSL, i),
+			Summary:             weatherCode.Description,
+			Temperature:         floatAt(hourly.Temperature, i),
+			TemperatureMax:      floatAt(hourly.Temperature, i),
+			Time:                dt,
+			Visibility:          floatAt(hourly.Visibility, i),
+			WindBearing:         floatAt(hourly.WindDirection, i),
+			WindGust:            floatAt(hourly.WindGusts, i),
+			WindSpeed:           floatAt(hourly.WindSpeed, i),
+		}
+
+		//	Add our point:
+		hourlyPoints = append(hourlyPoints, hourlyPoint)
+	}
+
+	//	Format our weather report
+	current := omResponse.Current
+	currentCode := openMeteoWeatherCodes[current.WeatherCode]
+	return WeatherReport{
+		Latitude:  omResponse.Latitude,
+		Longitude: omResponse.Longitude,
+		Currently: WeatherDataPoint{
+			ApparentTemperature: current.ApparentTemperature,
+			CloudCover:          current.CloudCover,
+			DewPoint:            current.DewPoint,
+			Humidity:            current.RelativeHumidity,
+			Icon:                openMeteoIcon(currentCode, current.IsDay == 1),
+			PrecipAccumulation:  current.Precipitation,
+			Pressure:            current.PressureMSL,
+			Summary:             currentCode.Description,
+			Temperature:         current.Temperature,
+			Time:                current.Time,
+			WindBearing:         current.WindDirection,
+			WindGust:            current.WindGusts,
+			WindSpeed:           current.WindSpeed,
+		},
+		Hourly:   hourlyPoints,
+		Minutely: []MinuteDataPoint{},
+		Alerts:   []AlertItem{},
+		Daily: WeatherDataBlock{
+			Data: dailyPoints,
+		},
+	}
+}
+
+// openMeteoIcon gets the OpenWeather icon code for the given weather code
+func openMeteoIcon(code openMeteoWeatherCode, isDay bool) string {
+	if code.Icon == "" {
+		return ""
+	}
+
+	if isDay {
+		return code.Icon + "d"
+	}
+
+	return code.Icon + "n"
+}
+
+// floatAt safely gets the value at the given index (or zero if it doesn't exist)
+func floatAt(values []float64, index int) float64 {
+	if index < len(values) {
+		return values[index]
+	}
+	return 0
+}
+
+// intAt safely gets the value at the given index (or zero if it doesn't exist)
+func intAt(values []int, index int) int {
+	if index < len(values) {
+		return values[index]
+	}
+	return 0
+}
+
+// int64At safely gets the value at the given index (or zero if it doesn't exist)
+func int64At(values []int64, index int) int64 {
+	if index < len(values) {
+		return values[index]
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestOpenMeteoIcon_MapsWeatherCodes(t *testing.T) {
+	//	Arrange
+	tests := []struct {
+		code        int
+		isDay       bool
+		icon        string
+		description string
+	}{
+		{0, true, "01d", "clear sky"},
+		{1, false, "02n", "mainly clear"},
+		{3, true, "04d", "overcast"},
+		{45, true, "50d", "fog"},
+		{55, false, "09n", "dense drizzle"},
+		{63, true, "10d", "moderate rain"},
+		{66, true, "13d", "light freezing rain"},
+		{75, false, "13n", "heavy snow fall"},
+		{82, true, "09d", "violent rain showers"},
+		{99, true, "11d", "thunderstorm with heavy hail"},
+		{42, true, "", ""},
+	}
+
+	for _, test := range tests {
+		//	Act
+		code := openMeteoWeatherCodes[test.code]
+		icon := openMeteoIcon(code, test.isDay)
+
+		//	Assert
+		if icon != test.icon || code.Description != test.description {
+			t.Errorf("Expected '%v' (%v) for weather code %v but got '%v' (%v) instead", test.icon, test.description, test.code, icon, code.Description)
+		}
+	}
+}
+
+func TestFloatAt_MissingIndexIsZero(t *testing.T) {
+	//	Arrange
+	tests := []struct {
+		values   []float64
+		index    int
+		expected float64
+	}{
+		{[]float64{1.5, 2.5}, 0, 1.5},
+		{[]float64{1.5, 2.5}, 1, 2.5},
+		{[]float64{1.5, 2.5}, 2, 0},
+		{[]float64{}, 0, 0},
+		{nil, 3, 0},
+	}
+
+	for _, test := range tests {
+		//	Act
+		value := floatAt(test.values, test.index)
+
+		//	Assert
+		if value != test.expected {
+			t.Errorf("Expected %v at index %v of %v but got %v instead", test.expected, test.index, test.values, value)
+		}
+	}
+}
+
+func TestNewOpenMeteoReport_MapsFixture(t *testing.T) {
+	//	Arrange
+	body, err := os.ReadFile("testdata/weather/openmeteo_forecast.json")
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	response := OpenMeteoResponse{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	//	Act
+	report := newOpenMeteoReport(response)
+
+	//	Assert
+	if report.Latitude != 33.87 || report.Longitude != -84.0 {
+		t.Errorf("Expected the location 33.87,-84 but got %v,%v instead", report.Latitude, report.Longitude)
+	}
+
+	current := report.Currently
+	if current.Temperature != 24.5 || current.Icon != "03d" || current.Summary != "partly cloudy" || current.WindBearing != 225 || current.WindGust != 6.4 {
+		t.Errorf("Expected the current conditions to be mapped but got %+v instead", current)
+	}
+
+	if len(report.Hourly) != 3 {
+		t.Fatalf("Expected 3 hourly points but got %v instead", len(report.Hourly))
+	}
+
+	//	The precipitation probability array is one short, and there's no visibility at all
+	lastHour := report.Hourly[2]
+	if lastHour.Icon != "10n" || lastHour.PrecipProbability != 0 || lastHour.Visibility != 0 || lastHour.Temperature != 26.0 {
+		t.Errorf("Expected the last hour to be rainy at night with no precipitation chance but got %+v instead", lastHour)
+	}
+
+	if report.Hourly[1].PrecipProbability != 0.55 || report.Hourly[1].Icon != "11d" {
+		t.Errorf("Expected a 55%% chance of thunderstorms in the second hour but got %+v instead", report.Hourly[1])
+	}
+
+	if len(report.Daily.Data) != 2 {
+		t.Fatalf("Expected 2 daily points but got %v instead", len(report.Daily.Data))
+	}
+
+	today := report.Daily.Data[0]
+	if today.TemperatureMax != 29.3 || today.TemperatureMin != 18.2 || today.Icon != "11d" || today.PrecipProbability != 0.7 || today.DaylightDuration != 51420 {
+		t.Errorf("Expected today to be mapped but got %+v instead", today)
+	}
+
+	//	An unknown weather code (and a missing precipitation chance) leave those values empty
+	tomorrow := report.Daily.Data[1]
+	if tomorrow.Icon != "" || tomorrow.Summary != "" || tomorrow.PrecipProbability != 0 || tomorrow.Sunrise != 1654164240 {
+		t.Errorf("Expected tomorrow to have no icon or summary but got %+v instead", tomorrow)
+	}
+}
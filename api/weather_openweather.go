@@ -0,0 +1,364 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/httpclient"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// OpenWeatherService is a weather provider for the OpenWeather onecall API
+type OpenWeatherService struct{}
+
+type OpenWeatherRequest struct {
+	Lat     string `json:"lat"`     // Latitude
+	Long    string `json:"lon"`     // Longitude
+	Exclude string `json:"exclude"` // Exclude data from response
+	Units   string `json:"units"`   // Units to use
+	AppId   string `json:"appid"`   // Application id / token
+}
+
+type OpenWeatherResponse struct {
+	Lat            float64 `json:"lat"`
+	Lon            float64 `json:"lon"`
+	Timezone       string  `json:"timezone"`
+	TimezoneOffset int     `json:"timezone_offset"`
+	Current        struct {
+		Dt        int     `json:"dt"`
+		Sunrise   int     `json:"sunrise"`
+		Sunset    int     `json:"sunset"`
+		Temp      float64 `json:"temp"`
+		FeelsLike float64 `json:"feels_like"`
+		Pressure  int     `json:"pressure"`
+		Humidity  int     `json:"humidity"`
+		DewPoint  float64 `json:"dew_point"`
+		Uvi       float64 `json:"uvi"`
+		Clouds    int     `json:"clouds"`
+		Rain      struct {
+			LastHour float64 `json:"1h"`
+		}
+		Visibility int     `json:"visibility"`
+		WindSpeed  float64 `json:"wind_speed"`
+		WindDeg    int     `json:"wind_deg"`
+		Weather    []struct {
+			ID          int    `json:"id"`
+			Main        string `json:"main"`
+			Description string `json:"description"`
+			Icon        string `json:"icon"`
+		} `json:"weather"`
+	} `json:"current"`
+	Hourly []struct {
+		Dt         int     `json:"dt"`
+		Temp       float64 `json:"temp"`
+		FeelsLike  float64 `json:"feels_like"`
+		Pressure   int     `json:"pressure"`
+		Humidity   int     `json:"humidity"`
+		DewPoint   float64 `json:"dew_point"`
+		Uvi        float64 `json:"uvi"`
+		Clouds     int     `json:"clouds"`
+		Visibility int     `json:"visibility"`
+		WindSpeed  float64 `json:"wind_speed"`
+		WindDeg    float64 `json:"wind_deg"`
+		WindGust   float64 `json:"wind_gust"`
+		Weather    []struct {
+			ID          int    `json:"id"`
+			Main        string `json:"main"`
+			Description string `json:"description"`
+			Icon        string `json:"icon"`
+		} `json:"weather"`
+		Pop  float64 `json:"pop"`
+		Rain struct {
+			OneH float64 `json:"1h"`
+		} `json:"rain,omitempty"`
+	} `json:"hourly"`
+	Daily []struct {
+		Dt        int     `json:"dt"`
+		Sunrise   int     `json:"sunrise"`
+		Sunset    int     `json:"sunset"`
+		Moonrise  int     `json:"moonrise"`
+		Moonset   int     `json:"moonset"`
+		MoonPhase float64 `json:"moon_phase"`
+		Temp      struct {
+			Day   float64 `json:"day"`
+			Min   float64 `json:"min"`
+			Max   float64 `json:"max"`
+			Night float64 `json:"night"`
+			Eve   float64 `json:"eve"`
+			Morn  float64 `json:"morn"`
+		} `json:"temp"`
+		FeelsLike struct {
+			Day   float64 `json:"day"`
+			Night float64 `json:"night"`
+			Eve   float64 `json:"eve"`
+			Morn  float64 `json:"morn"`
+		} `json:"feels_like"`
+		Pressure  int     `json:"pressure"`
+		Humidity  int     `json:"humidity"`
+		DewPoint  float64 `json:"dew_point"`
+		WindSpeed float64 `json:"wind_speed"`
+		WindDeg   int     `json:"wind_deg"`
+		WindGust  float64 `json:"wind_gust"`
+		Weather   []struct {
+			ID          int    `json:"id"`
+			Main        string `json:"main"`
+			Description string `json:"description"`
+			Icon        string `json:"icon"`
+		} `json:"weather"`
+		Clouds int     `json:"clouds"`
+		Pop    float64 `json:"pop"`
+		Rain   float64 `json:"rain"`
+		Uvi    float64 `json:"uvi"`
+	} `json:"daily"`
+	Minutely []struct {
+		Dt            int     `json:"dt"`
+		Precipitation float64 `json:"precipitation"`
+	} `json:"minutely"`
+	Alerts []struct {
+		SenderName  string   `json:"sender_name"`
+		Event       string   `json:"event"`
+		Start       float64  `json:"start"`
+		End         float64  `json:"end"`
+		Description string   `json:"description"`
+		Tags        []string `json:"tags"`
+	} `json:"alerts"`
+}
+
+// Name gets the display name of the provider
+func (s OpenWeatherService) Name() string {
+	return "OpenWeather"
+}
+
+// GetWeatherReport gets the weather report
+func (s OpenWeatherService) GetWeatherReport(ctx context.Context, request WeatherRequest) (WeatherReport, error) {
+
+	txn := newrelic.FromContext(ctx)
+	segment := txn.StartSegment("OpenWeather GetWeatherReport")
+	defer segment.End()
+
+	//	Our return value
+	retval := WeatherReport{}
+
+	//	Get the api key:
+	apikey := os.Getenv("OPENWEATHER_API_KEY")
+	if apikey == "" {
+		zlog.Errorw(
+			"{OPENWEATHER_API_KEY} key is blank but shouldn't be",
+		)
+		return retval, newInternalError(fmt.Errorf("{OPENWEATHER_API_KEY} key is blank but shouldn't be"))
+	}
+
+	//	Create our request:
+	clientRequest, err := http.NewRequest("GET", "https://api.openweathermap.org/data/2.5/onecall", nil)
+	if err != nil {
+		zlog.Errorw(
+			"problem creating request to openweather",
+			"error", err,
+		)
+		txn.NoticeError(err)
+		return retval, newInternalError(fmt.Errorf("problem creating request to openweather: %v", err))
+	}
+
+	q := clientRequest.URL.Query()
+	q.Add("lat", request.Latitude)
+	q.Add("lon", request.Longitude)
+	q.Add("units", "metric")
+	q.Add("appid", apikey)
+	if request.Language != "" {
+		q.Add("lang", request.Language)
+	}
+	clientRequest.URL.RawQuery = q.Encode()
+
+	//	Set our headers
+	clientRequest.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	clientRequest = newrelic.RequestWithTransactionContext(clientRequest, txn)
+
+	//	Execute the request
+	clientResponse, err := ctxhttp.Do(ctx, httpclient.Default(), clientRequest)
+	if err != nil {
+		zlog.Errorw(
+			"error when sending request to OpenWeather API server",
+			"err", err,
+		)
+		txn.NoticeError(err)
+		return retval, newUpstreamError(fmt.Errorf("error when sending request to OpenWeather API server: %w", err))
+	}
+	defer clientResponse.Body.Close()
+
+	//	If the HTTP status code indicates an error, report it and get out
+	if clientResponse.StatusCode >= 400 {
+		apperr := newUpstreamStatusError(fmt.Errorf("error getting information from OpenWeather API server: %s", clientResponse.Status), clientResponse.StatusCode)
+		txn.NoticeError(apperr)
+		return retval, apperr
+	}
+
+	//	Decode the response:
+	owResponse := OpenWeatherResponse{}
+	err = json.NewDecoder(clientResponse.Body).Decode(&owResponse)
+	if err != nil {
+		zlog.Errorw(
+			"problem decoding the response from the OpenWeather API server",
+			"err", err,
+		)
+		txn.NoticeError(err)
+		return retval, newUpstreamBadDataError(fmt.Errorf("problem decoding the response from the OpenWeather API server: %v", err))
+	}
+
+	//	Get the daily points:
+	dailyPoints := []WeatherDataPoint{}
+
+	//	Set the weather data points:
+	for _, item := range owResponse.Daily {
+
+		//	Grab the current daily point
+		dailyPoint := WeatherDataPoint{
+			ApparentTemperature: item.FeelsLike.Day,
+			CloudCover:          float64(item.Clouds),
+			DewPoint:            item.DewPoint,
+			Humidity:            float64(item.Humidity),
+			Moonrise:            int64(item.Moonrise),
+			Moonset:             int64(item.Moonset),
+			MoonPhase:           item.MoonPhase,
+			Sunrise:             int64(item.Sunrise),
+			Sunset:              int64(item.Sunset),
+			UVIndex:             item.Uvi,
+			PrecipAccumulation:  item.Rain,
+			PrecipProbability:   item.Pop,
+			Pressure:            float64(item.Pressure),
+			TemperatureMin:      item.Temp.Min,
+			Temperature:         item.Temp.Day,
+			TemperatureMax:      item.Temp.Max,
+			Time:                int64(item.Dt),
+			WindBearing:         float64(item.WindDeg),
+			WindGust:            item.WindGust,
+			WindSpeed:           item.WindSpeed,
+		}
+
+		if len(item.Weather) > 0 {
+			dailyPoint.Icon = item.Weather[0].Icon
+			dailyPoint.Summary = item.Weather[0].Description
+		}
+
+		//	Add our daily point:
+		dailyPoints = append(dailyPoints, dailyPoint)
+	}
+
+	//	Get the hourly points:
+	hourlyPoints := []WeatherDataPoint{}
+
+	//	Set the weather data points:
+	for _, item := range owResponse.Hourly {
+
+		//	Grab the current hourly point
+		hourlyPoint := WeatherDataPoint{
+			ApparentTemperature: item.FeelsLike,
+			CloudCover:          float64(item.Clouds),
+			DewPoint:            item.DewPoint,
+			Humidity:            float64(item.Humidity),
+			UVIndex:             item.Uvi,
+			PrecipAccumulation:  item.Rain.OneH,
+			PrecipProbability:   item.Pop,
+			Pressure:            float64(item.Pressure),
+			Temperature:         item.Temp,
+			TemperatureMax:      item.Temp,
+			Time:                int64(item.Dt),
+			Visibility:          float64(item.Visibility),
+			WindBearing:         item.WindDeg,
+			WindGust:            item.WindGust,
+			WindSpeed:           item.WindSpeed,
+		}
+
+		if len(item.Weather) > 0 {
+			hourlyPoint.Icon = item.Weather[0].Icon
+			hourlyPoint.Summary = item.Weather[0].Description
+		}
+
+		//	Add our point:
+		hourlyPoints = append(hourlyPoints, hourlyPoint)
+	}
+
+	//	Get the minutely points:
+	minutelyPoints := []MinuteDataPoint{}
+
+	//	Set the weather data points:
+	for _, item := range owResponse.Minutely {
+
+		//	Grab the current hourly point
+		minutePoint := MinuteDataPoint{
+			DateTime:      int64(item.Dt),
+			Precipitation: item.Precipitation,
+		}
+
+		//	Add our point:
+		minutelyPoints = append(minutelyPoints, minutePoint)
+	}
+
+	//	Get the alerts:
+	alerts := openWeatherAlerts(owResponse)
+
+	//	Format our weather report
+	retval = WeatherReport{
+		Latitude:  owResponse.Lat,
+		Longitude: owResponse.Lon,
+		Currently: WeatherDataPoint{
+			ApparentTemperature: owResponse.Current.FeelsLike,
+			CloudCover:          float64(owResponse.Current.Clouds),
+			DewPoint:            owResponse.Current.DewPoint,
+			Humidity:            float64(owResponse.Current.Humidity),
+			UVIndex:             owResponse.Current.Uvi,
+			PrecipAccumulation:  owResponse.Current.Rain.LastHour,
+			Pressure:            float64(owResponse.Current.Pressure),
+			Sunrise:             int64(owResponse.Current.Sunrise),
+			Sunset:              int64(owResponse.Current.Sunset),
+			Temperature:         owResponse.Current.Temp,
+			Time:                int64(owResponse.Current.Dt),
+			Visibility:          float64(owResponse.Current.Visibility),
+			WindBearing:         float64(owResponse.Current.WindDeg),
+			WindSpeed:           owResponse.Current.WindSpeed,
+		},
+		Hourly:   hourlyPoints,
+		Minutely: minutelyPoints,
+		Daily: WeatherDataBlock{
+			Data: dailyPoints,
+		},
+		Alerts: alerts,
+	}
+
+	if len(owResponse.Current.Weather) > 0 {
+		retval.Currently.Icon = owResponse.Current.Weather[0].Icon
+		retval.Currently.Summary = owResponse.Current.Weather[0].Description
+	}
+
+	return retval, nil
+}
+
+// openWeatherAlerts converts the alerts in an OpenWeather response to alert items
+func openWeatherAlerts(owResponse OpenWeatherResponse) []AlertItem {
+	retval := []AlertItem{}
+
+	for _, item := range owResponse.Alerts {
+		retval = append(retval, AlertItem{
+			Event:       item.Event,
+			Headline:    item.Event,
+			Description: item.Description,
+			SenderName:  item.SenderName,
+			Start:       floatToTime(item.Start),
+			End:         floatToTime(item.End),
+			VTEC:        []AlertVTEC{},
+		})
+	}
+
+	return retval
+}
+
+// floatToTime converts a (float) unix epoch value to a time
+func floatToTime(epoch float64) time.Time {
+	seconds, fraction := math.Modf(epoch)
+	return time.Unix(int64(seconds), int64(fraction*1e9)).UTC()
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestOpenWeatherAlerts_MapsFixture(t *testing.T) {
+	//	Arrange
+	body, err := os.ReadFile("testdata/weather/openweather_alerts.json")
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	response := OpenWeatherResponse{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	//	Act
+	alerts := openWeatherAlerts(response)
+
+	//	Assert
+	if len(alerts) != 2 {
+		t.Fatalf("Expected 2 alerts but got %v instead", len(alerts))
+	}
+
+	warning := alerts[0]
+	if warning.Event != "Severe Thunderstorm Warning" || warning.Headline != warning.Event || warning.SenderName != "NWS Peachtree City (Northern and Central Georgia)" {
+		t.Errorf("Expected the warning details to be mapped but got %+v instead", warning)
+	}
+
+	if warning.Description != "...SEVERE THUNDERSTORM WARNING REMAINS IN EFFECT UNTIL 400 PM EDT..." || warning.VTEC == nil || len(warning.VTEC) != 0 {
+		t.Errorf("Expected the description and an empty VTEC list but got %+v instead", warning)
+	}
+
+	start := time.Date(2022, 6, 1, 19, 0, 0, 0, time.UTC)
+	end := time.Date(2022, 6, 1, 20, 0, 0, 500000000, time.UTC)
+	if !warning.Start.Equal(start) || !warning.End.Equal(end) || warning.Start.Location() != time.UTC {
+		t.Errorf("Expected the warning from %v to %v but got %v to %v instead", start, end, warning.Start, warning.End)
+	}
+
+	if alerts[1].Event != "Heat Advisory" || !alerts[1].End.Equal(time.Date(2022, 6, 1, 23, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the heat advisory to end at 23:00 UTC but got %+v instead", alerts[1])
+	}
+}
+
+func TestFloatToTime_ConvertsEpochSeconds(t *testing.T) {
+	//	Arrange
+	tests := []struct {
+		epoch    float64
+		expected time.Time
+	}{
+		{0, time.Unix(0, 0).UTC()},
+		{1654110000, time.Date(2022, 6, 1, 19, 0, 0, 0, time.UTC)},
+		{1654110000.25, time.Date(2022, 6, 1, 19, 0, 0, 250000000, time.UTC)},
+	}
+
+	for _, test := range tests {
+		//	Act
+		converted := floatToTime(test.epoch)
+
+		//	Assert
+		if !converted.Equal(test.expected) {
+			t.Errorf("Expected %v for %v but got %v instead", test.expected, test.epoch, converted)
+		}
+	}
+}
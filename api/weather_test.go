@@ -0,0 +1,274 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// fakeWeatherProvider is a weather provider that returns a canned report (or error), optionally after a delay
+type fakeWeatherProvider struct {
+	name   string
+	report WeatherReport
+	err    error
+	delay  time.Duration
+	calls  *int
+}
+
+func (p fakeWeatherProvider) Name() string {
+	return p.name
+}
+
+func (p fakeWeatherProvider) GetWeatherReport(ctx context.Context, request WeatherRequest) (WeatherReport, error) {
+	if p.calls != nil {
+		*p.calls++
+	}
+
+	if p.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return WeatherReport{}, fmt.Errorf("%s gave up waiting", p.name)
+		case <-time.After(p.delay):
+		}
+	}
+
+	return p.report, p.err
+}
+
+// plausibleWeatherReport is a report that passes checkWeatherReport
+var plausibleWeatherReport = WeatherReport{
+	Currently: WeatherDataPoint{Summary: "clear sky", Icon: "01d"},
+	Hourly:    []WeatherDataPoint{{Temperature: 20}},
+}
+
+func TestAddWeatherAstronomy_FillsCurrentlyFromToday(t *testing.T) {
+	//	Arrange
+	report := WeatherReport{
+		Daily: WeatherDataBlock{
+			Data: []WeatherDataPoint{
+				{Sunrise: 1000, Sunset: 44200, MoonPhase: 0.5},
+			},
+		},
+	}
+
+	//	Act
+	report = addWeatherAstronomy(report)
+
+	//	Assert
+	if report.Currently.Sunrise != 1000 || report.Currently.Sunset != 44200 {
+		t.Errorf("Expected current sunrise/sunset from today but got %v/%v instead", report.Currently.Sunrise, report.Currently.Sunset)
+	}
+
+	if report.Currently.DaylightDuration != 43200 {
+		t.Errorf("Expected daylight duration of 43200 but got %v instead", report.Currently.DaylightDuration)
+	}
+
+	if report.Daily.Data[0].MoonPhaseName != "Full Moon" {
+		t.Errorf("Expected a full moon but got %v instead", report.Daily.Data[0].MoonPhaseName)
+	}
+}
+
+func TestAddWeatherAstronomy_CalculatesMissingSunAndMoon(t *testing.T) {
+	//	Arrange -- a daily point for London without any sun or moon details
+	report := WeatherReport{
+		Latitude:  51.5074,
+		Longitude: -0.1278,
+		Daily: WeatherDataBlock{
+			Data: []WeatherDataPoint{
+				{Time: time.Date(2022, 1, 17, 12, 0, 0, 0, time.UTC).Unix()},
+			},
+		},
+	}
+
+	//	Act
+	report = addWeatherAstronomy(report)
+
+	//	Assert
+	today := report.Daily.Data[0]
+	if today.Sunrise == 0 || today.Sunset == 0 || today.DaylightDuration == 0 {
+		t.Errorf("Expected calculated sunrise/sunset but got %v/%v instead", today.Sunrise, today.Sunset)
+	}
+
+	if today.MoonPhaseName != "Full Moon" {
+		t.Errorf("Expected a full moon but got %v instead", today.MoonPhaseName)
+	}
+
+	if report.Currently.MoonPhase != today.MoonPhase {
+		t.Errorf("Expected the current moon phase to match today")
+	}
+}
+
+func TestGetWeatherReportWithFailover_UsesFirstPlausibleReport(t *testing.T) {
+	//	Arrange
+	viper.Set("weather.timeout", "1s")
+	viper.Set("weather.timeouts.slow", "20ms")
+	defer viper.Set("weather.timeout", nil)
+	defer viper.Set("weather.timeouts.slow", nil)
+
+	unavailable := newUpstreamStatusError(fmt.Errorf("503 Service Unavailable"), 503)
+	implausible := WeatherReport{Currently: WeatherDataPoint{Summary: "clear sky"}}
+
+	tests := []struct {
+		name      string
+		providers []fakeWeatherProvider
+		expected  string
+		calls     []int
+	}{
+		{
+			name:      "first provider answers",
+			providers: []fakeWeatherProvider{{name: "first", report: plausibleWeatherReport}, {name: "second", report: plausibleWeatherReport}},
+			expected:  "first",
+			calls:     []int{1, 0},
+		},
+		{
+			name:      "first provider fails",
+			providers: []fakeWeatherProvider{{name: "first", err: unavailable}, {name: "second", report: plausibleWeatherReport}},
+			expected:  "second",
+			calls:     []int{1, 1},
+		},
+		{
+			name:      "first provider returns implausible data",
+			providers: []fakeWeatherProvider{{name: "first", report: implausible}, {name: "second", report: plausibleWeatherReport}},
+			expected:  "second",
+			calls:     []int{1, 1},
+		},
+		{
+			name:      "first provider times out",
+			providers: []fakeWeatherProvider{{name: "slow", report: plausibleWeatherReport, delay: time.Second}, {name: "second", report: plausibleWeatherReport}},
+			expected:  "second",
+			calls:     []int{1, 1},
+		},
+	}
+
+	for _, test := range tests {
+		calls := make([]int, len(test.providers))
+		providers := []WeatherProvider{}
+		for i, provider := range test.providers {
+			provider.calls = &calls[i]
+			providers = append(providers, provider)
+		}
+
+		//	Act
+		report, err := getWeatherReportWithFailover(context.Background(), WeatherRequest{}, providers, nil)
+
+		//	Assert
+		if err != nil {
+			t.Errorf("%s: Returned error and we didn't expect that: %v", test.name, err)
+			continue
+		}
+
+		if report.Provider != test.expected {
+			t.Errorf("%s: Expected a report from %v but got %v instead", test.name, test.expected, report.Provider)
+		}
+
+		for i := range calls {
+			if calls[i] != test.calls[i] {
+				t.Errorf("%s: Expected %v to be called %v times but got %v instead", test.name, test.providers[i].name, test.calls[i], calls[i])
+			}
+		}
+	}
+}
+
+func TestGetWeatherReportWithFailover_AllFail_ReportsEachProvider(t *testing.T) {
+	//	Arrange
+	viper.Set("weather.timeout", "1s")
+	viper.Set("weather.timeouts.slow", "20ms")
+	defer viper.Set("weather.timeout", nil)
+	defer viper.Set("weather.timeouts.slow", nil)
+
+	providers, problems := getWeatherProviders([]string{"nope"})
+
+	tests := []struct {
+		name      string
+		providers []WeatherProvider
+		problems  []ProviderError
+		code      string
+		expected  []ProviderError
+	}{
+		{
+			name:      "timeout",
+			providers: []WeatherProvider{fakeWeatherProvider{name: "slow", delay: time.Second}},
+			code:      ErrorCodeProvidersFailed,
+			expected:  []ProviderError{{Provider: "slow", Code: ErrorCodeUpstreamTimeout}},
+		},
+		{
+			name: "timeout and bad data",
+			providers: []WeatherProvider{
+				fakeWeatherProvider{name: "slow", delay: time.Second},
+				fakeWeatherProvider{name: "empty", report: WeatherReport{}},
+			},
+			code: ErrorCodeProvidersFailed,
+			expected: []ProviderError{
+				{Provider: "slow", Code: ErrorCodeUpstreamTimeout},
+				{Provider: "empty", Code: ErrorCodeUpstreamBadData},
+			},
+		},
+		{
+			name:      "unknown provider and bad status",
+			providers: append(providers, fakeWeatherProvider{name: "broken", err: newUpstreamStatusError(fmt.Errorf("404 Not Found"), 404)}),
+			problems:  problems,
+			code:      ErrorCodeProvidersFailed,
+			expected: []ProviderError{
+				{Provider: "nope", Code: ErrorCodeInternal},
+				{Provider: "broken", Code: ErrorCodeUpstreamBadData},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		//	Act
+		_, err := getWeatherReportWithFailover(context.Background(), WeatherRequest{}, test.providers, test.problems)
+
+		//	Assert
+		serviceErr, ok := err.(ServiceError)
+		if !ok {
+			t.Errorf("%s: Expected a service error but got %v instead", test.name, err)
+			continue
+		}
+
+		if serviceErr.Code != test.code {
+			t.Errorf("%s: Expected error code %v but got %v instead", test.name, test.code, serviceErr.Code)
+		}
+
+		if len(serviceErr.Providers) != len(test.expected) {
+			t.Errorf("%s: Expected %v provider errors but got %+v instead", test.name, len(test.expected), serviceErr.Providers)
+			continue
+		}
+
+		for i, problem := range serviceErr.Providers {
+			if problem.Provider != test.expected[i].Provider || problem.Code != test.expected[i].Code || problem.Message == "" {
+				t.Errorf("%s: Expected %v to fail with %v but got %+v instead", test.name, test.expected[i].Provider, test.expected[i].Code, problem)
+			}
+		}
+	}
+}
+
+func TestGetWeatherReportWithFailover_NoProviders_ReturnsInternalError(t *testing.T) {
+	//	Act
+	_, err := getWeatherReportWithFailover(context.Background(), WeatherRequest{}, []WeatherProvider{}, nil)
+
+	//	Assert
+	if errorCode(err) != ErrorCodeInternal {
+		t.Errorf("Expected an internal error but got %v instead", err)
+	}
+}
+
+func TestWeatherProviderTimeout_UsesProviderSpecificTimeout(t *testing.T) {
+	//	Arrange
+	viper.Set("weather.timeout", "10s")
+	viper.Set("weather.timeouts.openmeteo", "3s")
+	defer viper.Set("weather.timeout", nil)
+	defer viper.Set("weather.timeouts.openmeteo", nil)
+
+	//	Act
+	openMeteo := weatherProviderTimeout(OpenMeteoService{}.Name())
+	nws := weatherProviderTimeout(NWSWeatherService{}.Name())
+
+	//	Assert
+	if openMeteo != 3*time.Second || nws != 10*time.Second {
+		t.Errorf("Expected timeouts of 3s for Open-Meteo and 10s for NWS but got %v and %v instead", openMeteo, nws)
+	}
+}
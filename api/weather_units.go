@@ -0,0 +1,173 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+//	Weather providers always return data in our canonical (metric) units:
+//	temperature in Celsius, wind speed in meters/sec, visibility in meters,
+//	precipitation in millimeters and pressure in hectopascals.  The report is
+//	converted to the requested unit system just before it's returned.
+
+const (
+	// UnitsImperial reports in Fahrenheit, mph, miles, inches and inHg
+	UnitsImperial = "imperial"
+
+	// UnitsMetric reports in Celsius, m/s, kilometers, millimeters and hPa
+	UnitsMetric = "metric"
+
+	// UnitsStandard reports in Kelvin, m/s, meters, millimeters and hPa
+	UnitsStandard = "standard"
+)
+
+// WeatherUnits describes the units used in a weather report
+type WeatherUnits struct {
+	System        string `json:"system"`        // The unit system (imperial, metric or standard)
+	Temperature   string `json:"temperature"`   // Temperature unit
+	WindSpeed     string `json:"windSpeed"`     // Wind speed unit
+	Visibility    string `json:"visibility"`    // Visibility unit
+	Precipitation string `json:"precipitation"` // Precipitation accumulation unit
+	Pressure      string `json:"pressure"`      // Pressure unit
+}
+
+// weatherUnitSystems are the unit labels for each supported unit system
+var weatherUnitSystems = map[string]WeatherUnits{
+	UnitsImperial: {
+		System:        UnitsImperial,
+		Temperature:   "°F",
+		WindSpeed:     "mph",
+		Visibility:    "mi",
+		Precipitation: "in",
+		Pressure:      "inHg",
+	},
+	UnitsMetric: {
+		System:        UnitsMetric,
+		Temperature:   "°C",
+		WindSpeed:     "m/s",
+		Visibility:    "km",
+		Precipitation: "mm",
+		Pressure:      "hPa",
+	},
+	UnitsStandard: {
+		System:        UnitsStandard,
+		Temperature:   "K",
+		WindSpeed:     "m/s",
+		Visibility:    "m",
+		Precipitation: "mm",
+		Pressure:      "hPa",
+	},
+}
+
+// normalizeUnits gets the unit system to use for the requested units (imperial, if none are requested)
+func normalizeUnits(units string) (string, error) {
+	units = strings.ToLower(strings.TrimSpace(units))
+	if units == "" {
+		return UnitsImperial, nil
+	}
+
+	if _, found := weatherUnitSystems[units]; !found {
+		return "", fmt.Errorf("units must be one of imperial, metric or standard")
+	}
+
+	return units, nil
+}
+
+// convertWeatherReport converts a weather report from canonical units to the given unit system
+func convertWeatherReport(report WeatherReport, units string) WeatherReport {
+	report.Units = weatherUnitSystems[units]
+	report.Currently = convertWeatherDataPoint(report.Currently, units)
+
+	for i := range report.Hourly {
+		report.Hourly[i] = convertWeatherDataPoint(report.Hourly[i], units)
+	}
+
+	for i := range report.Daily.Data {
+		report.Daily.Data[i] = convertWeatherDataPoint(report.Daily.Data[i], units)
+	}
+
+	for i := range report.Minutely {
+		report.Minutely[i].Precipitation = convertPrecipitation(report.Minutely[i].Precipitation, units)
+	}
+
+	return report
+}
+
+// convertWeatherDataPoint converts a weather data point from canonical units to the given unit system
+func convertWeatherDataPoint(point WeatherDataPoint, units string) WeatherDataPoint {
+	point.Temperature = convertTemperature(point.Temperature, units)
+	point.TemperatureMin = convertTemperature(point.TemperatureMin, units)
+	point.TemperatureMax = convertTemperature(point.TemperatureMax, units)
+	point.ApparentTemperature = convertTemperature(point.ApparentTemperature, units)
+	point.DewPoint = convertTemperature(point.DewPoint, units)
+	point.WindSpeed = convertWindSpeed(point.WindSpeed, units)
+	point.WindGust = convertWindSpeed(point.WindGust, units)
+	point.Visibility = convertVisibility(point.Visibility, units)
+	point.PrecipAccumulation = convertPrecipitation(point.PrecipAccumulation, units)
+	point.PrecipIntensity = convertPrecipitation(point.PrecipIntensity, units)
+	point.PrecipIntensityMax = convertPrecipitation(point.PrecipIntensityMax, units)
+	point.Pressure = convertPressure(point.Pressure, units)
+
+	return point
+}
+
+// convertTemperature converts a temperature in Celsius
+func convertTemperature(celsius float64, units string) float64 {
+	switch units {
+	case UnitsImperial:
+		return celsius*9/5 + 32
+	case UnitsStandard:
+		return celsius + 273.15
+	}
+	return celsius
+}
+
+// convertWindSpeed converts a wind speed in meters/sec
+func convertWindSpeed(metersPerSecond float64, units string) float64 {
+	if units == UnitsImperial {
+		return metersPerSecond * 2.2369363
+	}
+	return metersPerSecond
+}
+
+// convertVisibility converts a visibility in meters
+func convertVisibility(meters float64, units string) float64 {
+	switch units {
+	case UnitsImperial:
+		return meters / 1609.344
+	case UnitsMetric:
+		return meters / 1000
+	}
+	return meters
+}
+
+// convertPrecipitation converts a precipitation amount in millimeters
+func convertPrecipitation(millimeters float64, units string) float64 {
+	if units == UnitsImperial {
+		return millimeters / 25.4
+	}
+	return millimeters
+}
+
+// convertPressure converts a pressure in hectopascals
+func convertPressure(hectopascals float64, units string) float64 {
+	if units == UnitsImperial {
+		return hectopascals * 0.0295299830714
+	}
+	return hectopascals
+}
+
+// fahrenheitToCelsius converts a Fahrenheit temperature to our canonical Celsius
+func fahrenheitToCelsius(fahrenheit float64) float64 {
+	return (fahrenheit - 32) * 5 / 9
+}
+
+// mphToMetersPerSecond converts a wind speed in mph to our canonical meters/sec
+func mphToMetersPerSecond(mph float64) float64 {
+	return mph / 2.2369363
+}
+
+// kphToMetersPerSecond converts a wind speed in km/h to our canonical meters/sec
+func kphToMetersPerSecond(kph float64) float64 {
+	return kph / 3.6
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeUnits_DefaultsToImperial(t *testing.T) {
+	units, err := normalizeUnits("")
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+
+	if units != UnitsImperial {
+		t.Errorf("Expected %v units but got %v instead", UnitsImperial, units)
+	}
+}
+
+func TestNormalizeUnits_UnknownUnits_ReturnsError(t *testing.T) {
+	_, err := normalizeUnits("furlongs")
+	if err == nil {
+		t.Errorf("Didn't return an error and we expected one")
+	}
+}
+
+func TestConvertWeatherDataPoint_ConvertsFromCanonicalUnits(t *testing.T) {
+	//	Arrange
+	point := WeatherDataPoint{
+		Temperature:        20,
+		WindSpeed:          10,
+		Visibility:         10000,
+		PrecipAccumulation: 25.4,
+		Pressure:           1013.25,
+	}
+
+	tests := []struct {
+		units         string
+		temperature   float64
+		windSpeed     float64
+		visibility    float64
+		precipitation float64
+		pressure      float64
+	}{
+		{UnitsImperial, 68, 22.369, 6.214, 1, 29.921},
+		{UnitsMetric, 20, 10, 10, 25.4, 1013.25},
+		{UnitsStandard, 293.15, 10, 10000, 25.4, 1013.25},
+	}
+
+	for _, test := range tests {
+		//	Act
+		converted := convertWeatherDataPoint(point, test.units)
+
+		//	Assert
+		checks := []struct {
+			name     string
+			got      float64
+			expected float64
+		}{
+			{"temperature", converted.Temperature, test.temperature},
+			{"wind speed", converted.WindSpeed, test.windSpeed},
+			{"visibility", converted.Visibility, test.visibility},
+			{"precipitation", converted.PrecipAccumulation, test.precipitation},
+			{"pressure", converted.Pressure, test.pressure},
+		}
+
+		for _, check := range checks {
+			if math.Abs(check.got-check.expected) > 0.001 {
+				t.Errorf("%v %v: expected %v but got %v instead", test.units, check.name, check.expected, check.got)
+			}
+		}
+	}
+}
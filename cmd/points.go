@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danesparza/daydash-service/api"
+	"github.com/danesparza/daydash-service/internal/pointstore"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	pointsOlderThan time.Duration
+	pointsService   string
+)
+
+// pointsCmd represents the points command
+var pointsCmd = &cobra.Command{
+	Use:   "points",
+	Short: "Manage the stored NWS points lookups",
+	Long: `The points command lists and purges the NWS points lookups stored on disk.
+
+The running service only opens the store while it's using it, so these commands work while
+it's running.  The service also keeps the lookups in memory -- use --service (with the admin
+token in server.admintoken) to purge through the running service so it forgets them too.`,
+}
+
+// pointsListCmd represents the points list command
+var pointsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the stored NWS points lookups",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list := api.NWSPointsList{}
+
+		if pointsService != "" {
+			if err := callPointsService("GET", "", &list); err != nil {
+				return err
+			}
+		} else {
+			store, err := openPointStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			entries, err := store.List()
+			if err != nil {
+				return err
+			}
+
+			list.Path = viper.GetString("nws.points.path")
+			for _, entry := range entries {
+				list.Entries = append(list.Entries, api.NWSPointsEntry{Key: entry.Key, FetchedAt: entry.FetchedAt})
+			}
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "LOCATION\tFETCHED\tAGE")
+		for _, entry := range list.Entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Key, entry.FetchedAt.Format(time.RFC3339), time.Since(entry.FetchedAt).Round(time.Minute))
+		}
+		w.Flush()
+
+		fmt.Printf("\n%d stored lookups in %s\n", len(list.Entries), list.Path)
+		return nil
+	},
+}
+
+// pointsPurgeCmd represents the points purge command
+var pointsPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Remove stored NWS points lookups",
+	Long:  `Removes the stored NWS points lookups (all of them, unless --older-than is used)`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if pointsService != "" {
+			result := api.NWSPointsPurgeResult{}
+			if err := callPointsService("DELETE", pointsOlderThan.String(), &result); err != nil {
+				return err
+			}
+
+			fmt.Printf("Removed %d stored lookups (and cleared %d from the service's memory)\n", result.Removed, result.Cleared)
+			return nil
+		}
+
+		store, err := openPointStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		removed, err := store.Purge(pointsOlderThan)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Removed %d stored lookups (the running service keeps its in-memory copies until it restarts -- use --service to clear those too)\n", removed)
+		return nil
+	},
+}
+
+// openPointStore opens the points store from the config
+func openPointStore() (*pointstore.Store, error) {
+	path := viper.GetString("nws.points.path")
+	if path == "" {
+		return nil, fmt.Errorf("nws.points.path isn't set -- there is no points store")
+	}
+
+	store, err := pointstore.Open(path, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// callPointsService calls the /v2/nwspoints admin endpoint on the running service and decodes the response
+func callPointsService(method, olderThan string, result interface{}) error {
+	serviceUrl := strings.TrimSuffix(pointsService, "/") + "/v2/nwspoints"
+	if olderThan != "" {
+		serviceUrl += "?olderthan=" + url.QueryEscape(olderThan)
+	}
+
+	req, err := http.NewRequest(method, serviceUrl, nil)
+	if err != nil {
+		return fmt.Errorf("problem creating the request to the service: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+viper.GetString("server.admintoken"))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("problem calling the service: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		problem := api.ErrorResponse{}
+		json.NewDecoder(resp.Body).Decode(&problem)
+		return fmt.Errorf("the service returned %s: %s", resp.Status, problem.Message)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func init() {
+	pointsCmd.PersistentFlags().StringVar(&pointsService, "service", "", "the url of the running service (like http://localhost:3000) -- list and purge through it instead of opening the store directly")
+	pointsPurgeCmd.Flags().DurationVar(&pointsOlderThan, "older-than", 0, "only remove lookups fetched longer ago than this (like 720h)")
+
+	pointsCmd.AddCommand(pointsListCmd)
+	pointsCmd.AddCommand(pointsPurgeCmd)
+	rootCmd.AddCommand(pointsCmd)
+}
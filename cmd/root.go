@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/danesparza/daydash-service/internal/logger"
 	homedir "github.com/mitchellh/go-homedir"
@@ -81,7 +82,41 @@ func initConfig() {
 	viper.SetDefault("server.port", "80")
 	viper.SetDefault("server.httponly", false)
 	viper.SetDefault("server.allowed-origins", "*")
+	viper.SetDefault("server.admintoken", "")
 	viper.SetDefault("news.mongodb", "")
+	viper.SetDefault("weather.providers", []string{"openweather", "nws", "openmeteo"})
+	viper.SetDefault("weather.timeout", "10s")
+	viper.SetDefault("pollen.timeout", "10s")
+	viper.SetDefault("http.connecttimeout", "5s")
+	viper.SetDefault("http.readtimeout", "15s")
+	viper.SetDefault("http.timeout", "30s")
+	viper.SetDefault("http.retries", 2)
+	viper.SetDefault("http.retrydelay", "250ms")
+	viper.SetDefault("http.maxretrydelay", "5s")
+	viper.SetDefault("cache.ttl.weather", "10m")
+	viper.SetDefault("cache.ttl.pollen", "6h")
+	viper.SetDefault("cache.ttl.alerts", "2m")
+	viper.SetDefault("cache.ttl.nwspoints", "24h")
+	viper.SetDefault("cache.ttl.calendar", "24h")
+	viper.SetDefault("cache.loadtimeout", "60s")
+	viper.SetDefault("cache.maxentries", 10000)
+	viper.SetDefault("calendar.maxbodysize", 10485760)
+	viper.SetDefault("calendar.cache.maxbytes", 67108864)
+	viper.SetDefault("calendar.workinghours.start", "09:00")
+	viper.SetDefault("calendar.workinghours.end", "17:00")
+	viper.SetDefault("holidays.country", "US")
+	viper.SetDefault("holidays.rules", "")
+	viper.SetDefault("nws.points.path", filepath.Join(home, ".daydash-service", "nwspoints.db"))
+	viper.SetDefault("nws.points.refresh", "720h")
+	viper.SetDefault("alerts.stream.interval", "60s")
+	viper.SetDefault("alerts.history.enabled", true)
+	viper.SetDefault("alerts.history.timeout", "5s")
+	viper.SetDefault("alerts.history.memory.maxrecords", 10000)
+	viper.SetDefault("alerts.history.memory.retention", "720h")
+	viper.SetDefault("alerts.webhooks.interval", "5m")
+	viper.SetDefault("alerts.webhooks.minseverity", "Severe")
+	viper.SetDefault("alerts.webhooks.retries", 3)
+	viper.SetDefault("alerts.webhooks.retrydelay", "2s")
 	viper.SetDefault("log.level", "info")
 
 	// If a config file is found, read it in
@@ -66,19 +66,36 @@ func start(cmd *cobra.Command, args []string) {
 	restRouter.Use(api.ApiVersionMiddleware)
 
 	//	DATA ROUTES
-	restRouter.HandleFunc("/v2/alerts", apiService.GetWeatherAlerts).Methods("POST")            // Get weather alerts data
-	restRouter.HandleFunc("/v2/calendar", apiService.GetCalendar).Methods("POST")               // Get calendar data
-	restRouter.HandleFunc("/v2/mapimage", apiService.GetMapImageForCoordinates).Methods("POST") // Get map data
-	restRouter.HandleFunc("/v2/news", apiService.GetNewsReport).Methods("GET")                  // Get news data
-	restRouter.HandleFunc("/v2/pollen", apiService.GetPollenReport).Methods("POST")             // Get pollen data
-	restRouter.HandleFunc("/v2/weather", apiService.GetWeatherReport).Methods("POST")           // Get weather data
+	restRouter.HandleFunc("/v2/alerts", apiService.GetWeatherAlerts).Methods("POST")                        // Get weather alerts data
+	restRouter.HandleFunc("/v2/alerts/history", apiService.GetAlertHistory).Methods("GET")                  // Get weather alerts the service has seen
+	restRouter.HandleFunc("/v2/alerts/stream", apiService.StreamWeatherAlerts).Methods("GET")               // Stream weather alerts as they change
+	restRouter.HandleFunc("/v2/alerts/webhooks/deliveries", apiService.GetWebhookDeliveries).Methods("GET") // Get recent alert webhook deliveries
+	restRouter.HandleFunc("/v2/astronomy", apiService.GetAstronomyReport).Methods("POST")                   // Get sun and moon data
+	restRouter.HandleFunc("/v2/calendar", apiService.GetCalendar).Methods("POST")                           // Get calendar data
+	restRouter.HandleFunc("/v2/holidays", apiService.GetHolidays).Methods("GET")                            // Get public holidays
+	restRouter.HandleFunc("/v2/mapimage", apiService.GetMapImageForCoordinates).Methods("POST")             // Get map data
+	restRouter.HandleFunc("/v2/news", apiService.GetNewsReport).Methods("GET")                              // Get news data
+	restRouter.HandleFunc("/v2/nwspoints", apiService.ListNWSPoints).Methods("GET")                         // List the stored NWS points lookups (admin)
+	restRouter.HandleFunc("/v2/nwspoints", apiService.PurgeNWSPoints).Methods("DELETE")                     // Purge the stored NWS points lookups (admin)
+	restRouter.HandleFunc("/v2/pollen", apiService.GetPollenReport).Methods("POST")                         // Get pollen data
+	restRouter.HandleFunc("/v2/weather", apiService.GetWeatherReport).Methods("POST")                       // Get weather data
 	// restRouter.HandleFunc("/v2/zipgeo", apiService.GetCalendar).Methods("POST")                 // Get zipgeo data
 
 	//	SWAGGER ROUTES
 	restRouter.PathPrefix("/v2/swagger").Handler(httpSwagger.WrapHandler)
 
-	//	Start the background process
+	//	Limit the response cache
+	api.ConfigureResponseCache()
+
+	//	Open the alert history store now, rather than on the first alerts request
+	api.OpenAlertHistoryStore()
+
+	//	Load the holiday rules now, so a bad rules file is reported right away
+	api.LoadHolidayRules()
+
+	//	Start the background processes
 	go news.NewsFetchTask(ctx)
+	go api.AlertWebhookTask(ctx)
 
 	//	Letsencrypt handled by certmagic
 	certmagic.DefaultACME.Agreed = true
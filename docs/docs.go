@@ -22,12 +22,14 @@ const docTemplate = `{
     "paths": {
         "/alerts": {
             "post": {
-                "description": "Gets the weather alerts for the area specified",
+                "description": "Gets the weather alerts for the area specified.  As application/cap+xml, only the most recently sent alert is returned (a CAP document is a single alert) -- use application/atom+xml to get every alert as CAP",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/cap+xml",
+                    "application/atom+xml"
                 ],
                 "tags": [
                     "dashboard"
@@ -49,26 +51,261 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/api.AlertReport"
+                        },
+                        "headers": {
+                            "Age": {
+                                "type": "integer",
+                                "description": "How old the cached response is (in seconds)"
+                            },
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "hit if the response came from the cache, otherwise miss"
+                            }
                         }
                     },
+                    "204": {
+                        "description": "No alerts (application/cap+xml only)"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/calendar": {
+        "/alerts/history": {
+            "get": {
+                "description": "Gets the weather alerts the service has seen (including expired alerts), most recently seen first.  Alerts are included if they were seen at any point in the time range.  Requires the admin token (server.admintoken) as a bearer token",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Gets the weather alerts the service has seen",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only alerts for this latitude (requires long)",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only alerts for this longitude (requires lat)",
+                        "name": "long",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only alerts for this event type (like 'Tornado Warning')",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only alerts seen at or after this time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only alerts seen at or before this time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The most alerts to return (defaults to 100, at most 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.AlertHistoryReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts/stream": {
+            "get": {
+                "description": "Streams server-sent events as weather alerts for the area specified are issued (alert.new), updated (alert.updated) and expire (alert.expired).  The currently active alerts are sent as alert.new events when the stream starts",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "dashboard"
+                ],
+                "summary": "Streams weather alerts for the area specified",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Latitude",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Longitude",
+                        "name": "long",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include alerts at least this severe (Minor, Moderate, Severe or Extreme)",
+                        "name": "min_severity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include alerts at least this urgent (Past, Future, Expected or Immediate)",
+                        "name": "min_urgency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include alerts at least this certain (Unlikely, Possible, Likely or Observed)",
+                        "name": "min_certainty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated alert statuses to include (defaults to Actual)",
+                        "name": "statuses",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.AlertStreamEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts/webhooks/deliveries": {
+            "get": {
+                "description": "Gets the most recent alert webhook deliveries (newest first), including failed deliveries.  Requires the admin token (server.admintoken) as a bearer token",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Gets the recent alert webhook deliveries",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.WebhookDeliveryLog"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/astronomy": {
             "post": {
-                "description": "Gets today's calendar data for the given iCal url and timezone",
+                "description": "Gets sunrise, sunset, twilight, solar noon, moon phase and moonrise/moonset for the given location and date.  Calculated locally (no outside services are used)",
                 "consumes": [
                     "application/json"
                 ],
@@ -78,15 +315,15 @@ const docTemplate = `{
                 "tags": [
                     "dashboard"
                 ],
-                "summary": "Gets today's calendar data for the given iCal url and timezone",
+                "summary": "Gets sun and moon data for the given location and date",
                 "parameters": [
                     {
-                        "description": "The calendar data to fetch",
+                        "description": "The location and date to get data for",
                         "name": "config",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.CalendarRequest"
+                            "$ref": "#/definitions/api.AstronomyRequest"
                         }
                     }
                 ],
@@ -94,7 +331,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.CalendarResponse"
+                            "$ref": "#/definitions/api.AstronomyReport"
                         }
                     },
                     "400": {
@@ -112,9 +349,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/mapimage": {
+        "/calendar": {
             "post": {
-                "description": "Gets a map image for the given lat, long and zoom level. Returns the map image as a base64 encoded jpeg",
+                "description": "Gets calendar data for the given iCal url (or list of calendars) and timezone.  Calendars can also be CalDAV calendars configured on the server (by name), and public holidays can be included as all-day events.  With analyze set, each day includes free/busy blocks within working hours and groups of conflicting events, and the next event to start is included.  Events from all calendars are merged and sorted by start time.  If some calendars can't be fetched, their errors are included and the rest of the events are returned.  By default only today's events are included -- use start, end or days to get a range of days (up to 31)",
                 "consumes": [
                     "application/json"
                 ],
@@ -124,7 +361,7 @@ const docTemplate = `{
                 "tags": [
                     "dashboard"
                 ],
-                "summary": "Gets a map image for the given lat, long and zoom level",
+                "summary": "Gets calendar data for the given iCal urls and timezone",
                 "parameters": [
                     {
                         "description": "The calendar data to fetch",
@@ -132,7 +369,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.MapImageRequest"
+                            "$ref": "#/definitions/api.CalendarRequest"
                         }
                     }
                 ],
@@ -140,7 +377,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.MapImageResponse"
+                            "$ref": "#/definitions/api.CalendarResponse"
                         }
                     },
                     "400": {
@@ -154,28 +391,63 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/news": {
+        "/holidays": {
             "get": {
-                "description": "Gets breaking news from CNN.  Images are included inline as base64 encoded jpeg images",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Gets the public holidays (and the dates they're observed) for a country and year.  Calculated locally from the built-in rules (US federal holidays) and the configured rules file",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "dashboard"
                 ],
-                "summary": "Gets breaking news from CNN",
+                "summary": "Gets the public holidays for a country (or region) and year",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Country code (like US).  Defaults to the configured country",
+                        "name": "country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Region code (like GA) to include regional holidays",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The year (defaults to the current year)",
+                        "name": "year",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.NewsReport"
+                            "$ref": "#/definitions/api.HolidayReport"
                         }
                     },
                     "400": {
@@ -183,19 +455,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
                     }
                 }
             }
         },
-        "/pollen": {
+        "/mapimage": {
             "post": {
-                "description": "Gets pollen data and forecast for a given location",
+                "description": "Gets a map image for the given lat, long and zoom level. Returns the map image as a base64 encoded jpeg",
                 "consumes": [
                     "application/json"
                 ],
@@ -205,15 +471,15 @@ const docTemplate = `{
                 "tags": [
                     "dashboard"
                 ],
-                "summary": "Gets pollen data and forecast for a given location",
+                "summary": "Gets a map image for the given lat, long and zoom level",
                 "parameters": [
                     {
-                        "description": "The location to get data for",
+                        "description": "The calendar data to fetch",
                         "name": "config",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.PollenRequest"
+                            "$ref": "#/definitions/api.MapImageRequest"
                         }
                     }
                 ],
@@ -221,7 +487,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.PollenReport"
+                            "$ref": "#/definitions/api.MapImageResponse"
                         }
                     },
                     "400": {
@@ -235,13 +501,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/weather": {
-            "post": {
-                "description": "Gets the current and forecasted weather for the given location",
+        "/news": {
+            "get": {
+                "description": "Gets breaking news from CNN.  Images are included inline as base64 encoded jpeg images",
                 "consumes": [
                     "application/json"
                 ],
@@ -251,23 +529,12 @@ const docTemplate = `{
                 "tags": [
                     "dashboard"
                 ],
-                "summary": "Gets the current and forecasted weather for the given location",
-                "parameters": [
-                    {
-                        "description": "The location to fetch data for",
-                        "name": "config",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.WeatherRequest"
-                        }
-                    }
-                ],
+                "summary": "Gets breaking news from CNN",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.WeatherReport"
+                            "$ref": "#/definitions/api.NewsReport"
                         }
                     },
                     "400": {
@@ -281,35 +548,376 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.AlertItem": {
-            "type": "object",
-            "properties": {
-                "area_description": {
-                    "description": "Affected Area description",
-                    "type": "string"
-                },
-                "description": {
-                    "description": "Long description of the event",
-                    "type": "string"
-                },
-                "end": {
-                    "description": "Event end time",
-                    "type": "string"
-                },
-                "event": {
-                    "description": "Short event summary",
-                    "type": "string"
-                },
-                "headline": {
+        },
+        "/nwspoints": {
+            "get": {
+                "description": "Lists the NWS points lookups in the on-disk store.  Requires the admin token (server.admintoken) as a bearer token",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Lists the stored NWS points lookups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.NWSPointsList"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes the NWS points lookups from the on-disk store (all of them, unless olderthan is used) and clears them from memory.  Requires the admin token (server.admintoken) as a bearer token",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Purges the stored NWS points lookups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only remove lookups fetched longer ago than this (like 720h)",
+                        "name": "olderthan",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.NWSPointsPurgeResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/pollen": {
+            "post": {
+                "description": "Gets pollen data and forecast for a given location",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dashboard"
+                ],
+                "summary": "Gets pollen data and forecast for a given location",
+                "parameters": [
+                    {
+                        "description": "The location to get data for",
+                        "name": "config",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.PollenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.PollenReport"
+                        },
+                        "headers": {
+                            "Age": {
+                                "type": "integer",
+                                "description": "How old the cached response is (in seconds)"
+                            },
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "hit if the response came from the cache, otherwise miss"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/weather": {
+            "post": {
+                "description": "Gets the current and forecasted weather for the given location",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dashboard"
+                ],
+                "summary": "Gets the current and forecasted weather for the given location",
+                "parameters": [
+                    {
+                        "description": "The location to fetch data for",
+                        "name": "config",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.WeatherRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.WeatherReport"
+                        },
+                        "headers": {
+                            "Age": {
+                                "type": "integer",
+                                "description": "How old the cached response is (in seconds)"
+                            },
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "hit if the response came from the cache, otherwise miss"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "alerthistory.Record": {
+            "type": "object",
+            "properties": {
+                "alertid": {
+                    "description": "Unique alert identifier",
+                    "type": "string"
+                },
+                "certainty": {
+                    "description": "Certainty of the alert",
+                    "type": "string"
+                },
+                "city": {
+                    "description": "City name for the location",
+                    "type": "string"
+                },
+                "county": {
+                    "description": "National weather service county for the location",
+                    "type": "string"
+                },
+                "end": {
+                    "description": "When the alert ends",
+                    "type": "string"
+                },
+                "event": {
+                    "description": "Short event summary (like 'Tornado Warning')",
+                    "type": "string"
+                },
+                "firstseen": {
+                    "description": "The first time the service saw the alert",
+                    "type": "string"
+                },
+                "headline": {
+                    "description": "Full headline description",
+                    "type": "string"
+                },
+                "lastseen": {
+                    "description": "The most recent time the service saw the alert",
+                    "type": "string"
+                },
+                "location": {
+                    "description": "The (rounded) coordinates the alert was seen for",
+                    "type": "string"
+                },
+                "severity": {
+                    "description": "Severity of the alert",
+                    "type": "string"
+                },
+                "start": {
+                    "description": "When the alert took effect",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "State name for the location",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status of the alert (like Actual or Test)",
+                    "type": "string"
+                },
+                "urgency": {
+                    "description": "Urgency of the alert",
+                    "type": "string"
+                }
+            }
+        },
+        "api.AlertHistoryReport": {
+            "type": "object",
+            "properties": {
+                "alerts": {
+                    "description": "Alerts seen (most recently seen first)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/alerthistory.Record"
+                    }
+                }
+            }
+        },
+        "api.AlertItem": {
+            "type": "object",
+            "properties": {
+                "area_description": {
+                    "description": "Affected Area description",
+                    "type": "string"
+                },
+                "certainty": {
+                    "description": "Certainty of the event",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Long description of the event",
+                    "type": "string"
+                },
+                "end": {
+                    "description": "Event end time",
+                    "type": "string"
+                },
+                "event": {
+                    "description": "Short event summary",
+                    "type": "string"
+                },
+                "eventendingtime": {
+                    "description": "When the event is expected to end (if the NWS includes it)",
+                    "type": "string"
+                },
+                "geometry": {
+                    "description": "The area the alert covers as a GeoJSON geometry (if it has one)",
+                    "type": "object"
+                },
+                "headline": {
                     "description": "Full headline description",
                     "type": "string"
                 },
+                "id": {
+                    "description": "Unique alert identifier",
+                    "type": "string"
+                },
+                "messagetype": {
+                    "description": "Message type (Alert, Update or Cancel)",
+                    "type": "string"
+                },
+                "nwsheadline": {
+                    "description": "The NWS headline (like 'TORNADO WARNING IN EFFECT UNTIL 6 PM EDT')",
+                    "type": "string"
+                },
+                "onset": {
+                    "description": "When the event is expected to start",
+                    "type": "string"
+                },
                 "sender": {
                     "description": "Sender (email) of the event",
                     "type": "string"
@@ -318,17 +926,32 @@ const docTemplate = `{
                     "description": "Sender name of the event",
                     "type": "string"
                 },
+                "sent": {
+                    "description": "When the alert message was sent",
+                    "type": "string"
+                },
                 "severity": {
                     "description": "Severity of the event",
                     "type": "string"
                 },
                 "start": {
-                    "description": "Event start time",
+                    "description": "When the alert takes effect",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Alert status (Actual, Exercise, System, Test or Draft)",
                     "type": "string"
                 },
                 "urgency": {
                     "description": "Urgency of the event",
                     "type": "string"
+                },
+                "vtec": {
+                    "description": "Parsed VTEC details (tells if the alert is new, continued, cancelled, etc)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AlertVTEC"
+                    }
                 }
             }
         },
@@ -346,108 +969,497 @@ const docTemplate = `{
                     "description": "URL to see active alerts on the NWS website for the current NWS zone",
                     "type": "string"
                 },
-                "city": {
-                    "description": "City name",
+                "city": {
+                    "description": "City name",
+                    "type": "string"
+                },
+                "county": {
+                    "description": "National weather service county",
+                    "type": "string"
+                },
+                "latitude": {
+                    "description": "Latitude",
+                    "type": "number"
+                },
+                "longitude": {
+                    "description": "Longitude",
+                    "type": "number"
+                },
+                "state": {
+                    "description": "State name",
+                    "type": "string"
+                }
+            }
+        },
+        "api.AlertStreamEvent": {
+            "type": "object",
+            "properties": {
+                "alert": {
+                    "description": "The alert (for expired alerts, the last version we saw)",
+                    "$ref": "#/definitions/api.AlertItem"
+                },
+                "type": {
+                    "description": "alert.new, alert.updated or alert.expired",
+                    "type": "string"
+                },
+                "zone": {
+                    "description": "The NWS zones polled for the alert (the forecast zone and county, like GAZ045,GAC135)",
+                    "type": "string"
+                }
+            }
+        },
+        "api.AlertVTEC": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Action code (like NEW, CON, EXT, CAN or EXP)",
+                    "type": "string"
+                },
+                "actiondescription": {
+                    "description": "Human readable action (like 'New' or 'Cancelled')",
+                    "type": "string"
+                },
+                "begin": {
+                    "description": "When the event begins (left out if it's already in progress)",
+                    "type": "string"
+                },
+                "end": {
+                    "description": "When the event ends (left out if it's not known)",
+                    "type": "string"
+                },
+                "eventtrackingnumber": {
+                    "description": "Event tracking number (unique for the office, phenomena and significance in a year)",
+                    "type": "integer"
+                },
+                "office": {
+                    "description": "Issuing office (like KFFC)",
+                    "type": "string"
+                },
+                "phenomena": {
+                    "description": "Phenomena code (like TO for tornado or SV for severe thunderstorm)",
+                    "type": "string"
+                },
+                "productclass": {
+                    "description": "O (operational), T (test), E (experimental) or X (experimental VTEC)",
+                    "type": "string"
+                },
+                "raw": {
+                    "description": "The original VTEC string",
+                    "type": "string"
+                },
+                "significance": {
+                    "description": "Significance code (W for warning, A for watch, Y for advisory, S for statement)",
+                    "type": "string"
+                }
+            }
+        },
+        "api.AlertsRequest": {
+            "type": "object",
+            "properties": {
+                "lat": {
+                    "type": "string"
+                },
+                "long": {
+                    "type": "string"
+                },
+                "min_certainty": {
+                    "description": "Only include alerts at least this certain (Unlikely, Possible, Likely or Observed)",
+                    "type": "string"
+                },
+                "min_severity": {
+                    "description": "Only include alerts at least this severe (Minor, Moderate, Severe or Extreme)",
+                    "type": "string"
+                },
+                "min_urgency": {
+                    "description": "Only include alerts at least this urgent (Past, Future, Expected or Immediate)",
+                    "type": "string"
+                },
+                "statuses": {
+                    "description": "Alert statuses to include (Actual, Exercise, System, Test or Draft).  Defaults to Actual",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.AstronomyReport": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "The date of the report (YYYY-MM-DD)",
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "moon": {
+                    "$ref": "#/definitions/api.MoonReport"
+                },
+                "sun": {
+                    "$ref": "#/definitions/api.SunReport"
+                },
+                "timezone": {
+                    "description": "The timezone used",
+                    "type": "string"
+                }
+            }
+        },
+        "api.AstronomyRequest": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "The date to get data for (YYYY-MM-DD).  Defaults to today",
+                    "type": "string"
+                },
+                "lat": {
+                    "type": "string"
+                },
+                "long": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "The timezone to use (like America/New_York).  Defaults to UTC",
+                    "type": "string"
+                }
+            }
+        },
+        "api.CalendarBlock": {
+            "type": "object",
+            "properties": {
+                "busy": {
+                    "description": "True if any event happens during the block",
+                    "type": "boolean"
+                },
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CalendarConflict": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "description": "When the last event in the group ends",
+                    "type": "string"
+                },
+                "events": {
+                    "description": "The overlapping events (sorted by start time)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CalendarEvent"
+                    }
+                },
+                "start": {
+                    "description": "When the first event in the group starts",
+                    "type": "string"
+                }
+            }
+        },
+        "api.CalendarDay": {
+            "type": "object",
+            "properties": {
+                "conflicts": {
+                    "description": "Groups of events that overlap each other (when analyze is set)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CalendarConflict"
+                    }
+                },
+                "date": {
+                    "description": "The local date (YYYY-MM-DD)",
+                    "type": "string"
+                },
+                "events": {
+                    "description": "Events on the day (including events that started earlier or end later), sorted by start time",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CalendarEvent"
+                    }
+                },
+                "freebusy": {
+                    "description": "Free and busy blocks within working hours (when analyze is set)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CalendarBlock"
+                    }
+                }
+            }
+        },
+        "api.CalendarEvent": {
+            "type": "object",
+            "properties": {
+                "allday": {
+                    "description": "True if the event lasts all day (start and end are local midnights)",
+                    "type": "boolean"
+                },
+                "calendar": {
+                    "description": "The name of the calendar the event came from",
+                    "type": "string"
+                },
+                "color": {
+                    "description": "The display color of the calendar the event came from",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Event long description",
+                    "type": "string"
+                },
+                "endtime": {
+                    "description": "Event end time",
+                    "type": "string"
+                },
+                "location": {
+                    "description": "Where the event is",
+                    "type": "string"
+                },
+                "organizer": {
+                    "description": "Who organized the event (their name, or email address if there's no name)",
+                    "type": "string"
+                },
+                "recurring": {
+                    "description": "True if the event is an instance of a repeating event",
+                    "type": "boolean"
+                },
+                "starttime": {
+                    "description": "Event start time",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "confirmed, tentative or cancelled (blank if the calendar doesn't say)",
+                    "type": "string"
+                },
+                "summary": {
+                    "description": "Event summary",
+                    "type": "string"
+                },
+                "uid": {
+                    "description": "Unique event id",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Link to more information about the event",
+                    "type": "string"
+                }
+            }
+        },
+        "api.CalendarHolidays": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "description": "Optional display color (like '#3366cc')",
+                    "type": "string"
+                },
+                "country": {
+                    "description": "Country code (like US).  Defaults to the configured country",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Optional calendar name for the holidays (defaults to Holidays)",
+                    "type": "string"
+                },
+                "region": {
+                    "description": "Optional region code (like GA) to include regional holidays",
+                    "type": "string"
+                }
+            }
+        },
+        "api.CalendarNextEvent": {
+            "type": "object",
+            "properties": {
+                "event": {
+                    "$ref": "#/definitions/api.CalendarEvent"
+                },
+                "startsin": {
+                    "description": "Minutes until the event starts (rounded up)",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.CalendarRequest": {
+            "type": "object",
+            "properties": {
+                "analyze": {
+                    "description": "Include free/busy blocks and conflicts for each day, and the next event",
+                    "type": "boolean"
+                },
+                "calendars": {
+                    "description": "Optional list of calendars to merge",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CalendarSource"
+                    }
+                },
+                "days": {
+                    "description": "Optional number of days to include (instead of end)",
+                    "type": "integer"
+                },
+                "end": {
+                    "description": "Optional last day (YYYY-MM-DD, inclusive).  Defaults to the start day",
+                    "type": "string"
+                },
+                "holidays": {
+                    "description": "Optional public holidays to include as all-day events",
+                    "$ref": "#/definitions/api.CalendarHolidays"
+                },
+                "include_cancelled": {
+                    "description": "Include cancelled events (they're left out by default)",
+                    "type": "boolean"
+                },
+                "start": {
+                    "description": "Optional first day (YYYY-MM-DD in the timezone given).  Defaults to today",
+                    "type": "string"
+                },
+                "timezone": {
                     "type": "string"
                 },
-                "county": {
-                    "description": "National weather service county",
+                "url": {
+                    "description": "Optional single calendar url (use calendars for more than one)",
                     "type": "string"
                 },
-                "latitude": {
-                    "description": "Latitude",
-                    "type": "number"
-                },
-                "longitude": {
-                    "description": "Longitude",
-                    "type": "number"
-                },
-                "state": {
-                    "description": "State name",
+                "workday_end": {
+                    "description": "Optional end of working hours for free/busy blocks (HH:MM).  Defaults to the configured end",
                     "type": "string"
                 },
-                "version": {
-                    "description": "Service version",
+                "workday_start": {
+                    "description": "Optional start of working hours for free/busy blocks (HH:MM).  Defaults to the configured start",
                     "type": "string"
                 }
             }
         },
-        "api.AlertsRequest": {
+        "api.CalendarResponse": {
             "type": "object",
             "properties": {
-                "lat": {
+                "currentlocaltime": {
+                    "description": "Sanity check:  Current local time in the timezone given",
                     "type": "string"
                 },
-                "long": {
+                "days": {
+                    "description": "The calendar events found, grouped by local date",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CalendarDay"
+                    }
+                },
+                "end": {
+                    "description": "The end of the last day included",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Calendars that couldn't be included (by calendar name)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ProviderError"
+                    }
+                },
+                "events": {
+                    "description": "The calendar events found (sorted by start time)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CalendarEvent"
+                    }
+                },
+                "nextevent": {
+                    "description": "The next event to start after the current local time (when analyze is set)",
+                    "$ref": "#/definitions/api.CalendarNextEvent"
+                },
+                "start": {
+                    "description": "The start of the first day included",
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "The timezone used",
                     "type": "string"
                 }
             }
         },
-        "api.CalendarEvent": {
+        "api.CalendarSource": {
             "type": "object",
             "properties": {
-                "description": {
-                    "description": "Event long description",
+                "caldav": {
+                    "description": "Optional name of a CalDAV calendar configured on the server (used instead of url)",
                     "type": "string"
                 },
-                "endtime": {
-                    "description": "Event end time",
-                    "type": "string"
-                },
-                "starttime": {
-                    "description": "Event start time",
+                "color": {
+                    "description": "Optional display color (like '#3366cc')",
                     "type": "string"
                 },
-                "summary": {
-                    "description": "Event summary",
+                "name": {
+                    "description": "Optional display name (defaults to the url host or CalDAV calendar name)",
                     "type": "string"
                 },
-                "uid": {
-                    "description": "Unique event id",
+                "url": {
+                    "description": "The iCal url",
                     "type": "string"
                 }
             }
         },
-        "api.CalendarRequest": {
+        "api.ErrorResponse": {
             "type": "object",
             "properties": {
-                "timezone": {
+                "code": {
+                    "description": "Machine readable error code (like 'upstream_timeout')",
                     "type": "string"
                 },
-                "url": {
+                "message": {
                     "type": "string"
+                },
+                "providers": {
+                    "description": "When several upstream providers were tried, why each one failed",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ProviderError"
+                    }
                 }
             }
         },
-        "api.CalendarResponse": {
+        "api.HolidayItem": {
             "type": "object",
             "properties": {
-                "currentlocaltime": {
-                    "description": "Sanity check:  Current local time in the timezone given",
+                "country": {
                     "type": "string"
                 },
-                "events": {
-                    "description": "The calendar events found",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/api.CalendarEvent"
-                    }
+                "date": {
+                    "description": "The date of the holiday (YYYY-MM-DD)",
+                    "type": "string"
                 },
-                "timezone": {
-                    "description": "The timezone used",
+                "name": {
                     "type": "string"
                 },
-                "version": {
-                    "description": "Service version",
+                "observed": {
+                    "description": "The date the holiday is observed (YYYY-MM-DD).  Different from the date when it falls on a weekend",
+                    "type": "string"
+                },
+                "region": {
+                    "description": "Set for regional holidays",
                     "type": "string"
                 }
             }
         },
-        "api.ErrorResponse": {
+        "api.HolidayReport": {
             "type": "object",
             "properties": {
-                "message": {
+                "country": {
+                    "type": "string"
+                },
+                "holidays": {
+                    "description": "Sorted by the date they're observed",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.HolidayItem"
+                    }
+                },
+                "region": {
                     "type": "string"
+                },
+                "year": {
+                    "type": "integer"
                 }
             }
         },
@@ -478,10 +1490,6 @@ const docTemplate = `{
                 "long": {
                     "type": "number"
                 },
-                "version": {
-                    "description": "Service version",
-                    "type": "string"
-                },
                 "zoom": {
                     "type": "integer"
                 }
@@ -498,6 +1506,71 @@ const docTemplate = `{
                 }
             }
         },
+        "api.MoonReport": {
+            "type": "object",
+            "properties": {
+                "illumination": {
+                    "description": "Fraction of the moon's disk that is lit (0 - 1)",
+                    "type": "number"
+                },
+                "moonrise": {
+                    "type": "string"
+                },
+                "moonset": {
+                    "type": "string"
+                },
+                "phase": {
+                    "description": "0 and 1 are 'new moon', 0.25 is 'first quarter', 0.5 is 'full moon', 0.75 is 'last quarter'",
+                    "type": "number"
+                },
+                "phaseName": {
+                    "description": "Human readable moon phase (like 'Waxing Gibbous')",
+                    "type": "string"
+                }
+            }
+        },
+        "api.NWSPointsEntry": {
+            "type": "object",
+            "properties": {
+                "fetchedAt": {
+                    "description": "When the lookup was fetched from the NWS points service",
+                    "type": "string"
+                },
+                "key": {
+                    "description": "The (rounded) coordinates for the lookup",
+                    "type": "string"
+                }
+            }
+        },
+        "api.NWSPointsList": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "description": "The stored lookups",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.NWSPointsEntry"
+                    }
+                },
+                "path": {
+                    "description": "Where the store is",
+                    "type": "string"
+                }
+            }
+        },
+        "api.NWSPointsPurgeResult": {
+            "type": "object",
+            "properties": {
+                "cleared": {
+                    "description": "How many lookups were cleared from memory",
+                    "type": "integer"
+                },
+                "removed": {
+                    "description": "How many lookups were removed from the on-disk store",
+                    "type": "integer"
+                }
+            }
+        },
         "api.NewsItem": {
             "type": "object",
             "properties": {
@@ -529,9 +1602,6 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/api.NewsItem"
                     }
-                },
-                "version": {
-                    "type": "string"
                 }
             }
         },
@@ -561,10 +1631,6 @@ const docTemplate = `{
                     "description": "The start time for this report",
                     "type": "string"
                 },
-                "version": {
-                    "description": "Service version information",
-                    "type": "string"
-                },
                 "zip": {
                     "description": "The zipcode for the report",
                     "type": "string"
@@ -579,6 +1645,61 @@ const docTemplate = `{
                 }
             }
         },
+        "api.ProviderError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Machine readable error code",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "What went wrong",
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "The provider name",
+                    "type": "string"
+                }
+            }
+        },
+        "api.SunReport": {
+            "type": "object",
+            "properties": {
+                "alwaysDown": {
+                    "description": "The sun never rises on this day",
+                    "type": "boolean"
+                },
+                "alwaysUp": {
+                    "description": "The sun never sets on this day",
+                    "type": "boolean"
+                },
+                "civilDawn": {
+                    "type": "string"
+                },
+                "civilDusk": {
+                    "type": "string"
+                },
+                "daylightDuration": {
+                    "description": "Seconds between sunrise and sunset",
+                    "type": "integer"
+                },
+                "nauticalDawn": {
+                    "type": "string"
+                },
+                "nauticalDusk": {
+                    "type": "string"
+                },
+                "solarNoon": {
+                    "type": "string"
+                },
+                "sunrise": {
+                    "type": "string"
+                },
+                "sunset": {
+                    "type": "string"
+                }
+            }
+        },
         "api.WeatherDataBlock": {
             "type": "object",
             "properties": {
@@ -605,12 +1726,35 @@ const docTemplate = `{
                 "cloudCover": {
                     "type": "number"
                 },
+                "daylightDuration": {
+                    "description": "Seconds between sunrise and sunset",
+                    "type": "integer"
+                },
+                "dewPoint": {
+                    "type": "number"
+                },
                 "humidity": {
                     "type": "number"
                 },
                 "icon": {
                     "type": "string"
                 },
+                "moonPhase": {
+                    "description": "0 and 1 are 'new moon', 0.25 is 'first quarter', 0.5 is 'full moon', 0.75 is 'last quarter'",
+                    "type": "number"
+                },
+                "moonPhaseName": {
+                    "description": "Human readable moon phase (like 'Waxing Gibbous')",
+                    "type": "string"
+                },
+                "moonrise": {
+                    "description": "Moonrise time (unix)",
+                    "type": "integer"
+                },
+                "moonset": {
+                    "description": "Moonset time (unix)",
+                    "type": "integer"
+                },
                 "ozone": {
                     "type": "number"
                 },
@@ -635,6 +1779,14 @@ const docTemplate = `{
                 "summary": {
                     "type": "string"
                 },
+                "sunrise": {
+                    "description": "Sunrise time (unix)",
+                    "type": "integer"
+                },
+                "sunset": {
+                    "description": "Sunset time (unix)",
+                    "type": "integer"
+                },
                 "temperature": {
                     "type": "number"
                 },
@@ -667,6 +1819,13 @@ const docTemplate = `{
         "api.WeatherReport": {
             "type": "object",
             "properties": {
+                "alerts": {
+                    "description": "Active alerts (if the provider includes them)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AlertItem"
+                    }
+                },
                 "apicalls": {
                     "type": "integer"
                 },
@@ -697,19 +1856,111 @@ const docTemplate = `{
                         "$ref": "#/definitions/api.MinuteDataPoint"
                     }
                 },
-                "version": {
+                "provider": {
+                    "description": "The weather provider that answered",
                     "type": "string"
+                },
+                "units": {
+                    "description": "The units used in the report",
+                    "$ref": "#/definitions/api.WeatherUnits"
                 }
             }
         },
         "api.WeatherRequest": {
             "type": "object",
             "properties": {
+                "lang": {
+                    "description": "Language for descriptions (like 'en' or 'de').  Only supported by some providers",
+                    "type": "string"
+                },
                 "lat": {
                     "type": "string"
                 },
                 "long": {
                     "type": "string"
+                },
+                "units": {
+                    "description": "Unit system: imperial (default), metric or standard",
+                    "type": "string"
+                }
+            }
+        },
+        "api.WeatherUnits": {
+            "type": "object",
+            "properties": {
+                "precipitation": {
+                    "description": "Precipitation accumulation unit",
+                    "type": "string"
+                },
+                "pressure": {
+                    "description": "Pressure unit",
+                    "type": "string"
+                },
+                "system": {
+                    "description": "The unit system (imperial, metric or standard)",
+                    "type": "string"
+                },
+                "temperature": {
+                    "description": "Temperature unit",
+                    "type": "string"
+                },
+                "visibility": {
+                    "description": "Visibility unit",
+                    "type": "string"
+                },
+                "windSpeed": {
+                    "description": "Wind speed unit",
+                    "type": "string"
+                }
+            }
+        },
+        "api.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "alertid": {
+                    "type": "string"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "description": "The alert event (like 'Tornado Warning')",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "The last HTTP status code (0 if we never got a response)",
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Just the scheme and host (the rest of a webhook url is often a secret)",
+                    "type": "string"
+                }
+            }
+        },
+        "api.WebhookDeliveryLog": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "description": "Most recent first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WebhookDelivery"
+                    }
                 }
             }
         }
@@ -0,0 +1,151 @@
+package alerthistory
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/integrations/nrmongo"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore keeps alert history in the dashboard.alerthistory collection
+type MongoStore struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewMongoStore creates a store using the MongoDB server at the given uri
+func NewMongoStore(ctx context.Context, uri string) (*MongoStore, error) {
+
+	clientOptions := options.Client().ApplyURI(uri).SetMonitor(nrmongo.NewCommandMonitor(nil))
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("problem connecting to MongoDB: %v", err)
+	}
+
+	collection := client.Database("dashboard").Collection("alerthistory")
+
+	//	Records are looked up by location and alert, and listed by when they were last seen
+	_, err = collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "location", Value: 1}, {Key: "alertid", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "lastseen", Value: -1}}},
+	})
+	if err != nil {
+		client.Disconnect(ctx)
+		return nil, fmt.Errorf("problem creating alert history indexes: %v", err)
+	}
+
+	return &MongoStore{client: client, collection: collection}, nil
+}
+
+// Close disconnects from MongoDB
+func (m *MongoStore) Close(ctx context.Context) error {
+	return m.client.Disconnect(ctx)
+}
+
+// Record saves the alerts seen at a time
+func (m *MongoStore) Record(ctx context.Context, records []Record, seen time.Time) error {
+
+	txn := newrelic.FromContext(ctx)
+	segment := txn.StartSegment("AlertHistory Record")
+	defer segment.End()
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	models := []mongo.WriteModel{}
+	for _, record := range records {
+		record.LastSeen = seen
+		set, err := recordFields(record)
+		if err != nil {
+			return err
+		}
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{Key: "location", Value: record.Location}, {Key: "alertid", Value: record.AlertID}}).
+			SetUpdate(bson.M{
+				"$set":         set,
+				"$setOnInsert": bson.M{"firstseen": seen},
+			}).
+			SetUpsert(true))
+	}
+
+	_, err := m.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return fmt.Errorf("problem updating alert history: %v", err)
+	}
+
+	return nil
+}
+
+// Find gets the records that match the query (most recently seen first)
+func (m *MongoStore) Find(ctx context.Context, query Query) ([]Record, error) {
+
+	txn := newrelic.FromContext(ctx)
+	segment := txn.StartSegment("AlertHistory Find")
+	defer segment.End()
+
+	retval := []Record{}
+
+	filter := bson.D{}
+	if query.Location != "" {
+		filter = append(filter, bson.E{Key: "location", Value: query.Location})
+	}
+	if query.Event != "" {
+		filter = append(filter, bson.E{Key: "event", Value: bson.M{"$regex": "^" + regexp.QuoteMeta(query.Event) + "$", "$options": "i"}})
+	}
+	if !query.From.IsZero() {
+		filter = append(filter, bson.E{Key: "lastseen", Value: bson.M{"$gte": query.From}})
+	}
+	if !query.To.IsZero() {
+		filter = append(filter, bson.E{Key: "firstseen", Value: bson.M{"$lte": query.To}})
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "lastseen", Value: -1}, {Key: "firstseen", Value: -1}, {Key: "alertid", Value: 1}})
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+
+	cur, err := m.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("problem finding items in dashboard.alerthistory: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var record Record
+		if err := cur.Decode(&record); err != nil {
+			return nil, fmt.Errorf("problem decoding alert history item: %v", err)
+		}
+
+		retval = append(retval, record)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("problem navigating through the list of items: %v", err)
+	}
+
+	return retval, nil
+}
+
+// recordFields gets the fields of a record to update (everything but when it was first seen)
+func recordFields(record Record) (bson.M, error) {
+	data, err := bson.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("problem encoding alert history item: %v", err)
+	}
+
+	retval := bson.M{}
+	if err := bson.Unmarshal(data, &retval); err != nil {
+		return nil, fmt.Errorf("problem encoding alert history item: %v", err)
+	}
+	delete(retval, "firstseen")
+
+	return retval, nil
+}
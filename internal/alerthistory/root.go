@@ -0,0 +1,176 @@
+// Package alerthistory keeps a record of the weather alerts the service has seen.  NWS drops alerts
+// once they expire, so this is the only way to know what a location experienced in the past.
+package alerthistory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is a single alert seen at a location
+type Record struct {
+	Location  string    `json:"location" bson:"location"`   // The (rounded) coordinates the alert was seen for
+	City      string    `json:"city" bson:"city"`           // City name for the location
+	State     string    `json:"state" bson:"state"`         // State name for the location
+	County    string    `json:"county" bson:"county"`       // National weather service county for the location
+	AlertID   string    `json:"alertid" bson:"alertid"`     // Unique alert identifier
+	Event     string    `json:"event" bson:"event"`         // Short event summary (like 'Tornado Warning')
+	Headline  string    `json:"headline" bson:"headline"`   // Full headline description
+	Severity  string    `json:"severity" bson:"severity"`   // Severity of the alert
+	Urgency   string    `json:"urgency" bson:"urgency"`     // Urgency of the alert
+	Certainty string    `json:"certainty" bson:"certainty"` // Certainty of the alert
+	Status    string    `json:"status" bson:"status"`       // Status of the alert (like Actual or Test)
+	Start     time.Time `json:"start" bson:"start"`         // When the alert took effect
+	End       time.Time `json:"end" bson:"end"`             // When the alert ends
+	FirstSeen time.Time `json:"firstseen" bson:"firstseen"` // The first time the service saw the alert
+	LastSeen  time.Time `json:"lastseen" bson:"lastseen"`   // The most recent time the service saw the alert
+}
+
+// Query describes which records to find.  Empty fields aren't used to filter
+type Query struct {
+	Location string    // Only records for this location (rounded coordinates)
+	Event    string    // Only records for this event type (not case sensitive)
+	From     time.Time // Only records seen at or after this time
+	To       time.Time // Only records seen at or before this time
+	Limit    int       // The most records to return (0 for no limit)
+}
+
+// Store keeps alert history
+type Store interface {
+	// Record saves the alerts seen at a time.  Alerts we've already seen just get their
+	// last seen time (and any changed details) updated
+	Record(ctx context.Context, records []Record, seen time.Time) error
+
+	// Find gets the records that match the query (most recently seen first)
+	Find(ctx context.Context, query Query) ([]Record, error)
+}
+
+// Matches returns true if the record matches the query (ignoring the limit)
+func (q Query) Matches(record Record) bool {
+	if q.Location != "" && record.Location != q.Location {
+		return false
+	}
+
+	if q.Event != "" && !strings.EqualFold(record.Event, q.Event) {
+		return false
+	}
+
+	//	The record matches if it was seen at any point in the range
+	if !q.From.IsZero() && record.LastSeen.Before(q.From) {
+		return false
+	}
+
+	if !q.To.IsZero() && record.FirstSeen.After(q.To) {
+		return false
+	}
+
+	return true
+}
+
+// MemoryStore keeps alert history in memory.  It's used when there's no database configured,
+// so history only goes back as far as the last restart.  Records that haven't been seen within
+// the retention period are dropped, and so are the least recently seen records past the size cap
+type MemoryStore struct {
+	mu         sync.Mutex
+	records    map[string]Record
+	maxRecords int
+	retention  time.Duration
+}
+
+// NewMemoryStore creates an empty in-memory store that keeps at most maxRecords records, each for
+// the retention period after it was last seen.  0 means there's no limit
+func NewMemoryStore(maxRecords int, retention time.Duration) *MemoryStore {
+	return &MemoryStore{
+		records:    map[string]Record{},
+		maxRecords: maxRecords,
+		retention:  retention,
+	}
+}
+
+// Record saves the alerts seen at a time
+func (m *MemoryStore) Record(ctx context.Context, records []Record, seen time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, record := range records {
+		key := record.Location + "|" + record.AlertID
+
+		record.FirstSeen = seen
+		if existing, found := m.records[key]; found {
+			record.FirstSeen = existing.FirstSeen
+		}
+		record.LastSeen = seen
+
+		m.records[key] = record
+	}
+
+	m.prune(seen)
+
+	return nil
+}
+
+// prune drops the records past the retention period (as of the given time), then the least recently
+// seen records until the store is back under its size cap.  The caller must hold the lock
+func (m *MemoryStore) prune(now time.Time) {
+	if m.retention > 0 {
+		cutoff := now.Add(-m.retention)
+		for key, record := range m.records {
+			if record.LastSeen.Before(cutoff) {
+				delete(m.records, key)
+			}
+		}
+	}
+
+	if m.maxRecords <= 0 || len(m.records) <= m.maxRecords {
+		return
+	}
+
+	keys := make([]string, 0, len(m.records))
+	for key := range m.records {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return m.records[keys[i]].LastSeen.Before(m.records[keys[j]].LastSeen)
+	})
+
+	for _, key := range keys[:len(keys)-m.maxRecords] {
+		delete(m.records, key)
+	}
+}
+
+// Find gets the records that match the query (most recently seen first)
+func (m *MemoryStore) Find(ctx context.Context, query Query) ([]Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	retval := []Record{}
+	for _, record := range m.records {
+		if query.Matches(record) {
+			retval = append(retval, record)
+		}
+	}
+
+	sortRecords(retval)
+
+	if query.Limit > 0 && len(retval) > query.Limit {
+		retval = retval[:query.Limit]
+	}
+
+	return retval, nil
+}
+
+// sortRecords sorts records so the most recently seen come first (then by when they were first seen)
+func sortRecords(records []Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].LastSeen.Equal(records[j].LastSeen) {
+			return records[i].LastSeen.After(records[j].LastSeen)
+		}
+		if !records[i].FirstSeen.Equal(records[j].FirstSeen) {
+			return records[i].FirstSeen.After(records[j].FirstSeen)
+		}
+		return records[i].AlertID < records[j].AlertID
+	})
+}
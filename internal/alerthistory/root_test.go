@@ -0,0 +1,124 @@
+package alerthistory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/alerthistory"
+)
+
+func TestMemoryStore_Record_KeepsFirstSeen(t *testing.T) {
+	//	Arrange
+	store := alerthistory.NewMemoryStore(0, 0)
+	first := time.Date(2022, 6, 1, 17, 0, 0, 0, time.UTC)
+	second := first.Add(10 * time.Minute)
+	record := alerthistory.Record{Location: "33.87,-84.00", AlertID: "urn:1", Event: "Tornado Warning"}
+
+	//	Act
+	err := store.Record(context.Background(), []alerthistory.Record{record}, first)
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+
+	record.Headline = "Updated headline"
+	err = store.Record(context.Background(), []alerthistory.Record{record}, second)
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+
+	records, err := store.Find(context.Background(), alerthistory.Query{})
+
+	//	Assert
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record but got %v instead", len(records))
+	}
+
+	if !records[0].FirstSeen.Equal(first) || !records[0].LastSeen.Equal(second) {
+		t.Errorf("Expected first seen %v and last seen %v but got %v and %v instead", first, second, records[0].FirstSeen, records[0].LastSeen)
+	}
+
+	if records[0].Headline != "Updated headline" {
+		t.Errorf("Expected the details to be updated but got %v instead", records[0].Headline)
+	}
+}
+
+func TestMemoryStore_Find_Filters(t *testing.T) {
+	//	Arrange
+	store := alerthistory.NewMemoryStore(0, 0)
+	june := time.Date(2022, 6, 1, 17, 0, 0, 0, time.UTC)
+	july := time.Date(2022, 7, 1, 17, 0, 0, 0, time.UTC)
+
+	store.Record(context.Background(), []alerthistory.Record{
+		{Location: "33.87,-84.00", AlertID: "urn:1", Event: "Tornado Warning"},
+		{Location: "40.71,-74.01", AlertID: "urn:2", Event: "Tornado Warning"},
+	}, june)
+	store.Record(context.Background(), []alerthistory.Record{
+		{Location: "33.87,-84.00", AlertID: "urn:3", Event: "Heat Advisory"},
+		{Location: "33.87,-84.00", AlertID: "urn:4", Event: "Tornado Warning"},
+	}, july)
+
+	tests := []struct {
+		name     string
+		query    alerthistory.Query
+		expected []string
+	}{
+		{"everything", alerthistory.Query{}, []string{"urn:3", "urn:4", "urn:1", "urn:2"}},
+		{"location", alerthistory.Query{Location: "33.87,-84.00"}, []string{"urn:3", "urn:4", "urn:1"}},
+		{"event", alerthistory.Query{Event: "tornado warning"}, []string{"urn:4", "urn:1", "urn:2"}},
+		{"from", alerthistory.Query{From: june.Add(time.Hour)}, []string{"urn:3", "urn:4"}},
+		{"to", alerthistory.Query{To: june.Add(time.Hour)}, []string{"urn:1", "urn:2"}},
+		{"limit", alerthistory.Query{Location: "33.87,-84.00", Event: "Tornado Warning", Limit: 1}, []string{"urn:4"}},
+	}
+
+	for _, test := range tests {
+		//	Act
+		records, err := store.Find(context.Background(), test.query)
+
+		//	Assert
+		if err != nil {
+			t.Errorf("%s: Returned error and we didn't expect that: %v", test.name, err)
+		}
+
+		ids := []string{}
+		for _, record := range records {
+			ids = append(ids, record.AlertID)
+		}
+
+		if len(ids) != len(test.expected) {
+			t.Errorf("%s: Expected %v but got %v instead", test.name, test.expected, ids)
+			continue
+		}
+
+		for i := range ids {
+			if ids[i] != test.expected[i] {
+				t.Errorf("%s: Expected %v but got %v instead", test.name, test.expected, ids)
+				break
+			}
+		}
+	}
+}
+
+func TestMemoryStore_Record_DropsOldAndOverCapRecords(t *testing.T) {
+	//	Arrange
+	store := alerthistory.NewMemoryStore(2, 24*time.Hour)
+	start := time.Date(2022, 6, 1, 17, 0, 0, 0, time.UTC)
+
+	//	Act
+	store.Record(context.Background(), []alerthistory.Record{{Location: "33.87,-84.00", AlertID: "urn:old"}}, start)
+	store.Record(context.Background(), []alerthistory.Record{{Location: "33.87,-84.00", AlertID: "urn:1"}}, start.Add(48*time.Hour))
+	store.Record(context.Background(), []alerthistory.Record{{Location: "33.87,-84.00", AlertID: "urn:2"}}, start.Add(49*time.Hour))
+	store.Record(context.Background(), []alerthistory.Record{{Location: "33.87,-84.00", AlertID: "urn:3"}}, start.Add(50*time.Hour))
+
+	records, _ := store.Find(context.Background(), alerthistory.Query{})
+
+	//	Assert
+	//	urn:old is past the retention period, and urn:1 is the least recently seen once we're over the cap
+	if len(records) != 2 || records[0].AlertID != "urn:3" || records[1].AlertID != "urn:2" {
+		t.Errorf("Expected only urn:3 and urn:2 to be kept but got %+v instead", records)
+	}
+}
@@ -0,0 +1,178 @@
+package astronomy
+
+import (
+	"math"
+	"time"
+)
+
+// MoonInfo describes the moon for a location on a given day.  Moonrise and moonset
+// are left as zero times if they don't happen on the given day (the moon rises about
+// 50 minutes later each day, so about once a month one of them is skipped)
+type MoonInfo struct {
+	Phase        float64 // 0 and 1 are 'new moon', 0.25 is 'first quarter', 0.5 is 'full moon', 0.75 is 'last quarter'
+	PhaseName    string  // Human readable moon phase (like 'Waxing Gibbous')
+	Illumination float64 // Fraction of the moon's disk that is lit (0 - 1)
+	Moonrise     time.Time
+	Moonset      time.Time
+}
+
+// phaseNames are the names of the moon phases, starting with the new moon
+var phaseNames = []string{"New Moon", "Waxing Crescent", "First Quarter", "Waxing Gibbous", "Full Moon", "Waning Gibbous", "Last Quarter", "Waning Crescent"}
+
+// GetMoonInfo gets the moon details for the given latitude and longitude on the calendar day
+// of the given date (in the date's location).  The phase is calculated at local noon.
+// Times are returned in the date's location
+func GetMoonInfo(date time.Time, lat, long float64) MoonInfo {
+	location := date.Location()
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
+	end := start.AddDate(0, 0, 1)
+	noon := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, location)
+
+	retval := MoonInfo{
+		Phase:        MoonPhase(noon),
+		Illumination: MoonIllumination(noon),
+	}
+	retval.PhaseName = PhaseName(retval.Phase)
+	retval.Moonrise, retval.Moonset = moonRiseAndSet(start, end, lat, long)
+
+	if !retval.Moonrise.IsZero() {
+		retval.Moonrise = retval.Moonrise.In(location)
+	}
+	if !retval.Moonset.IsZero() {
+		retval.Moonset = retval.Moonset.In(location)
+	}
+
+	return retval
+}
+
+// MoonPhase gets the phase of the moon at the given time (0 and 1 are 'new moon', 0.5 is 'full moon')
+func MoonPhase(t time.Time) float64 {
+	elongation := normalizeDegrees(getMoonCoordinates(t).longitude - sunEclipticLongitude(t))
+	return elongation / 360
+}
+
+// MoonIllumination gets the fraction of the moon's disk that is lit at the given time
+func MoonIllumination(t time.Time) float64 {
+	return (1 - math.Cos(MoonPhase(t)*2*math.Pi)) / 2
+}
+
+// PhaseName gets the name of the given moon phase (0 and 1 are 'new moon', 0.5 is 'full moon')
+func PhaseName(phase float64) string {
+	index := int(math.Floor(phase*8+0.5)) % len(phaseNames)
+	if index < 0 {
+		index += len(phaseNames)
+	}
+	return phaseNames[index]
+}
+
+// moonCoordinates describes the geocentric position of the moon (all angles in degrees)
+type moonCoordinates struct {
+	longitude float64 // Ecliptic longitude
+	latitude  float64 // Ecliptic latitude
+	parallax  float64 // Horizontal parallax
+}
+
+// getMoonCoordinates gets the position of the moon at the given time using the low
+// precision formulae from the Astronomical Almanac (good to about 0.3 degrees)
+func getMoonCoordinates(t time.Time) moonCoordinates {
+	T := julianCentury(t)
+
+	longitude := 218.32 + 481267.881*T +
+		6.29*sinDeg(134.9+477198.85*T) -
+		1.27*sinDeg(259.2-413335.38*T) +
+		0.66*sinDeg(235.7+890534.23*T) +
+		0.21*sinDeg(269.9+954397.70*T) -
+		0.19*sinDeg(357.5+35999.05*T) -
+		0.11*sinDeg(186.6+966404.05*T)
+
+	latitude := 5.13*sinDeg(93.3+483202.03*T) +
+		0.28*sinDeg(228.2+960400.87*T) -
+		0.28*sinDeg(318.3+6003.18*T) -
+		0.17*sinDeg(217.6-407332.20*T)
+
+	parallax := 0.9508 +
+		0.0518*cosDeg(134.9+477198.85*T) +
+		0.0095*cosDeg(259.2-413335.38*T) +
+		0.0078*cosDeg(235.7+890534.23*T) +
+		0.0028*cosDeg(269.9+954397.70*T)
+
+	return moonCoordinates{
+		longitude: normalizeDegrees(longitude),
+		latitude:  latitude,
+		parallax:  parallax,
+	}
+}
+
+// moonAltitude gets how far (in degrees) the moon's upper limb is above the horizon at the
+// given time, corrected for parallax and atmospheric refraction
+func moonAltitude(t time.Time, lat, long float64) float64 {
+	moon := getMoonCoordinates(t)
+	obliquity := obliquityCorrection(julianCentury(t))
+
+	//	Convert from ecliptic to equatorial coordinates
+	rightAscension := math.Atan2(
+		sinDeg(moon.longitude)*cosDeg(obliquity)-tanDeg(moon.latitude)*sinDeg(obliquity),
+		cosDeg(moon.longitude),
+	) * degrees
+	declination := math.Asin(
+		sinDeg(moon.latitude)*cosDeg(obliquity)+cosDeg(moon.latitude)*sinDeg(obliquity)*sinDeg(moon.longitude),
+	) * degrees
+
+	//	Find the local hour angle from the sidereal time
+	hourAngle := greenwichSiderealTime(t) + long - rightAscension
+
+	altitude := math.Asin(sinDeg(lat)*sinDeg(declination)+cosDeg(lat)*cosDeg(declination)*cosDeg(hourAngle)) * degrees
+
+	//	The moon 'rises' when its altitude reaches 0.7275 * parallax - 34 arcminutes
+	return altitude - (0.7275*moon.parallax - 0.5667)
+}
+
+// greenwichSiderealTime gets the mean sidereal time at Greenwich (in degrees) for the given time
+func greenwichSiderealTime(t time.Time) float64 {
+	jd := julianDay(t)
+	T := (jd - j2000) / 36525
+	return normalizeDegrees(280.46061837 + 360.98564736629*(jd-j2000) + T*T*(0.000387933-T/38710000))
+}
+
+// moonRiseAndSet finds the moonrise and moonset between the start and end times by stepping
+// through the day and narrowing down each horizon crossing
+func moonRiseAndSet(start, end time.Time, lat, long float64) (time.Time, time.Time) {
+	const step = 10 * time.Minute
+
+	var moonrise, moonset time.Time
+
+	previous := start
+	previousAltitude := moonAltitude(previous, lat, long)
+
+	for current := start.Add(step); !current.After(end); current = current.Add(step) {
+		currentAltitude := moonAltitude(current, lat, long)
+
+		if previousAltitude < 0 && currentAltitude >= 0 && moonrise.IsZero() {
+			moonrise = bisectMoonCrossing(previous, current, lat, long, true)
+		}
+
+		if previousAltitude >= 0 && currentAltitude < 0 && moonset.IsZero() {
+			moonset = bisectMoonCrossing(previous, current, lat, long, false)
+		}
+
+		previous, previousAltitude = current, currentAltitude
+	}
+
+	return moonrise, moonset
+}
+
+// bisectMoonCrossing narrows down the time the moon crosses the horizon between the two given times
+func bisectMoonCrossing(before, after time.Time, lat, long float64, rising bool) time.Time {
+	for after.Sub(before) > time.Second {
+		middle := before.Add(after.Sub(before) / 2)
+		above := moonAltitude(middle, lat, long) >= 0
+
+		if above == rising {
+			after = middle
+		} else {
+			before = middle
+		}
+	}
+
+	return after.Truncate(time.Second)
+}
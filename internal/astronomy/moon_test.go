@@ -0,0 +1,80 @@
+package astronomy_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/astronomy"
+)
+
+// Expected phases are at the published times of new and full moons
+func TestMoonPhase_MatchesPublishedPhases(t *testing.T) {
+	tests := []struct {
+		time     time.Time
+		expected float64
+	}{
+		{time.Date(2022, 1, 2, 18, 33, 0, 0, time.UTC), 0},    // New moon
+		{time.Date(2022, 1, 17, 23, 48, 0, 0, time.UTC), 0.5}, // Full moon
+		{time.Date(2023, 8, 31, 1, 35, 0, 0, time.UTC), 0.5},  // Full (blue) moon
+		{time.Date(2024, 4, 8, 18, 21, 0, 0, time.UTC), 0},    // New moon (total solar eclipse)
+	}
+
+	for _, test := range tests {
+		phase := astronomy.MoonPhase(test.time)
+
+		//	Phases wrap around at 1 (which is also a new moon)
+		diff := math.Abs(phase - test.expected)
+		diff = math.Min(diff, 1-diff)
+
+		//	0.005 of a lunar cycle is about 3.5 hours
+		if diff > 0.005 {
+			t.Errorf("%v: expected a phase of %v but got %v instead", test.time, test.expected, phase)
+		}
+	}
+}
+
+func TestPhaseName_ReturnsExpectedNames(t *testing.T) {
+	tests := []struct {
+		phase    float64
+		expected string
+	}{
+		{0, "New Moon"},
+		{0.1, "Waxing Crescent"},
+		{0.25, "First Quarter"},
+		{0.5, "Full Moon"},
+		{0.75, "Last Quarter"},
+		{0.9, "Waning Crescent"},
+		{1, "New Moon"},
+	}
+
+	for _, test := range tests {
+		if got := astronomy.PhaseName(test.phase); got != test.expected {
+			t.Errorf("Phase %v: expected %v but got %v instead", test.phase, test.expected, got)
+		}
+	}
+}
+
+func TestGetMoonInfo_FullMoon_RisesAroundSunset(t *testing.T) {
+	london, _ := time.LoadLocation("Europe/London")
+	date := time.Date(2022, 1, 17, 0, 0, 0, 0, london)
+
+	moon := astronomy.GetMoonInfo(date, 51.5074, -0.1278)
+	sun := astronomy.GetSunTimes(date, 51.5074, -0.1278)
+
+	if moon.PhaseName != "Full Moon" {
+		t.Errorf("Expected a full moon but got %v instead", moon.PhaseName)
+	}
+
+	if moon.Illumination < 0.99 {
+		t.Errorf("Expected the moon to be fully lit but got %v instead", moon.Illumination)
+	}
+
+	if diff := moon.Moonrise.Sub(sun.Sunset); diff < -2*time.Hour || diff > 2*time.Hour {
+		t.Errorf("Expected the full moon to rise around sunset (%v) but it rose at %v", sun.Sunset, moon.Moonrise)
+	}
+
+	if moon.Moonset.IsZero() || moon.Moonset.After(sun.Sunrise.Add(2*time.Hour)) {
+		t.Errorf("Expected the full moon to set around sunrise (%v) but it set at %v", sun.Sunrise, moon.Moonset)
+	}
+}
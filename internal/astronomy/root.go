@@ -0,0 +1,56 @@
+// Package astronomy calculates sun and moon times for a location without calling
+// any outside services.  The sun calculations are based on the NOAA solar calculator
+// and the moon calculations use the low precision formulae from the Astronomical Almanac.
+package astronomy
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	degrees = 180 / math.Pi
+	radians = math.Pi / 180
+
+	// j2000 is the julian day of the J2000.0 epoch
+	j2000 = 2451545.0
+)
+
+// julianDay gets the julian day for the given time
+func julianDay(t time.Time) float64 {
+	//	UnixNano overflows outside of about 1678 - 2262, so use seconds (plus the fraction of a second)
+	seconds := float64(t.Unix()) + float64(t.Nanosecond())/float64(time.Second)
+	return seconds/float64(24*60*60) + 2440587.5
+}
+
+// julianCentury gets the number of julian centuries since J2000.0 for the given time
+func julianCentury(t time.Time) float64 {
+	return (julianDay(t) - j2000) / 36525
+}
+
+// normalizeDegrees puts the given angle in the range [0, 360)
+func normalizeDegrees(angle float64) float64 {
+	angle = math.Mod(angle, 360)
+	if angle < 0 {
+		angle += 360
+	}
+	return angle
+}
+
+func sinDeg(angle float64) float64 {
+	return math.Sin(angle * radians)
+}
+
+func cosDeg(angle float64) float64 {
+	return math.Cos(angle * radians)
+}
+
+func tanDeg(angle float64) float64 {
+	return math.Tan(angle * radians)
+}
+
+// localDay gets the start of the calendar day (in UTC) for the given date in its own location.
+// All of the calculations for a date are done relative to this time
+func localDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,182 @@
+package astronomy
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// Zenith angles (in degrees) for each of the sun events we calculate
+	zenithSunriseSunset = 90.833 // Includes atmospheric refraction and the radius of the sun
+	zenithCivil         = 96
+	zenithNautical      = 102
+)
+
+// SunTimes describes the sun events for a location on a given day.  Events that don't
+// happen on the given day (like sunrise during a polar night) are left as zero times
+type SunTimes struct {
+	Sunrise          time.Time
+	Sunset           time.Time
+	SolarNoon        time.Time
+	CivilDawn        time.Time
+	CivilDusk        time.Time
+	NauticalDawn     time.Time
+	NauticalDusk     time.Time
+	DaylightDuration time.Duration
+	AlwaysUp         bool // The sun never sets on this day (midnight sun)
+	AlwaysDown       bool // The sun never rises on this day (polar night)
+}
+
+// GetSunTimes gets the sun events for the given latitude and longitude on the calendar
+// day of the given date (in the date's location).  Times are returned in the date's location
+func GetSunTimes(date time.Time, lat, long float64) SunTimes {
+	retval := SunTimes{}
+	location := date.Location()
+	base := localDay(date)
+
+	//	Solar noon
+	retval.SolarNoon = base.Add(minutesToDuration(solarNoonMinutes(base, long))).In(location)
+
+	//	Sunrise and sunset
+	sunrise, sunriseStatus := sunEventMinutes(base, lat, long, zenithSunriseSunset, true)
+	sunset, _ := sunEventMinutes(base, lat, long, zenithSunriseSunset, false)
+	switch sunriseStatus {
+	case sunEventAlwaysAbove:
+		retval.AlwaysUp = true
+		retval.DaylightDuration = 24 * time.Hour
+	case sunEventAlwaysBelow:
+		retval.AlwaysDown = true
+	default:
+		retval.Sunrise = base.Add(minutesToDuration(sunrise)).In(location)
+		retval.Sunset = base.Add(minutesToDuration(sunset)).In(location)
+		retval.DaylightDuration = retval.Sunset.Sub(retval.Sunrise)
+	}
+
+	//	Twilight
+	retval.CivilDawn, retval.CivilDusk = twilightTimes(base, lat, long, zenithCivil, location)
+	retval.NauticalDawn, retval.NauticalDusk = twilightTimes(base, lat, long, zenithNautical, location)
+
+	return retval
+}
+
+const (
+	sunEventOccurs = iota
+	sunEventAlwaysAbove
+	sunEventAlwaysBelow
+)
+
+// twilightTimes gets the dawn and dusk times for the given zenith angle (or zero times if they don't happen)
+func twilightTimes(base time.Time, lat, long, zenith float64, location *time.Location) (time.Time, time.Time) {
+	dawn, status := sunEventMinutes(base, lat, long, zenith, true)
+	if status != sunEventOccurs {
+		return time.Time{}, time.Time{}
+	}
+
+	dusk, _ := sunEventMinutes(base, lat, long, zenith, false)
+
+	return base.Add(minutesToDuration(dawn)).In(location), base.Add(minutesToDuration(dusk)).In(location)
+}
+
+// solarNoonMinutes gets the solar noon (in minutes after the base UTC midnight)
+func solarNoonMinutes(base time.Time, long float64) float64 {
+	//	Start with an approximation and refine it using the equation of time at that moment
+	minutes := 720 - 4*long
+	for i := 0; i < 2; i++ {
+		_, eqTime := sunDeclinationAndEquationOfTime(base.Add(minutesToDuration(minutes)))
+		minutes = 720 - 4*long - eqTime
+	}
+
+	return minutes
+}
+
+// sunEventMinutes gets the time (in minutes after the base UTC midnight) that the center of the sun
+// crosses the given zenith angle -- either rising (in the morning) or setting (in the evening)
+func sunEventMinutes(base time.Time, lat, long, zenith float64, rising bool) (float64, int) {
+
+	//	Start at solar noon and iterate: each pass uses the sun's position at the previous estimate
+	minutes := solarNoonMinutes(base, long)
+	for i := 0; i < 3; i++ {
+		declination, eqTime := sunDeclinationAndEquationOfTime(base.Add(minutesToDuration(minutes)))
+
+		cosHourAngle := (cosDeg(zenith) - sinDeg(lat)*sinDeg(declination)) / (cosDeg(lat) * cosDeg(declination))
+		if cosHourAngle > 1 {
+			return 0, sunEventAlwaysBelow
+		}
+		if cosHourAngle < -1 {
+			return 0, sunEventAlwaysAbove
+		}
+
+		hourAngle := math.Acos(cosHourAngle) * degrees
+		if !rising {
+			hourAngle = -hourAngle
+		}
+
+		minutes = 720 - 4*(long+hourAngle) - eqTime
+	}
+
+	return minutes, sunEventOccurs
+}
+
+// sunDeclinationAndEquationOfTime gets the sun's declination (in degrees) and the
+// equation of time (in minutes) for the given time
+func sunDeclinationAndEquationOfTime(t time.Time) (float64, float64) {
+	T := julianCentury(t)
+	sun := getSunCoordinates(T)
+	obliquity := obliquityCorrection(T)
+
+	declination := math.Asin(sinDeg(obliquity)*sinDeg(sun.apparentLongitude)) * degrees
+
+	y := tanDeg(obliquity/2) * tanDeg(obliquity/2)
+	e := sun.eccentricity
+	eqTime := y*sinDeg(2*sun.meanLongitude) -
+		2*e*sinDeg(sun.meanAnomaly) +
+		4*e*y*sinDeg(sun.meanAnomaly)*cosDeg(2*sun.meanLongitude) -
+		0.5*y*y*sinDeg(4*sun.meanLongitude) -
+		1.25*e*e*sinDeg(2*sun.meanAnomaly)
+
+	return declination, 4 * eqTime * degrees
+}
+
+// sunEclipticLongitude gets the sun's apparent ecliptic longitude (in degrees) for the given time
+func sunEclipticLongitude(t time.Time) float64 {
+	return normalizeDegrees(getSunCoordinates(julianCentury(t)).apparentLongitude)
+}
+
+// sunCoordinates are the intermediate values used to locate the sun (all angles in degrees)
+type sunCoordinates struct {
+	meanLongitude     float64
+	meanAnomaly       float64
+	eccentricity      float64 // Eccentricity of earth's orbit
+	apparentLongitude float64 // Corrected for nutation and aberration
+}
+
+// getSunCoordinates gets the sun's coordinates for the given julian century
+func getSunCoordinates(T float64) sunCoordinates {
+	retval := sunCoordinates{
+		meanLongitude: normalizeDegrees(280.46646 + T*(36000.76983+T*0.0003032)),
+		meanAnomaly:   357.52911 + T*(35999.05029-0.0001537*T),
+		eccentricity:  0.016708634 - T*(0.000042037+0.0000001267*T),
+	}
+
+	equationOfCenter := sinDeg(retval.meanAnomaly)*(1.914602-T*(0.004817+0.000014*T)) +
+		sinDeg(2*retval.meanAnomaly)*(0.019993-0.000101*T) +
+		sinDeg(3*retval.meanAnomaly)*0.000289
+
+	omega := 125.04 - 1934.136*T
+	retval.apparentLongitude = retval.meanLongitude + equationOfCenter - 0.00569 - 0.00478*sinDeg(omega)
+
+	return retval
+}
+
+// obliquityCorrection gets the corrected obliquity of the ecliptic (in degrees)
+func obliquityCorrection(T float64) float64 {
+	seconds := 21.448 - T*(46.8150+T*(0.00059-T*0.001813))
+	meanObliquity := 23 + (26+seconds/60)/60
+	omega := 125.04 - 1934.136*T
+	return meanObliquity + 0.00256*cosDeg(omega)
+}
+
+// minutesToDuration converts fractional minutes to a duration (rounded to the second)
+func minutesToDuration(minutes float64) time.Duration {
+	return time.Duration(math.Round(minutes*60)) * time.Second
+}
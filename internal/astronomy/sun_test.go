@@ -0,0 +1,111 @@
+package astronomy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/astronomy"
+)
+
+// Expected times are from the published USNO / timeanddate.com tables for each location
+func TestGetSunTimes_MatchesPublishedTables(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	london, _ := time.LoadLocation("Europe/London")
+
+	tests := []struct {
+		name      string
+		date      time.Time
+		lat       float64
+		long      float64
+		sunrise   string
+		sunset    string
+		solarNoon string
+		civilDawn string
+		civilDusk string
+	}{
+		{"Washington DC summer solstice", time.Date(2022, 6, 21, 0, 0, 0, 0, newYork), 38.8895, -77.0353, "05:43", "20:37", "13:10", "05:10", "21:09"},
+		{"London winter solstice", time.Date(2022, 12, 21, 0, 0, 0, 0, london), 51.5074, -0.1278, "08:04", "15:53", "11:58", "07:24", "16:33"},
+		{"London summer solstice", time.Date(2022, 6, 21, 0, 0, 0, 0, london), 51.5074, -0.1278, "04:43", "21:21", "13:02", "03:55", "22:09"},
+	}
+
+	for _, test := range tests {
+		//	Act
+		sunTimes := astronomy.GetSunTimes(test.date, test.lat, test.long)
+
+		//	Assert
+		checks := []struct {
+			event    string
+			got      time.Time
+			expected string
+		}{
+			{"sunrise", sunTimes.Sunrise, test.sunrise},
+			{"sunset", sunTimes.Sunset, test.sunset},
+			{"solar noon", sunTimes.SolarNoon, test.solarNoon},
+			{"civil dawn", sunTimes.CivilDawn, test.civilDawn},
+			{"civil dusk", sunTimes.CivilDusk, test.civilDusk},
+		}
+
+		for _, check := range checks {
+			expected, _ := time.ParseInLocation("2006-01-02 15:04", test.date.Format("2006-01-02 ")+check.expected, test.date.Location())
+			if diff := check.got.Sub(expected); diff < -2*time.Minute || diff > 2*time.Minute {
+				t.Errorf("%v %v: expected %v but got %v instead", test.name, check.event, expected, check.got)
+			}
+		}
+	}
+}
+
+func TestGetSunTimes_PolarNight_SunNeverRises(t *testing.T) {
+	oslo, _ := time.LoadLocation("Europe/Oslo")
+
+	//	Tromsø has polar night around the winter solstice
+	sunTimes := astronomy.GetSunTimes(time.Date(2022, 12, 21, 0, 0, 0, 0, oslo), 69.6492, 18.9553)
+
+	if !sunTimes.AlwaysDown {
+		t.Errorf("Expected polar night but the sun rises at %v", sunTimes.Sunrise)
+	}
+
+	if !sunTimes.Sunrise.IsZero() || !sunTimes.Sunset.IsZero() {
+		t.Errorf("Expected no sunrise or sunset but got %v / %v", sunTimes.Sunrise, sunTimes.Sunset)
+	}
+
+	if sunTimes.CivilDawn.IsZero() {
+		t.Errorf("Expected civil twilight during polar night but didn't get any")
+	}
+}
+
+func TestGetSunTimes_MidnightSun_SunNeverSets(t *testing.T) {
+	oslo, _ := time.LoadLocation("Europe/Oslo")
+
+	sunTimes := astronomy.GetSunTimes(time.Date(2022, 6, 21, 0, 0, 0, 0, oslo), 69.6492, 18.9553)
+
+	if !sunTimes.AlwaysUp {
+		t.Errorf("Expected the midnight sun but the sun sets at %v", sunTimes.Sunset)
+	}
+
+	if sunTimes.DaylightDuration != 24*time.Hour {
+		t.Errorf("Expected 24 hours of daylight but got %v instead", sunTimes.DaylightDuration)
+	}
+}
+
+func TestGetSunTimes_FarFutureDate_IsStillCalculated(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	//	The sun rises and sets at about the same times on the solstice 1000 years from now
+	//	(times past 2262 don't fit in nanoseconds since 1970)
+	sunTimes := astronomy.GetSunTimes(time.Date(3000, 6, 21, 0, 0, 0, 0, newYork), 38.8895, -77.0353)
+
+	checks := []struct {
+		event    string
+		got      time.Time
+		expected time.Time
+	}{
+		{"sunrise", sunTimes.Sunrise, time.Date(3000, 6, 21, 5, 43, 0, 0, newYork)},
+		{"sunset", sunTimes.Sunset, time.Date(3000, 6, 21, 20, 37, 0, 0, newYork)},
+	}
+
+	for _, check := range checks {
+		if diff := check.got.Sub(check.expected); diff < -15*time.Minute || diff > 15*time.Minute {
+			t.Errorf("Expected %v around %v but got %v instead", check.event, check.expected, check.got)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Package cache provides an in-memory cache for upstream data.  Each item has its own
+// time-to-live, and concurrent requests for the same missing item are collapsed into a
+// single call to the upstream service.  The cache can be limited to a number of items,
+// in which case the least recently used items are dropped first.
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// sweepInterval is how many stores happen between sweeps for expired items
+const sweepInterval = 100
+
+// Cache is an in-memory cache of values with a time-to-live
+type Cache struct {
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	order      *list.List // Most recently used first
+	maxEntries int        // 0 means there's no limit
+	stores     int
+	group      singleflight.Group
+	now        func() time.Time
+}
+
+// Result describes where a cached value came from
+type Result struct {
+	Hit bool          // The value was already in the cache
+	Age time.Duration // How long ago the value was fetched
+}
+
+// item is a single value in the cache
+type item struct {
+	key     string
+	value   interface{}
+	stored  time.Time
+	expires time.Time // Zero time means the item never expires
+}
+
+// New creates an empty cache (with no limit on the number of items)
+func New() *Cache {
+	return &Cache{
+		items: make(map[string]*list.Element),
+		order: list.New(),
+		now:   time.Now,
+	}
+}
+
+// SetMaxEntries limits the number of items in the cache.  When there are too many, expired
+// items are cleaned out and then the least recently used items are dropped.  0 means there's no limit
+func (c *Cache) SetMaxEntries(maxEntries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxEntries = maxEntries
+	c.trim(c.now())
+}
+
+// Get gets the value for the given key (if it's in the cache and hasn't expired)
+func (c *Cache) Get(key string) (interface{}, Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	element, ok := c.items[key]
+	if !ok {
+		return nil, Result{}, false
+	}
+
+	found := element.Value.(*item)
+	if found.expired(now) {
+		c.remove(element)
+		return nil, Result{}, false
+	}
+
+	c.order.MoveToFront(element)
+	return found.value, Result{Hit: true, Age: now.Sub(found.stored)}, true
+}
+
+// Set stores the value for the given key.  A ttl of 0 keeps the value forever (unless it's
+// pushed out by the item limit), and a negative ttl means the value isn't cached at all
+func (c *Cache) Set(key string, value interface{}, ttl time.Duration) {
+	if ttl < 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	newItem := &item{key: key, value: value, stored: now}
+	if ttl > 0 {
+		newItem.expires = now.Add(ttl)
+	}
+
+	if element, ok := c.items[key]; ok {
+		element.Value = newItem
+		c.order.MoveToFront(element)
+	} else {
+		c.items[key] = c.order.PushFront(newItem)
+	}
+
+	//	Every so often, clean out anything that has expired
+	c.stores++
+	if c.stores%sweepInterval == 0 {
+		c.sweep(now)
+	}
+
+	c.trim(now)
+}
+
+// Delete removes the value for the given key
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.remove(element)
+	}
+}
+
+// DeletePrefix removes the values for every key that starts with the given prefix (like 'nwspoints:')
+// and returns how many were removed
+func (c *Cache) DeletePrefix(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, element := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(element)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// Len gets the number of items in the cache (including any that have expired but haven't been cleaned out yet)
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
+
+// GetOrLoad gets the value for the given key.  If it's not in the cache, load is called to get it
+// and the value is stored for the given ttl.  If several callers ask for the same missing key at
+// the same time, load is only called once and they all share the result.  Errors aren't cached
+func (c *Cache) GetOrLoad(key string, ttl time.Duration, load func() (interface{}, error)) (interface{}, Result, error) {
+
+	//	If we already have it, we're done
+	if value, result, ok := c.Get(key); ok {
+		return value, result, nil
+	}
+
+	value, err, _ := c.group.Do(key, func() (interface{}, error) {
+
+		//	Someone else might have loaded it while we were waiting
+		if value, _, ok := c.Get(key); ok {
+			return value, nil
+		}
+
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+
+		c.Set(key, value, ttl)
+		return value, nil
+	})
+
+	return value, Result{}, err
+}
+
+// expired returns true if the item has expired as of the given time
+func (i *item) expired(now time.Time) bool {
+	return !i.expires.IsZero() && !now.Before(i.expires)
+}
+
+// trim gets the cache back under the item limit.  Expired items go first, then the least
+// recently used.  The caller must hold the lock
+func (c *Cache) trim(now time.Time) {
+	if c.maxEntries <= 0 || len(c.items) <= c.maxEntries {
+		return
+	}
+
+	c.sweep(now)
+	for len(c.items) > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// sweep removes expired items.  The caller must hold the lock
+func (c *Cache) sweep(now time.Time) {
+	for _, element := range c.items {
+		if element.Value.(*item).expired(now) {
+			c.remove(element)
+		}
+	}
+}
+
+// remove removes an element from the cache.  The caller must hold the lock
+func (c *Cache) remove(element *list.Element) {
+	found := c.order.Remove(element).(*item)
+	delete(c.items, found.key)
+}
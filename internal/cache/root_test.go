@@ -0,0 +1,202 @@
+package cache_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/cache"
+)
+
+func TestGetOrLoad_SecondCall_IsHit(t *testing.T) {
+	//	Arrange
+	c := cache.New()
+	calls := 0
+	load := func() (interface{}, error) {
+		calls++
+		return "value", nil
+	}
+
+	//	Act
+	_, first, err := c.GetOrLoad("key", time.Minute, load)
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+	value, second, err := c.GetOrLoad("key", time.Minute, load)
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+
+	//	Assert
+	if first.Hit {
+		t.Errorf("Expected the first call to be a miss")
+	}
+
+	if !second.Hit {
+		t.Errorf("Expected the second call to be a hit")
+	}
+
+	if value != "value" || calls != 1 {
+		t.Errorf("Expected one load returning 'value' but got %v loads returning %v instead", calls, value)
+	}
+}
+
+func TestGet_Expired_IsMiss(t *testing.T) {
+	//	Arrange
+	c := cache.New()
+	c.Set("key", "value", 10*time.Millisecond)
+
+	//	Act
+	time.Sleep(20 * time.Millisecond)
+	_, _, found := c.Get("key")
+
+	//	Assert
+	if found {
+		t.Errorf("Expected the item to have expired")
+	}
+}
+
+func TestSet_ZeroTTL_NeverExpires(t *testing.T) {
+	//	Arrange
+	c := cache.New()
+	c.Set("key", "value", 0)
+
+	//	Act
+	time.Sleep(10 * time.Millisecond)
+	_, result, found := c.Get("key")
+
+	//	Assert
+	if !found {
+		t.Errorf("Expected the item to still be cached")
+	}
+
+	if result.Age <= 0 {
+		t.Errorf("Expected a positive age but got %v instead", result.Age)
+	}
+}
+
+func TestSet_NegativeTTL_IsNotCached(t *testing.T) {
+	//	Arrange
+	c := cache.New()
+	c.Set("key", "value", -1)
+
+	//	Act
+	_, _, found := c.Get("key")
+
+	//	Assert
+	if found {
+		t.Errorf("Expected the item not to be cached")
+	}
+}
+
+func TestDeletePrefix_RemovesMatchingKeys(t *testing.T) {
+	//	Arrange
+	c := cache.New()
+	c.Set("nwspoints:33.98,-83.96", "points", 0)
+	c.Set("nwspoints:34.00,-84.00", "points", 0)
+	c.Set("weather:33.98,-83.96", "weather", 0)
+
+	//	Act
+	removed := c.DeletePrefix("nwspoints:")
+
+	//	Assert
+	if removed != 2 || c.Len() != 1 {
+		t.Errorf("Expected 2 items removed and 1 left but got %v removed and %v left instead", removed, c.Len())
+	}
+
+	if _, _, found := c.Get("weather:33.98,-83.96"); !found {
+		t.Errorf("Expected the weather item to still be cached")
+	}
+}
+
+func TestGetOrLoad_Error_IsNotCached(t *testing.T) {
+	//	Arrange
+	c := cache.New()
+
+	//	Act
+	_, _, err := c.GetOrLoad("key", time.Minute, func() (interface{}, error) {
+		return nil, fmt.Errorf("upstream is down")
+	})
+
+	//	Assert
+	if err == nil {
+		t.Errorf("Didn't return an error and we expected one")
+	}
+
+	if c.Len() != 0 {
+		t.Errorf("Expected an empty cache but got %v items instead", c.Len())
+	}
+}
+
+func TestGetOrLoad_ConcurrentCalls_LoadOnce(t *testing.T) {
+	//	Arrange
+	c := cache.New()
+	var calls int32
+	release := make(chan struct{})
+	load := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "value", nil
+	}
+
+	//	Act
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.GetOrLoad("key", time.Minute, load)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	//	Assert
+	if calls != 1 {
+		t.Errorf("Expected 1 load but got %v instead", calls)
+	}
+}
+
+func TestSetMaxEntries_DropsLeastRecentlyUsed(t *testing.T) {
+	//	Arrange
+	c := cache.New()
+	c.SetMaxEntries(2)
+	c.Set("a", "a", 0)
+	c.Set("b", "b", 0)
+
+	//	Act
+	c.Get("a")
+	c.Set("c", "c", 0)
+
+	//	Assert
+	if c.Len() != 2 {
+		t.Errorf("Expected 2 items but got %v instead", c.Len())
+	}
+
+	for key, expected := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, _, found := c.Get(key); found != expected {
+			t.Errorf("Expected '%v' to be cached: %v but got %v instead", key, expected, found)
+		}
+	}
+}
+
+func TestSetMaxEntries_DropsExpiredItemsFirst(t *testing.T) {
+	//	Arrange
+	c := cache.New()
+	c.SetMaxEntries(2)
+	c.Set("short", "short", 10*time.Millisecond)
+	c.Set("long", "long", 0)
+	c.Get("short")
+
+	//	Act
+	time.Sleep(20 * time.Millisecond)
+	c.Set("new", "new", 0)
+
+	//	Assert
+	if _, _, found := c.Get("long"); !found {
+		t.Errorf("Expected the expired item to be dropped instead of the least recently used one")
+	}
+}
@@ -0,0 +1,218 @@
+// Package caldav is a small CalDAV client.  It only knows how to ask a calendar collection for the
+// events in a time range (a calendar-query REPORT) and hand back the results as a single iCalendar
+// document, so they can be parsed the same way as any iCal feed.
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Authentication types
+const (
+	AuthNone   = ""       // No authentication
+	AuthBasic  = "basic"  // HTTP basic authentication (username and password)
+	AuthBearer = "bearer" // Bearer token authentication
+)
+
+// Auth describes how to authenticate with a CalDAV server
+type Auth struct {
+	Type     string `mapstructure:"auth"`     // basic, bearer (or blank for none)
+	Username string `mapstructure:"username"` // Username (for basic authentication)
+	Password string `mapstructure:"password"` // Password -- usually an app password (for basic authentication)
+	Token    string `mapstructure:"token"`    // Token (for bearer authentication)
+}
+
+// Apply adds the authentication to a request
+func (a Auth) Apply(req *http.Request) error {
+	switch strings.ToLower(a.Type) {
+	case AuthNone:
+	case AuthBasic:
+		req.SetBasicAuth(a.Username, a.Password)
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	default:
+		return fmt.Errorf("%w '%s' (should be basic or bearer)", ErrUnknownAuthType, a.Type)
+	}
+
+	return nil
+}
+
+// Client queries a single CalDAV calendar collection
+type Client struct {
+	URL         string       // The calendar collection url
+	Auth        Auth         // How to authenticate
+	HTTPClient  *http.Client // The client used to make requests
+	MaxBodySize int64        // The largest response we'll read (0 for no limit)
+}
+
+// StatusError is returned when the server responds with an HTTP error
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+// Error gets the error message
+func (e StatusError) Error() string {
+	return fmt.Sprintf("the CalDAV server returned %s", e.Status)
+}
+
+// ErrUnknownAuthType is returned (wrapped) when the authentication type isn't one we know about
+var ErrUnknownAuthType = errors.New("unknown CalDAV auth type")
+
+// ErrInvalidResponse is returned (wrapped) when the server's response can't be used
+var ErrInvalidResponse = errors.New("invalid CalDAV response")
+
+// multistatus is the (WebDAV) response to a calendar-query
+type multistatus struct {
+	XMLName   xml.Name `xml:"DAV: multistatus"`
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// calendarQuery is the REPORT body asking for the events in a time range
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <D:getetag/>
+    <C:calendar-data/>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:time-range start="%s" end="%s"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+// Events gets the events from start to end as a single iCalendar document
+func (c Client) Events(ctx context.Context, start, end time.Time) ([]byte, error) {
+
+	body := fmt.Sprintf(calendarQuery, start.UTC().Format("20060102T150405Z"), end.UTC().Format("20060102T150405Z"))
+	req, err := http.NewRequest("REPORT", c.URL, strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("problem creating the CalDAV request: %v", err)
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+	if err := c.Auth.Apply(req); err != nil {
+		return nil, err
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("problem sending the CalDAV request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	//	Read the response (but not too much of it)
+	var reader io.Reader = resp.Body
+	if c.MaxBodySize > 0 {
+		reader = io.LimitReader(resp.Body, c.MaxBodySize+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("problem reading the CalDAV response: %w", err)
+	}
+	if c.MaxBodySize > 0 && int64(len(data)) > c.MaxBodySize {
+		return nil, fmt.Errorf("%w: the response is larger than the %v byte limit", ErrInvalidResponse, c.MaxBodySize)
+	}
+
+	response := multistatus{}
+	if err := xml.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("%w: problem decoding the response: %v", ErrInvalidResponse, err)
+	}
+
+	calendars := []string{}
+	for _, r := range response.Responses {
+		for _, propstat := range r.Propstat {
+			if propstat.Prop.CalendarData != "" && (propstat.Status == "" || strings.Contains(propstat.Status, " 200 ")) {
+				calendars = append(calendars, propstat.Prop.CalendarData)
+			}
+		}
+	}
+
+	return MergeCalendars(calendars), nil
+}
+
+// MergeCalendars combines iCalendar documents into one.  The components (events, time zones, etc)
+// of each document are copied in order -- identical components (like a shared VTIMEZONE) are only
+// included once
+func MergeCalendars(calendars []string) []byte {
+	retval := bytes.Buffer{}
+	retval.WriteString("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//daydash-service//caldav//EN\r\n")
+
+	seen := map[string]bool{}
+	for _, calendar := range calendars {
+		for _, component := range calendarComponents(calendar) {
+			if seen[component] {
+				continue
+			}
+			seen[component] = true
+			retval.WriteString(component)
+		}
+	}
+
+	retval.WriteString("END:VCALENDAR\r\n")
+	return retval.Bytes()
+}
+
+// calendarComponents gets the top level components (like VEVENT or VTIMEZONE) in an iCalendar document
+func calendarComponents(calendar string) []string {
+	retval := []string{}
+
+	component := strings.Builder{}
+	depth := 0
+	for _, line := range strings.Split(strings.ReplaceAll(calendar, "\r\n", "\n"), "\n") {
+		upper := strings.ToUpper(strings.TrimSpace(line))
+
+		switch {
+		case upper == "BEGIN:VCALENDAR" || upper == "END:VCALENDAR":
+			continue
+		case strings.HasPrefix(upper, "BEGIN:"):
+			depth++
+		case depth == 0:
+			//	A property of the calendar itself (like VERSION or PRODID)
+			continue
+		}
+
+		component.WriteString(line)
+		component.WriteString("\r\n")
+
+		if strings.HasPrefix(upper, "END:") {
+			depth--
+			if depth == 0 {
+				retval = append(retval, component.String())
+				component.Reset()
+			}
+		}
+	}
+
+	return retval
+}
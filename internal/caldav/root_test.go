@@ -0,0 +1,136 @@
+package caldav_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/caldav"
+)
+
+const testMultistatus = `<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav">
+  <d:response>
+    <d:href>/calendars/pat/family/one.ics</d:href>
+    <d:propstat>
+      <d:prop>
+        <d:getetag>"1"</d:getetag>
+        <cal:calendar-data>BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Nextcloud//EN
+BEGIN:VTIMEZONE
+TZID:America/New_York
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:one
+SUMMARY:Dinner
+END:VEVENT
+END:VCALENDAR
+</cal:calendar-data>
+      </d:prop>
+      <d:status>HTTP/1.1 200 OK</d:status>
+    </d:propstat>
+  </d:response>
+  <d:response>
+    <d:href>/calendars/pat/family/two.ics</d:href>
+    <d:propstat>
+      <d:prop>
+        <cal:calendar-data>BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VTIMEZONE
+TZID:America/New_York
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:two
+SUMMARY:Soccer
+END:VEVENT
+END:VCALENDAR
+</cal:calendar-data>
+      </d:prop>
+      <d:status>HTTP/1.1 200 OK</d:status>
+    </d:propstat>
+  </d:response>
+</d:multistatus>`
+
+func TestClient_Events_MergesCalendarData(t *testing.T) {
+	//	Arrange
+	var method, depth, user, password, body string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		method = req.Method
+		depth = req.Header.Get("Depth")
+		user, password, _ = req.BasicAuth()
+		data, _ := io.ReadAll(req.Body)
+		body = string(data)
+
+		rw.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(rw, testMultistatus)
+	}))
+	defer server.Close()
+
+	client := caldav.Client{
+		URL:  server.URL,
+		Auth: caldav.Auth{Type: caldav.AuthBasic, Username: "pat", Password: "secret"},
+	}
+
+	//	Act
+	data, err := client.Events(context.Background(), time.Date(2022, 6, 1, 4, 0, 0, 0, time.UTC), time.Date(2022, 6, 2, 4, 0, 0, 0, time.UTC))
+
+	//	Assert
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	if method != "REPORT" || depth != "1" || user != "pat" || password != "secret" {
+		t.Errorf("Expected an authenticated REPORT with depth 1 but got %v (depth %v) as %v/%v instead", method, depth, user, password)
+	}
+
+	if !strings.Contains(body, `<C:time-range start="20220601T040000Z" end="20220602T040000Z"/>`) {
+		t.Errorf("Expected the time range in the query but got %v instead", body)
+	}
+
+	merged := string(data)
+	if strings.Count(merged, "BEGIN:VCALENDAR") != 1 || strings.Count(merged, "BEGIN:VTIMEZONE") != 1 || strings.Count(merged, "BEGIN:VEVENT") != 2 {
+		t.Errorf("Expected a single calendar with one time zone and two events but got %v instead", merged)
+	}
+
+	if strings.Contains(merged, "Nextcloud") {
+		t.Errorf("Expected the original calendar properties to be left out but got %v instead", merged)
+	}
+}
+
+func TestClient_Events_BearerAuthAndErrors(t *testing.T) {
+	//	Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(rw, "not xml")
+	}))
+	defer server.Close()
+
+	//	Act
+	_, authErr := caldav.Client{URL: server.URL, Auth: caldav.Auth{Type: caldav.AuthBearer, Token: "wrong"}}.Events(context.Background(), time.Now(), time.Now())
+	_, dataErr := caldav.Client{URL: server.URL, Auth: caldav.Auth{Type: caldav.AuthBearer, Token: "token"}}.Events(context.Background(), time.Now(), time.Now())
+	_, typeErr := caldav.Client{URL: server.URL, Auth: caldav.Auth{Type: "digest"}}.Events(context.Background(), time.Now(), time.Now())
+
+	//	Assert
+	statusErr := caldav.StatusError{}
+	if !errors.As(authErr, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 status error but got %v instead", authErr)
+	}
+
+	if !errors.Is(dataErr, caldav.ErrInvalidResponse) {
+		t.Errorf("Expected an invalid response error but got %v instead", dataErr)
+	}
+
+	if !errors.Is(typeErr, caldav.ErrUnknownAuthType) {
+		t.Errorf("Expected an unknown auth type error but got %v instead", typeErr)
+	}
+}
@@ -0,0 +1,297 @@
+// Package holidays calculates public holidays (and the days they're observed) for a country or
+// region without calling any outside services.  Holidays are described by rules: fixed dates,
+// nth weekdays of a month and days relative to Easter.
+package holidays
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Rule types
+const (
+	TypeFixed   = "fixed"   // The same date every year (month and day)
+	TypeWeekday = "weekday" // The nth weekday of a month (month, weekday and week)
+	TypeEaster  = "easter"  // A number of days from (Western) Easter Sunday (offset)
+)
+
+// Ways a holiday that falls on a weekend can be observed on a weekday instead
+const (
+	ObservedNone           = "none"             // It isn't moved
+	ObservedNearest        = "nearest"          // Saturday moves to Friday, Sunday moves to Monday
+	ObservedNextWeekday    = "next_weekday"     // Saturday and Sunday move to the next weekday that isn't already a holiday
+	ObservedSundayToMonday = "sunday_to_monday" // Only Sunday moves (to Monday)
+)
+
+// The years rules can be calculated for (the Easter calculation is only good for the Gregorian calendar)
+const (
+	MinYear = 1583
+	MaxYear = 4099
+)
+
+// Rule describes how to find the date of a holiday in a given year
+type Rule struct {
+	Name     string `json:"name" mapstructure:"name"`
+	Type     string `json:"type" mapstructure:"type"`         // fixed, weekday or easter
+	Month    int    `json:"month" mapstructure:"month"`       // 1 - 12 (fixed and weekday rules)
+	Day      int    `json:"day" mapstructure:"day"`           // 1 - 31 (fixed rules)
+	Weekday  string `json:"weekday" mapstructure:"weekday"`   // Like 'monday' (weekday rules)
+	Week     int    `json:"week" mapstructure:"week"`         // 1 - 5 for the nth weekday of the month, or -1 for the last one (weekday rules)
+	Offset   int    `json:"offset" mapstructure:"offset"`     // Days after Easter Sunday -- negative for days before (easter rules)
+	Observed string `json:"observed" mapstructure:"observed"` // How the holiday moves when it's on a weekend (defaults to none)
+	From     int    `json:"from" mapstructure:"from"`         // Optional first year the holiday is observed
+	To       int    `json:"to" mapstructure:"to"`             // Optional last year the holiday is observed
+}
+
+// Calendar is the set of holidays for a country (or a region within a country)
+type Calendar struct {
+	Country string `json:"country" mapstructure:"country"` // Country code (like US)
+	Region  string `json:"region" mapstructure:"region"`   // Optional region code (like GA).  Regional holidays are in addition to the country's
+	Name    string `json:"name" mapstructure:"name"`       // Display name (like 'US federal holidays')
+	Rules   []Rule `json:"rules" mapstructure:"rules"`
+}
+
+// Holiday is a holiday in a given year
+type Holiday struct {
+	Name     string    `json:"name"`
+	Country  string    `json:"country"`
+	Region   string    `json:"region,omitempty"`
+	Date     time.Time `json:"date"`     // The date of the holiday (midnight UTC)
+	Observed time.Time `json:"observed"` // The date it's observed (the same as the date unless it falls on a weekend)
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// Validate returns an error if the rule can't be used
+func (r Rule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("holiday rules need a name")
+	}
+
+	switch strings.ToLower(r.Type) {
+	case TypeFixed:
+		if r.Month < 1 || r.Month > 12 || r.Day < 1 || r.Day > daysIn(time.Month(r.Month), 2000) {
+			return fmt.Errorf("'%s' needs a valid month and day", r.Name)
+		}
+	case TypeWeekday:
+		if _, found := weekdays[strings.ToLower(r.Weekday)]; !found || r.Month < 1 || r.Month > 12 || r.Week < -1 || r.Week == 0 || r.Week > 5 {
+			return fmt.Errorf("'%s' needs a valid month, weekday and week (1 - 5, or -1 for the last)", r.Name)
+		}
+	case TypeEaster:
+	default:
+		return fmt.Errorf("'%s' has an unknown type '%s' (it should be fixed, weekday or easter)", r.Name, r.Type)
+	}
+
+	switch strings.ToLower(r.Observed) {
+	case "", ObservedNone, ObservedNearest, ObservedNextWeekday, ObservedSundayToMonday:
+	default:
+		return fmt.Errorf("'%s' has an unknown observed setting '%s'", r.Name, r.Observed)
+	}
+
+	return nil
+}
+
+// Date gets the date of the holiday in the given year (midnight UTC).  It returns false if the
+// holiday isn't observed that year (or doesn't happen in it, like the 5th Monday of a short month)
+func (r Rule) Date(year int) (time.Time, bool) {
+	if (r.From != 0 && year < r.From) || (r.To != 0 && year > r.To) {
+		return time.Time{}, false
+	}
+
+	switch strings.ToLower(r.Type) {
+	case TypeFixed:
+		if r.Day > daysIn(time.Month(r.Month), year) {
+			return time.Time{}, false
+		}
+		return time.Date(year, time.Month(r.Month), r.Day, 0, 0, 0, 0, time.UTC), true
+
+	case TypeWeekday:
+		return nthWeekday(year, time.Month(r.Month), weekdays[strings.ToLower(r.Weekday)], r.Week)
+
+	case TypeEaster:
+		return Easter(year).AddDate(0, 0, r.Offset), true
+	}
+
+	return time.Time{}, false
+}
+
+// Holidays gets the holidays in the calendar for the given year (sorted by date)
+func (c Calendar) Holidays(year int) []Holiday {
+	retval := []Holiday{}
+
+	//	Keep track of the dates that are already days off, so substitute days don't land on them
+	taken := map[time.Time]bool{}
+	for _, rule := range c.Rules {
+		if date, ok := rule.Date(year); ok {
+			taken[date] = true
+		}
+	}
+
+	for _, rule := range c.Rules {
+		date, ok := rule.Date(year)
+		if !ok {
+			continue
+		}
+
+		observed := observedDate(date, rule.Observed, taken)
+		taken[observed] = true
+
+		retval = append(retval, Holiday{
+			Name:     rule.Name,
+			Country:  c.Country,
+			Region:   c.Region,
+			Date:     date,
+			Observed: observed,
+		})
+	}
+
+	sortHolidays(retval)
+	return retval
+}
+
+// Find gets the calendars for a country (and region, if there is one).  Regional calendars are in
+// addition to the national ones, so both are included
+func Find(calendars []Calendar, country, region string) []Calendar {
+	retval := []Calendar{}
+	for _, calendar := range calendars {
+		if !strings.EqualFold(calendar.Country, country) {
+			continue
+		}
+
+		if calendar.Region == "" || strings.EqualFold(calendar.Region, region) {
+			retval = append(retval, calendar)
+		}
+	}
+	return retval
+}
+
+// ForYear gets the holidays from all of the calendars for the given year (sorted by date)
+func ForYear(calendars []Calendar, year int) []Holiday {
+	retval := []Holiday{}
+	for _, calendar := range calendars {
+		retval = append(retval, calendar.Holidays(year)...)
+	}
+
+	sortHolidays(retval)
+	return retval
+}
+
+// Between gets the holidays from all of the calendars that are on (or observed on) a date from the
+// start date through the end date (sorted by date)
+func Between(calendars []Calendar, start, end time.Time) []Holiday {
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+
+	retval := []Holiday{}
+
+	//	Holidays can be observed in the year before (or after) they happen
+	for year := first.Year() - 1; year <= last.Year()+1; year++ {
+		for _, holiday := range ForYear(calendars, year) {
+			if inRange(holiday.Date, first, last) || inRange(holiday.Observed, first, last) {
+				retval = append(retval, holiday)
+			}
+		}
+	}
+
+	return retval
+}
+
+// Easter gets the date of (Western) Easter Sunday in the given year (midnight UTC), using the
+// anonymous Gregorian algorithm
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// nthWeekday gets the nth weekday of a month (or the last one if n is -1)
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) (time.Time, bool) {
+	if n == -1 {
+		last := time.Date(year, month, daysIn(month, year), 0, 0, 0, 0, time.UTC)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(weekday) + 7) % 7)), true
+	}
+
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	date := first.AddDate(0, 0, (int(weekday)-int(first.Weekday())+7)%7+7*(n-1))
+	if date.Month() != month {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// observedDate gets the date a holiday is observed on
+func observedDate(date time.Time, observed string, taken map[time.Time]bool) time.Time {
+	switch strings.ToLower(observed) {
+	case ObservedNearest:
+		switch date.Weekday() {
+		case time.Saturday:
+			return date.AddDate(0, 0, -1)
+		case time.Sunday:
+			return date.AddDate(0, 0, 1)
+		}
+
+	case ObservedSundayToMonday:
+		if date.Weekday() == time.Sunday {
+			return date.AddDate(0, 0, 1)
+		}
+
+	case ObservedNextWeekday:
+		if !isWeekend(date) {
+			return date
+		}
+
+		next := date.AddDate(0, 0, 1)
+		for isWeekend(next) || taken[next] {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	}
+
+	return date
+}
+
+func isWeekend(date time.Time) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
+
+// daysIn gets the number of days in a month
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func inRange(date, first, last time.Time) bool {
+	return !date.Before(first) && !date.After(last)
+}
+
+// sortHolidays puts holidays in order by the date they're observed
+func sortHolidays(holidays []Holiday) {
+	sort.SliceStable(holidays, func(i, j int) bool {
+		if !holidays[i].Observed.Equal(holidays[j].Observed) {
+			return holidays[i].Observed.Before(holidays[j].Observed)
+		}
+		return holidays[i].Date.Before(holidays[j].Date)
+	})
+}
@@ -0,0 +1,204 @@
+package holidays_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/holidays"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestUSFederal_Holidays_MatchPublishedDates(t *testing.T) {
+	//	Arrange
+	//	Dates from the OPM federal holiday schedule for 2022
+	expected := map[string][2]time.Time{
+		"New Year's Day":                       {date(2022, 1, 1), date(2021, 12, 31)},
+		"Martin Luther King Jr. Day":           {date(2022, 1, 17), date(2022, 1, 17)},
+		"Washington's Birthday":                {date(2022, 2, 21), date(2022, 2, 21)},
+		"Memorial Day":                         {date(2022, 5, 30), date(2022, 5, 30)},
+		"Juneteenth National Independence Day": {date(2022, 6, 19), date(2022, 6, 20)},
+		"Independence Day":                     {date(2022, 7, 4), date(2022, 7, 4)},
+		"Labor Day":                            {date(2022, 9, 5), date(2022, 9, 5)},
+		"Columbus Day":                         {date(2022, 10, 10), date(2022, 10, 10)},
+		"Veterans Day":                         {date(2022, 11, 11), date(2022, 11, 11)},
+		"Thanksgiving Day":                     {date(2022, 11, 24), date(2022, 11, 24)},
+		"Christmas Day":                        {date(2022, 12, 25), date(2022, 12, 26)},
+	}
+
+	//	Act
+	days := holidays.USFederal.Holidays(2022)
+
+	//	Assert
+	if len(days) != len(expected) {
+		t.Fatalf("Expected %v holidays but got %v instead", len(expected), len(days))
+	}
+
+	for _, day := range days {
+		dates, found := expected[day.Name]
+		if !found {
+			t.Errorf("Didn't expect a holiday named %v", day.Name)
+			continue
+		}
+
+		if !day.Date.Equal(dates[0]) || !day.Observed.Equal(dates[1]) {
+			t.Errorf("Expected %v on %v (observed %v) but got %v (observed %v) instead", day.Name, dates[0], dates[1], day.Date, day.Observed)
+		}
+	}
+
+	if days[0].Name != "New Year's Day" {
+		t.Errorf("Expected the holidays to be sorted by observed date but got %v first", days[0].Name)
+	}
+}
+
+func TestUSFederal_Holidays_RespectsFirstYear(t *testing.T) {
+	//	Act
+	days := holidays.USFederal.Holidays(2020)
+
+	//	Assert
+	for _, day := range days {
+		if day.Name == "Juneteenth National Independence Day" {
+			t.Errorf("Expected no Juneteenth holiday before 2021")
+		}
+	}
+}
+
+func TestEaster_KnownDates(t *testing.T) {
+	//	Arrange
+	tests := []struct {
+		year     int
+		expected time.Time
+	}{
+		{1961, date(1961, 4, 2)},
+		{2000, date(2000, 4, 23)},
+		{2019, date(2019, 4, 21)},
+		{2022, date(2022, 4, 17)},
+		{2024, date(2024, 3, 31)},
+		{2038, date(2038, 4, 25)},
+	}
+
+	for _, test := range tests {
+		//	Act
+		easter := holidays.Easter(test.year)
+
+		//	Assert
+		if !easter.Equal(test.expected) {
+			t.Errorf("Expected Easter %v on %v but got %v instead", test.year, test.expected, easter)
+		}
+	}
+}
+
+func TestCalendar_Holidays_NextWeekdaySkipsOtherHolidays(t *testing.T) {
+	//	Arrange
+	//	Christmas 2021 is a Saturday and Boxing Day is a Sunday, so they're observed Monday and Tuesday
+	calendar := holidays.Calendar{
+		Country: "GB",
+		Rules: []holidays.Rule{
+			{Name: "Christmas Day", Type: holidays.TypeFixed, Month: 12, Day: 25, Observed: holidays.ObservedNextWeekday},
+			{Name: "Boxing Day", Type: holidays.TypeFixed, Month: 12, Day: 26, Observed: holidays.ObservedNextWeekday},
+			{Name: "Good Friday", Type: holidays.TypeEaster, Offset: -2},
+			{Name: "Early May bank holiday", Type: holidays.TypeWeekday, Month: 5, Weekday: "monday", Week: 1},
+		},
+	}
+
+	//	Act
+	days := calendar.Holidays(2021)
+
+	//	Assert
+	expected := []time.Time{date(2021, 4, 2), date(2021, 5, 3), date(2021, 12, 27), date(2021, 12, 28)}
+	if len(days) != len(expected) {
+		t.Fatalf("Expected %v holidays but got %+v instead", len(expected), days)
+	}
+
+	for i, day := range days {
+		if !day.Observed.Equal(expected[i]) {
+			t.Errorf("Expected %v to be observed on %v but got %v instead", day.Name, expected[i], day.Observed)
+		}
+	}
+}
+
+func TestRule_Date_FifthWeekdayMayNotHappen(t *testing.T) {
+	//	Arrange
+	rule := holidays.Rule{Name: "Fifth Monday", Type: holidays.TypeWeekday, Month: 2, Weekday: "monday", Week: 5}
+
+	//	Act
+	_, in2021 := rule.Date(2021)
+	leapDay, in2016 := rule.Date(2016)
+
+	//	Assert
+	if in2021 {
+		t.Errorf("Expected no fifth Monday in February 2021")
+	}
+
+	if !in2016 || !leapDay.Equal(date(2016, 2, 29)) {
+		t.Errorf("Expected the fifth Monday in February 2016 to be the 29th but got %v instead", leapDay)
+	}
+}
+
+func TestBetween_IncludesHolidaysObservedInRange(t *testing.T) {
+	//	Arrange
+	calendars := holidays.Find(holidays.Builtin(), "us", "GA")
+
+	//	Act
+	days := holidays.Between(calendars, date(2021, 12, 31), date(2021, 12, 31))
+
+	//	Assert
+	if len(days) != 1 || days[0].Name != "New Year's Day" || days[0].Date.Year() != 2022 {
+		t.Errorf("Expected New Year's Day 2022 (observed on Dec 31, 2021) but got %+v instead", days)
+	}
+}
+
+func TestLoadRules_ValidFile_LoadsCalendars(t *testing.T) {
+	//	Arrange
+	path := filepath.Join(t.TempDir(), "holidays.yaml")
+	os.WriteFile(path, []byte(`calendars:
+  - country: US
+    region: GA
+    name: Georgia state holidays
+    rules:
+      - { name: State Holiday, type: weekday, month: 4, weekday: monday, week: 4 }
+  - country: GB
+    rules:
+      - { name: Easter Monday, type: easter, offset: 1 }
+`), 0644)
+
+	//	Act
+	calendars, err := holidays.LoadRules(path)
+
+	//	Assert
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+
+	georgia := holidays.Find(append(holidays.Builtin(), calendars...), "US", "GA")
+	if len(georgia) != 2 {
+		t.Fatalf("Expected the federal and Georgia calendars but got %v instead", len(georgia))
+	}
+
+	days := holidays.ForYear(holidays.Find(calendars, "GB", ""), 2022)
+	if len(days) != 1 || !days[0].Date.Equal(date(2022, 4, 18)) {
+		t.Errorf("Expected Easter Monday on April 18, 2022 but got %+v instead", days)
+	}
+}
+
+func TestLoadRules_InvalidRule_ReturnsError(t *testing.T) {
+	//	Arrange
+	path := filepath.Join(t.TempDir(), "holidays.yaml")
+	os.WriteFile(path, []byte(`calendars:
+  - country: US
+    rules:
+      - { name: Nope, type: weekday, month: 13, weekday: monday, week: 1 }
+`), 0644)
+
+	//	Act
+	_, err := holidays.LoadRules(path)
+
+	//	Assert
+	if err == nil {
+		t.Errorf("Expected an error for an invalid rule but didn't get one")
+	}
+}
@@ -0,0 +1,45 @@
+package holidays
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// LoadRules reads holiday calendars from a rules file (YAML, JSON or TOML -- anything viper can read).
+// The file has a list of calendars, like:
+//
+//	calendars:
+//	  - country: GB
+//	    region: ENG
+//	    name: England bank holidays
+//	    rules:
+//	      - { name: Good Friday, type: easter, offset: -2 }
+//	      - { name: Boxing Day, type: fixed, month: 12, day: 26, observed: next_weekday }
+func LoadRules(path string) ([]Calendar, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("problem reading the holiday rules file: %v", err)
+	}
+
+	retval := []Calendar{}
+	if err := v.UnmarshalKey("calendars", &retval); err != nil {
+		return nil, fmt.Errorf("problem decoding the holiday rules file: %v", err)
+	}
+
+	for _, calendar := range retval {
+		if calendar.Country == "" {
+			return nil, fmt.Errorf("every calendar in the holiday rules file needs a country")
+		}
+
+		for _, rule := range calendar.Rules {
+			if err := rule.Validate(); err != nil {
+				return nil, fmt.Errorf("problem with the %s holiday rules: %w", calendar.Country, err)
+			}
+		}
+	}
+
+	return retval, nil
+}
@@ -0,0 +1,26 @@
+package holidays
+
+// USFederal is the calendar of US federal holidays.  Holidays on a Saturday are observed on the
+// Friday before, and holidays on a Sunday are observed on the Monday after (5 U.S.C. 6103)
+var USFederal = Calendar{
+	Country: "US",
+	Name:    "US federal holidays",
+	Rules: []Rule{
+		{Name: "New Year's Day", Type: TypeFixed, Month: 1, Day: 1, Observed: ObservedNearest},
+		{Name: "Martin Luther King Jr. Day", Type: TypeWeekday, Month: 1, Weekday: "monday", Week: 3, From: 1986},
+		{Name: "Washington's Birthday", Type: TypeWeekday, Month: 2, Weekday: "monday", Week: 3},
+		{Name: "Memorial Day", Type: TypeWeekday, Month: 5, Weekday: "monday", Week: -1},
+		{Name: "Juneteenth National Independence Day", Type: TypeFixed, Month: 6, Day: 19, Observed: ObservedNearest, From: 2021},
+		{Name: "Independence Day", Type: TypeFixed, Month: 7, Day: 4, Observed: ObservedNearest},
+		{Name: "Labor Day", Type: TypeWeekday, Month: 9, Weekday: "monday", Week: 1},
+		{Name: "Columbus Day", Type: TypeWeekday, Month: 10, Weekday: "monday", Week: 2},
+		{Name: "Veterans Day", Type: TypeFixed, Month: 11, Day: 11, Observed: ObservedNearest},
+		{Name: "Thanksgiving Day", Type: TypeWeekday, Month: 11, Weekday: "thursday", Week: 4},
+		{Name: "Christmas Day", Type: TypeFixed, Month: 12, Day: 25, Observed: ObservedNearest},
+	},
+}
+
+// Builtin gets the calendars that are always available
+func Builtin() []Calendar {
+	return []Calendar{USFederal}
+}
@@ -0,0 +1,118 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryTransport retries idempotent requests that fail with a network error,
+// a 429 (too many requests) or a 5xx server error
+type retryTransport struct {
+	next   http.RoundTripper
+	config Config
+}
+
+// RoundTrip sends the request (retrying if we need to)
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	//	Set our User-Agent (unless the caller has its own)
+	if t.config.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.config.UserAgent)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+
+		//	If we got an answer we can use (or we can't try again) we're done
+		if attempt >= t.config.MaxRetries || !isIdempotent(req) || !shouldRetry(req.Context(), resp, err) {
+			return resp, err
+		}
+
+		delay := t.retryDelay(attempt, resp)
+
+		zlog.Infow(
+			"retrying upstream request",
+			"url", req.URL.Redacted(),
+			"attempt", attempt+1,
+			"delay", delay,
+			"status", statusOf(resp),
+			"error", err,
+		)
+
+		//	Throw away the response we're not going to use
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+
+		//	Wait before trying again (unless the caller gives up first)
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryDelay gets how long to wait before the given retry.  If the server told us how long
+// to wait (with Retry-After) we use that.  Otherwise the delay doubles each time, with jitter
+// so a group of clients don't all come back at once
+func (t *retryTransport) retryDelay(attempt int, resp *http.Response) time.Duration {
+
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return capDelay(time.Duration(seconds)*time.Second, t.config.MaxRetryDelay)
+		}
+	}
+
+	delay := capDelay(t.config.RetryDelay<<uint(attempt), t.config.MaxRetryDelay)
+	if delay <= 0 {
+		return 0
+	}
+
+	//	Use somewhere between half and all of the delay
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// capDelay makes sure a delay isn't longer than the max (if there is one)
+func capDelay(delay, max time.Duration) time.Duration {
+	if max > 0 && delay > max {
+		return max
+	}
+	return delay
+}
+
+// isIdempotent returns true if the request can safely be sent more than once
+func isIdempotent(req *http.Request) bool {
+	return req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == ""
+}
+
+// shouldRetry returns true if the request failed in a way that might work if we try again
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+
+	//	If the caller gave up, there's no point
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// statusOf gets the status code of a response (or 0 if there isn't one)
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
@@ -0,0 +1,169 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/httpclient"
+)
+
+// testConfig gets a client config with short delays
+func testConfig() httpclient.Config {
+	return httpclient.Config{
+		ConnectTimeout: time.Second,
+		ReadTimeout:    time.Second,
+		Timeout:        5 * time.Second,
+		MaxRetries:     2,
+		RetryDelay:     time.Millisecond,
+		MaxRetryDelay:  10 * time.Millisecond,
+		UserAgent:      httpclient.UserAgent(),
+	}
+}
+
+func TestClient_ServerError_RetriesUntilSuccess(t *testing.T) {
+	//	Arrange
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	//	Act
+	resp, err := httpclient.New(testConfig()).Get(server.URL)
+
+	//	Assert
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %v but got %v instead", http.StatusOK, resp.StatusCode)
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected 3 calls but got %v instead", calls)
+	}
+}
+
+func TestClient_ServerError_GivesUpAfterMaxRetries(t *testing.T) {
+	//	Arrange
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	//	Act
+	resp, err := httpclient.New(testConfig()).Get(server.URL)
+
+	//	Assert
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected status %v but got %v instead", http.StatusTooManyRequests, resp.StatusCode)
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected 3 calls but got %v instead", calls)
+	}
+}
+
+func TestClient_Post_DoesNotRetry(t *testing.T) {
+	//	Arrange
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	//	Act
+	resp, err := httpclient.New(testConfig()).Post(server.URL, "text/plain", strings.NewReader("hello"))
+
+	//	Assert
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if calls != 1 {
+		t.Errorf("Expected 1 call but got %v instead", calls)
+	}
+}
+
+func TestClient_UserAgent_OnlySetWhenMissing(t *testing.T) {
+	//	Arrange
+	agents := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		agents <- req.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+	client := httpclient.New(testConfig())
+
+	//	Act
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	resp.Body.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("User-Agent", "custom-agent")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	resp.Body.Close()
+
+	//	Assert
+	if got := <-agents; got != httpclient.UserAgent() {
+		t.Errorf("Expected User-Agent %v but got %v instead", httpclient.UserAgent(), got)
+	}
+
+	if got := <-agents; got != "custom-agent" {
+		t.Errorf("Expected User-Agent custom-agent but got %v instead", got)
+	}
+}
+
+func TestClient_ContextCancelled_StopsRetrying(t *testing.T) {
+	//	Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Retry-After", "5")
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := testConfig()
+	config.MaxRetryDelay = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	//	Act
+	start := time.Now()
+	_, err := httpclient.New(config).Do(req)
+
+	//	Assert
+	if err == nil {
+		t.Errorf("Didn't return an error and we expected one")
+	}
+
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("Took %v to give up -- expected the context to stop us", time.Since(start))
+	}
+}
@@ -0,0 +1,98 @@
+// Package httpclient provides the HTTP client used for all upstream calls.  The client has
+// connect and read timeouts, retries idempotent requests with jittered exponential backoff,
+// sends a polite User-Agent and reports each attempt to New Relic.
+package httpclient
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/logger"
+	"github.com/danesparza/daydash-service/version"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+var (
+	rootlogger *zap.Logger
+	zlog       *zap.SugaredLogger
+
+	defaultClient     *http.Client
+	defaultClientOnce sync.Once
+)
+
+// Config describes how a client should behave
+type Config struct {
+	ConnectTimeout time.Duration // How long to wait to connect (including the TLS handshake)
+	ReadTimeout    time.Duration // How long to wait for response headers once the request is sent
+	Timeout        time.Duration // The total time allowed for a request (including retries)
+	MaxRetries     int           // How many times to retry a failed request
+	RetryDelay     time.Duration // The delay before the first retry.  It doubles for each retry after that
+	MaxRetryDelay  time.Duration // The longest we'll wait between retries
+	UserAgent      string        // The User-Agent to send (if the request doesn't set its own)
+}
+
+func init() {
+	rootlogger, _ = logger.NewProd()
+	defer rootlogger.Sync() // flushes buffer, if any
+	zlog = rootlogger.Sugar()
+}
+
+// UserAgent gets the User-Agent we send to upstream services
+func UserAgent() string {
+	return fmt.Sprintf("daydash-service/%s (+https://github.com/danesparza/daydash-service)", version.String())
+}
+
+// DefaultConfig gets the client configuration from the app config
+func DefaultConfig() Config {
+	return Config{
+		ConnectTimeout: viper.GetDuration("http.connecttimeout"),
+		ReadTimeout:    viper.GetDuration("http.readtimeout"),
+		Timeout:        viper.GetDuration("http.timeout"),
+		MaxRetries:     viper.GetInt("http.retries"),
+		RetryDelay:     viper.GetDuration("http.retrydelay"),
+		MaxRetryDelay:  viper.GetDuration("http.maxretrydelay"),
+		UserAgent:      UserAgent(),
+	}
+}
+
+// Default gets the shared client (created from the app config the first time it's used)
+func Default() *http.Client {
+	defaultClientOnce.Do(func() {
+		defaultClient = New(DefaultConfig())
+	})
+
+	return defaultClient
+}
+
+// New creates a client with the given configuration
+func New(config Config) *http.Client {
+
+	dialer := &net.Dialer{
+		Timeout:   config.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   config.ConnectTimeout,
+		ResponseHeaderTimeout: config.ReadTimeout,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		ForceAttemptHTTP2:     true,
+	}
+
+	return &http.Client{
+		Timeout: config.Timeout,
+		Transport: &retryTransport{
+			next:   newrelic.NewRoundTripper(transport), // Each attempt is reported as its own external call
+			config: config,
+		},
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/danesparza/daydash-service/internal/httpclient"
 	"github.com/muesli/smartcrop"
 	"github.com/muesli/smartcrop/nfnt"
 	"github.com/newrelic/go-agent/v3/newrelic"
@@ -113,9 +114,7 @@ func FetchTweets(ctx context.Context, sinceTweetID string) ([]Tweet, error) {
 	clientRequest = newrelic.RequestWithTransactionContext(clientRequest, txn)
 
 	//	Execute the request
-	client := http.Client{}
-	client.Transport = newrelic.NewRoundTripper(client.Transport)
-	clientResponse, err := client.Do(clientRequest)
+	clientResponse, err := httpclient.Default().Do(clientRequest)
 	if err != nil {
 		zlog.Errorw(
 			"error when sending request to Twitter API server",
@@ -172,9 +171,7 @@ func GetStoryAndImageUrl(ctx context.Context, page string) (StoryMeta, error) {
 
 	req = newrelic.RequestWithTransactionContext(req, txn)
 
-	client := http.Client{}
-	client.Transport = newrelic.NewRoundTripper(client.Transport)
-	res, err := client.Do(req)
+	res, err := httpclient.Default().Do(req)
 	if res != nil {
 		defer res.Body.Close()
 	}
@@ -245,9 +242,7 @@ func GetResizedEncodedImage(ctx context.Context, imageUrl string, width, height
 
 	req = newrelic.RequestWithTransactionContext(req, txn)
 
-	client := http.Client{}
-	client.Transport = newrelic.NewRoundTripper(client.Transport)
-	response, err := client.Do(req)
+	response, err := httpclient.Default().Do(req)
 	if response != nil {
 		defer response.Body.Close()
 	}
@@ -0,0 +1,177 @@
+// Package pointstore is a durable local store for NWS points lookups.  The zone, county,
+// grid and radar station for a location essentially never change, so they're kept in an
+// embedded key/value file and only refreshed now and then.
+package pointstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucketName is the bucket that holds the points data
+var bucketName = []byte("nwspoints")
+
+// Store is the on-disk store of points lookups
+type Store struct {
+	db *bolt.DB
+}
+
+// Entry is a single stored points lookup
+type Entry struct {
+	Key       string          `json:"key"`       // The (rounded) coordinates for the lookup
+	Data      json.RawMessage `json:"data"`      // The points response
+	FetchedAt time.Time       `json:"fetchedAt"` // When the points response was fetched
+}
+
+// Age gets how long ago the entry was fetched
+func (e Entry) Age() time.Duration {
+	return time.Since(e.FetchedAt)
+}
+
+// Open opens (or creates) the store at the given path.  The store can only be open in one
+// process at a time -- if it's locked, we give up after the given timeout
+func Open(path string, timeout time.Duration) (*Store, error) {
+
+	//	Make sure the directory exists
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("problem creating the directory for the points store: %v", err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return nil, fmt.Errorf("problem opening the points store at %s: %v", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("problem creating the points bucket: %v", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the store
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Get gets the entry for the given key.  The bool is false if there isn't one
+func (s *Store) Get(key string) (Entry, bool, error) {
+	retval := Entry{}
+	found := false
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketName).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+
+		found = true
+		return json.Unmarshal(data, &retval)
+	})
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("problem reading points entry %s: %v", key, err)
+	}
+
+	return retval, found, nil
+}
+
+// Put stores the points data for the given key (as fetched now)
+func (s *Store) Put(key string, data []byte) error {
+	entry, err := json.Marshal(Entry{
+		Key:       key,
+		Data:      data,
+		FetchedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("problem encoding points entry %s: %v", key, err)
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Put([]byte(key), entry)
+	})
+	if err != nil {
+		return fmt.Errorf("problem storing points entry %s: %v", key, err)
+	}
+
+	return nil
+}
+
+// List gets all of the entries (sorted by key)
+func (s *Store) List() ([]Entry, error) {
+	retval := []Entry{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).ForEach(func(k, v []byte) error {
+			entry := Entry{}
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return fmt.Errorf("problem decoding points entry %s: %v", k, err)
+			}
+			retval = append(retval, entry)
+			return nil
+		})
+	})
+	if err != nil {
+		return retval, err
+	}
+
+	sort.Slice(retval, func(i, j int) bool {
+		return retval[i].Key < retval[j].Key
+	})
+
+	return retval, nil
+}
+
+// Delete removes the entry for the given key
+func (s *Store) Delete(key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Delete([]byte(key))
+	})
+}
+
+// Purge removes entries fetched longer ago than the given age (or all entries if the age is 0).
+// It returns the number of entries removed
+func (s *Store) Purge(olderThan time.Duration) (int, error) {
+	removed := 0
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+
+		//	Find what we need to remove (we can't remove while we're iterating)
+		keys := [][]byte{}
+		err := bucket.ForEach(func(k, v []byte) error {
+			entry := Entry{}
+			if err := json.Unmarshal(v, &entry); err != nil || olderThan <= 0 || entry.Age() > olderThan {
+				keys = append(keys, append([]byte{}, k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		removed = len(keys)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("problem purging points entries: %v", err)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,98 @@
+package pointstore_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danesparza/daydash-service/internal/pointstore"
+)
+
+func TestStore_PutThenGet_ReturnsEntry(t *testing.T) {
+	//	Arrange
+	store, err := pointstore.Open(filepath.Join(t.TempDir(), "points.db"), time.Second)
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	defer store.Close()
+
+	//	Act
+	err = store.Put("33.87,-84.00", []byte(`{"id":"test"}`))
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+	entry, found, err := store.Get("33.87,-84.00")
+
+	//	Assert
+	if err != nil || !found {
+		t.Fatalf("Expected to find the entry but got found=%v, err=%v", found, err)
+	}
+
+	if string(entry.Data) != `{"id":"test"}` {
+		t.Errorf("Expected the stored data but got %s instead", entry.Data)
+	}
+
+	if entry.Age() > time.Minute {
+		t.Errorf("Expected a fresh entry but it's %v old", entry.Age())
+	}
+}
+
+func TestStore_Get_MissingKey_NotFound(t *testing.T) {
+	//	Arrange
+	store, err := pointstore.Open(filepath.Join(t.TempDir(), "points.db"), time.Second)
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	defer store.Close()
+
+	//	Act
+	_, found, err := store.Get("0.00,0.00")
+
+	//	Assert
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+
+	if found {
+		t.Errorf("Expected not to find an entry")
+	}
+}
+
+func TestStore_Purge_RemovesOldEntries(t *testing.T) {
+	//	Arrange
+	path := filepath.Join(t.TempDir(), "points", "points.db")
+	store, err := pointstore.Open(path, time.Second)
+	if err != nil {
+		t.Fatalf("Returned error and we didn't expect that: %v", err)
+	}
+	defer store.Close()
+
+	store.Put("b", []byte(`{}`))
+	store.Put("a", []byte(`{}`))
+
+	//	Act
+	kept, err := store.Purge(time.Hour)
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+	entries, _ := store.List()
+
+	removed, err := store.Purge(0)
+	if err != nil {
+		t.Errorf("Returned error and we didn't expect that: %v", err)
+	}
+	remaining, _ := store.List()
+
+	//	Assert
+	if kept != 0 || len(entries) != 2 {
+		t.Errorf("Expected fresh entries to be kept but %v were removed", kept)
+	}
+
+	if entries[0].Key != "a" {
+		t.Errorf("Expected entries sorted by key but got %v first", entries[0].Key)
+	}
+
+	if removed != 2 || len(remaining) != 0 {
+		t.Errorf("Expected all entries to be purged but %v were removed and %v remain", removed, len(remaining))
+	}
+}